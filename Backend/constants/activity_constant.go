@@ -12,6 +12,9 @@ const (
 	ActPayment = "PAYMENT"
 	ActRefund  = "REFUND"
 	ActAdmin   = "ADMIN"
+
+	ActPasswordReset = "PASSWORD_RESET"
+	ActHoldExpired   = "HOLD_EXPIRED"
 )
 
 // Categories for retention
@@ -0,0 +1,43 @@
+package constants
+
+// Status booking
+const (
+	BookingStatusPending   = "PENDING"   // menunggu pembayaran
+	BookingStatusConfirmed = "CONFIRMED" // sudah dibayar / dikonfirmasi staff
+	BookingStatusCancelled = "CANCELLED"
+	BookingStatusCompleted = "COMPLETED" // tamu sudah check-out
+)
+
+// BookingActiveStatuses adalah status yang masih menempati kamar.
+var BookingActiveStatuses = []string{
+	BookingStatusPending,
+	BookingStatusConfirmed,
+}
+
+// Status hold kamar selama checkout
+const (
+	HoldStatusActive    = "ACTIVE"
+	HoldStatusConfirmed = "CONFIRMED" // sudah menjadi booking
+	HoldStatusReleased  = "RELEASED"  // dilepas tamu
+	HoldStatusExpired   = "EXPIRED"   // dilepas otomatis oleh HoldExpiryJob
+)
+
+// Jenis diskon promo code
+const (
+	DiscountTypePercent = "PERCENT"
+	DiscountTypeFixed   = "FIXED"
+)
+
+// Jenis denda pembatalan setelah batas gratis lewat
+const (
+	PenaltyTypePercent    = "PERCENT"     // persen dari jumlah yang dibayar
+	PenaltyTypeFirstNight = "FIRST_NIGHT" // harga malam pertama
+)
+
+// Aturan yang dipakai saat menghitung refund pembatalan
+const (
+	RefundRuleNoPolicy      = "NO_POLICY"
+	RefundRuleFree          = "FREE_CANCELLATION"
+	RefundRulePenalty       = "PENALTY"
+	RefundRuleNonRefundable = "NON_REFUNDABLE"
+)
@@ -0,0 +1,16 @@
+package constants
+
+// Status pemrosesan foto kamar (models.RoomImage.Status). Foto lama tanpa
+// status dianggap siap.
+const (
+	ImageStatusProcessing = "PROCESSING" // menunggu worker membuat varian
+	ImageStatusReady      = "READY"
+	ImageStatusFailed     = "FAILED"
+)
+
+// Varian ukuran foto yang dibuat otomatis dari setiap upload
+const (
+	ImageVariantThumbnail = "thumbnail"
+	ImageVariantMedium    = "medium"
+	ImageVariantLarge     = "large"
+)
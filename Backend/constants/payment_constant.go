@@ -0,0 +1,25 @@
+package constants
+
+// Status pembayaran
+const (
+	PaymentStatusPending           = "PENDING"    // menunggu konfirmasi provider
+	PaymentStatusAuthorized        = "AUTHORIZED" // dana ditahan, belum di-capture
+	PaymentStatusCaptured          = "CAPTURED"
+	PaymentStatusFailed            = "FAILED"
+	PaymentStatusPartiallyRefunded = "PARTIALLY_REFUNDED"
+	PaymentStatusRefunded          = "REFUNDED"
+)
+
+// Jenis tagihan yang bisa dibayar
+const (
+	PayableBooking    = "booking"     // menginap (models.Booking)
+	PayableRoomCharge = "room_charge" // tagihan tambahan atas kamar (models.Room), mis. minibar
+)
+
+// Event webhook pembayaran
+const (
+	PaymentEventAuthorized = "payment.authorized"
+	PaymentEventCaptured   = "payment.captured"
+	PaymentEventFailed     = "payment.failed"
+	PaymentEventRefunded   = "payment.refunded"
+)
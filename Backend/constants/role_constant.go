@@ -0,0 +1,71 @@
+package constants
+
+// Roles (nilai yang disimpan di models.User.Role)
+const (
+	RoleAdmin        = "Admin"
+	RoleFrontDesk    = "Resepsionis"
+	RoleHousekeeping = "Housekeeping"
+	RoleGuest        = "Guest"
+)
+
+// Permissions yang dicek oleh middleware.Authorize
+const (
+	PermUserRead      = "user:read"
+	PermUserWrite     = "user:write"
+	PermRoomRead      = "room:read"
+	PermRoomWrite     = "room:write"
+	PermFacilityRead  = "facility:read"
+	PermFacilityWrite = "facility:write"
+	PermRoomTypeRead  = "room_type:read"
+	PermRoomTypeWrite = "room_type:write"
+	PermBookingRead   = "booking:read"
+	PermBookingWrite  = "booking:write"
+	PermPromoRead     = "promo:read"
+	PermPromoWrite    = "promo:write"
+	PermPaymentRead   = "payment:read"
+	PermPaymentWrite  = "payment:write"
+	PermSecurityAdmin = "security:admin"
+)
+
+// RolePermissions memetakan role ke permission yang diizinkan.
+// Role yang tidak terdaftar di sini tidak punya akses sama sekali.
+var RolePermissions = map[string][]string{
+	RoleAdmin: {
+		PermUserRead, PermUserWrite,
+		PermRoomRead, PermRoomWrite,
+		PermFacilityRead, PermFacilityWrite,
+		PermRoomTypeRead, PermRoomTypeWrite,
+		PermBookingRead, PermBookingWrite,
+		PermPromoRead, PermPromoWrite,
+		PermPaymentRead, PermPaymentWrite,
+		PermSecurityAdmin,
+	},
+	RoleFrontDesk: {
+		PermUserRead,
+		PermRoomRead,
+		PermFacilityRead,
+		PermRoomTypeRead,
+		PermBookingRead, PermBookingWrite,
+		PermPromoRead,
+		PermPaymentRead, PermPaymentWrite,
+	},
+	RoleHousekeeping: {
+		PermRoomRead,
+		PermFacilityRead,
+		PermRoomTypeRead,
+		PermBookingRead,
+	},
+	RoleGuest: {},
+}
+
+// Scopes untuk API key integrasi
+const (
+	ScopeActivityLogsRead   = "activity_logs:read"
+	ScopeActivityLogsExport = "activity_logs:export"
+)
+
+// APIKeyScopes adalah daftar scope yang boleh diberikan ke API key.
+var APIKeyScopes = []string{
+	ScopeActivityLogsRead,
+	ScopeActivityLogsExport,
+}
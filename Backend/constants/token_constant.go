@@ -0,0 +1,7 @@
+package constants
+
+// Purpose untuk token sekali pakai yang dikirim lewat email
+const (
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposePasswordReset     = "password_reset"
+)
@@ -5,7 +5,6 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"net/http"
-	"os"
 	"strconv"
 	"time"
 
@@ -13,29 +12,12 @@ import (
 	"github.com/rs/zerolog/log"
 	"go.mongodb.org/mongo-driver/bson"
 
-	// "astro-backend/constants"
+	"astro-backend/constants"
+	"astro-backend/middleware"
 	"astro-backend/service/activityLog"
+	"astro-backend/service/auth"
 )
 
-/* ===========================
-        Middleware
-=========================== */
-
-func AdminAuth(next http.Handler) http.Handler {
-	token := getEnv("ADMIN_API_TOKEN", "")
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if token == "" {
-			http.Error(w, "admin token not configured", http.StatusInternalServerError)
-			return
-		}
-		if r.Header.Get("Authorization") != "Bearer "+token {
-			http.Error(w, "unauthorized", http.StatusUnauthorized)
-			return
-		}
-		next.ServeHTTP(w, r)
-	})
-}
-
 /* ===========================
         Handler Struct
 =========================== */
@@ -48,15 +30,19 @@ func NewActivityLogHandler(svc activityLog.ActivityLogService) *ActivityLogHandl
 	return &ActivityLogHandler{Svc: svc}
 }
 
-func (h *ActivityLogHandler) RegisterRoutes(r *mux.Router) {
+// RegisterRoutes memasang endpoint activity log. Setiap route butuh API key
+// dengan scope yang sesuai (lihat constants.APIKeyScopes).
+func (h *ActivityLogHandler) RegisterRoutes(r *mux.Router, keys auth.APIKeyService) {
+	read := middleware.APIKeyAuth(keys, constants.ScopeActivityLogsRead)
+	export := middleware.APIKeyAuth(keys, constants.ScopeActivityLogsExport)
+
 	ar := r.PathPrefix("/api/admin/activity-logs").Subrouter()
-	ar.Use(AdminAuth)
-	ar.HandleFunc("", h.List).Methods("GET")
-	ar.HandleFunc("/search", h.Search).Methods("GET")
-	ar.HandleFunc("/dashboard", h.Dashboard).Methods("GET")
-	ar.HandleFunc("/security-alerts", h.SecurityAlerts).Methods("GET")
-	ar.HandleFunc("/export", h.Export).Methods("GET")
-	ar.HandleFunc("/{id}", h.Detail).Methods("GET")
+	ar.Handle("", read(http.HandlerFunc(h.List))).Methods("GET")
+	ar.Handle("/search", read(http.HandlerFunc(h.Search))).Methods("GET")
+	ar.Handle("/dashboard", read(http.HandlerFunc(h.Dashboard))).Methods("GET")
+	ar.Handle("/security-alerts", read(http.HandlerFunc(h.SecurityAlerts))).Methods("GET")
+	ar.Handle("/export", export(http.HandlerFunc(h.Export))).Methods("GET")
+	ar.Handle("/{id}", read(http.HandlerFunc(h.Detail))).Methods("GET")
 }
 
 /* ===========================
@@ -243,14 +229,6 @@ func (h *ActivityLogHandler) Export(w http.ResponseWriter, r *http.Request) {
         Helpers
 =========================== */
 
-func getEnv(k, def string) string {
-	v := os.Getenv(k)
-	if v == "" {
-		return def
-	}
-	return v
-}
-
 func parseInt(s string, def int) int {
 	if s == "" {
 		return def
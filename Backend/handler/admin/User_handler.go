@@ -1,9 +1,11 @@
 package admin
 
 import (
-	"astro-backend/models"
+	adminRepo "astro-backend/repository/admin"
 	"astro-backend/service/admin"
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,15 +23,20 @@ func NewUserHandler(s admin.UserService) UserHandler {
 }
 
 func (h UserHandler) CreateUser(c *gin.Context) {
-	var user models.User
+	var input admin.CreateUserInput
 
-	if err := c.ShouldBindJSON(&user); err != nil {
+	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
 		return
 	}
 
-	if err := h.service.CreateUser(user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+	err := h.service.CreateUser(input)
+	if errors.Is(err, adminRepo.ErrEmailExists) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -42,28 +49,42 @@ func (h UserHandler) DeleteUser(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
 }
+// UpdateUser hanya mengubah field yang dikirim di body JSON.
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	id := c.Param("id")
 
-	var user models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
+	var input admin.UpdateUserInput
+	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Format JSON tidak valid"})
 		return
 	}
 
-	if err := h.service.UpdateUser(user, id); err != nil {
+	err := h.service.UpdateUser(id, input)
+	if errors.Is(err, adminRepo.ErrEmailExists) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "User berhasil diperbarui"})
 }
+// GetAllUsers mengembalikan daftar user per halaman. Filter: role, email
+// (awalan email).
 func (h UserHandler) GetAllUsers(c *gin.Context) {
-	users, err := h.service.GetAllUsers()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data user"})
+	q, ok := listQuery(c, userSortFields)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, users)
+
+	filter := adminRepo.UserListFilter{
+		Role:        c.Query("role"),
+		EmailPrefix: strings.TrimSpace(c.Query("email")),
+	}
+
+	users, err := h.service.List(filter, q)
+	respondList(c, users, err)
 }
 
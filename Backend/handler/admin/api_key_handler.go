@@ -0,0 +1,81 @@
+package admin
+
+import (
+	"astro-backend/middleware"
+	"astro-backend/service/auth"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type APIKeyHandler struct {
+	service auth.APIKeyService
+}
+
+func NewAPIKeyHandler(s auth.APIKeyService) APIKeyHandler {
+	return APIKeyHandler{s}
+}
+
+type createAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (h APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req createAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format JSON tidak valid"})
+		return
+	}
+
+	var createdBy *primitive.ObjectID
+	if user, ok := middleware.CurrentUser(c); ok {
+		createdBy = &user.Id
+	}
+
+	key, raw, err := h.service.Create(req.Name, req.Scopes, req.ExpiresAt, createdBy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "API key berhasil dibuat, simpan key ini karena tidak akan ditampilkan lagi",
+		"data":    key,
+		"api_key": raw,
+	})
+}
+
+func (h APIKeyHandler) GetAllAPIKeys(c *gin.Context) {
+	keys, err := h.service.GetAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data API key"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": keys})
+}
+
+func (h APIKeyHandler) RotateAPIKey(c *gin.Context) {
+	key, raw, err := h.service.Rotate(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key berhasil dirotasi, key lama sudah tidak berlaku",
+		"data":    key,
+		"api_key": raw,
+	})
+}
+
+func (h APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	if err := h.service.Revoke(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "API key berhasil dicabut"})
+}
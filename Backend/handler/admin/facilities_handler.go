@@ -2,8 +2,10 @@ package admin
 
 import (
 	"astro-backend/models"
+	adminRepo "astro-backend/repository/admin"
 	"astro-backend/service/admin"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,17 +17,15 @@ func NewFacilitiesHandler(s admin.FacilityService) FacilitiesHandler {
 	return FacilitiesHandler{s}
 }
 
+// GetAllFacilities mengembalikan daftar fasilitas per halaman. Filter: name.
 func (h FacilitiesHandler) GetAllFacilities(c *gin.Context) {
-	facilities, err := h.services.GetAll()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve facilities"})
-		return
-	}
-	if len(facilities) == 0 {
-		c.JSON(http.StatusOK, gin.H{"message": "Data Facilities Kosong"})
+	q, ok := listQuery(c, nameSortFields)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, facilities)
+
+	facilities, err := h.services.List(adminRepo.FacilityListFilter{Name: strings.TrimSpace(c.Query("name"))}, q)
+	respondList(c, facilities, err)
 }
 func (h FacilitiesHandler) CreateFacility(c *gin.Context) {
 	var facility models.Facility
@@ -44,17 +44,17 @@ func (h FacilitiesHandler) CreateFacility(c *gin.Context) {
 }
 func (h FacilitiesHandler) UpdateFacility(c *gin.Context) {
 	id := c.Param("id")
-	var facility models.Facility
+	var input admin.UpdateFacilityInput
 
-	if err := c.ShouldBindJSON(&facility); err != nil {
+	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
 		return
 	}
-	if err := h.services.Update(id, facility); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update facility"})
+	if err := h.services.Update(id, input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"data" : facility, "message" : "Update data facility succsess"})
+	c.JSON(http.StatusOK, gin.H{"message" : "Update data facility succsess"})
 }
 func (h FacilitiesHandler) DeleteFacility(c *gin.Context) {
 	id := c.Param("id")
@@ -0,0 +1,54 @@
+package admin
+
+import (
+	"astro-backend/repository/listing"
+	"astro-backend/utils"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Field yang boleh dipakai sort_by per resource (nama publik -> field bson).
+var (
+	userSortFields = map[string]string{
+		"name":       "Name",
+		"email":      "Email",
+		"role":       "Role",
+		"created_at": "CreatedAt",
+	}
+	roomSortFields = map[string]string{
+		"name":        "name",
+		"room_number": "room_number",
+		"price":       "price_per_night",
+		"capacity":    "capacity",
+		"created_at":  "created_at",
+	}
+	nameSortFields = map[string]string{
+		"name": "name",
+	}
+)
+
+// listQuery membaca parameter list bersama (page, limit, cursor, sort_by,
+// sort_order). Kalau tidak valid, response 400 sudah dikirim.
+func listQuery(c *gin.Context, sortable map[string]string) (utils.ListQuery, bool) {
+	q, err := utils.ParseListQuery(c.Request.URL.Query(), sortable)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return utils.ListQuery{}, false
+	}
+	return q, true
+}
+
+// respondList mengirim satu halaman dengan envelope bersama.
+func respondList[T any](c *gin.Context, page utils.ListPage[T], err error) {
+	if errors.Is(err, listing.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
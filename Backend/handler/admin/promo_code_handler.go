@@ -0,0 +1,75 @@
+package admin
+
+import (
+	"astro-backend/models"
+	"astro-backend/service/admin"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromoCodeHandler struct {
+	services admin.PromoCodeService
+}
+
+func NewPromoCodeHandler(s admin.PromoCodeService) PromoCodeHandler {
+	return PromoCodeHandler{s}
+}
+
+func (h PromoCodeHandler) GetAllPromoCodes(c *gin.Context) {
+	promos, err := h.services.GetAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve promo codes"})
+		return
+	}
+	c.JSON(http.StatusOK, promos)
+}
+
+func (h PromoCodeHandler) GetPromoCode(c *gin.Context) {
+	promo, err := h.services.GetByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Promo code tidak ditemukan"})
+		return
+	}
+	c.JSON(http.StatusOK, promo)
+}
+
+func (h PromoCodeHandler) CreatePromoCode(c *gin.Context) {
+	var promo models.PromoCode
+	if err := c.ShouldBindJSON(&promo); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return
+	}
+
+	created, err := h.services.Create(promo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": created, "message": "Promo code created successfully"})
+}
+
+func (h PromoCodeHandler) UpdatePromoCode(c *gin.Context) {
+	var promo models.PromoCode
+	if err := c.ShouldBindJSON(&promo); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return
+	}
+
+	if err := h.services.Update(c.Param("id"), promo); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Promo code updated successfully"})
+}
+
+func (h PromoCodeHandler) DeletePromoCode(c *gin.Context) {
+	if err := h.services.Delete(c.Param("id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete promo code"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Promo code deleted successfully"})
+}
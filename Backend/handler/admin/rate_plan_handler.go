@@ -0,0 +1,75 @@
+package admin
+
+import (
+	"astro-backend/models"
+	"astro-backend/service/admin"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RatePlanHandler struct {
+	services admin.RatePlanService
+}
+
+func NewRatePlanHandler(s admin.RatePlanService) RatePlanHandler {
+	return RatePlanHandler{s}
+}
+
+func (h RatePlanHandler) GetAllRatePlans(c *gin.Context) {
+	plans, err := h.services.GetAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve rate plans"})
+		return
+	}
+	c.JSON(http.StatusOK, plans)
+}
+
+func (h RatePlanHandler) GetRatePlan(c *gin.Context) {
+	plan, err := h.services.GetByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rate plan tidak ditemukan"})
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
+
+func (h RatePlanHandler) CreateRatePlan(c *gin.Context) {
+	var plan models.RatePlan
+	if err := c.ShouldBindJSON(&plan); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return
+	}
+
+	created, err := h.services.Create(plan)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": created, "message": "Rate plan created successfully"})
+}
+
+func (h RatePlanHandler) UpdateRatePlan(c *gin.Context) {
+	var plan models.RatePlan
+	if err := c.ShouldBindJSON(&plan); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return
+	}
+
+	if err := h.services.Update(c.Param("id"), plan); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Rate plan updated successfully"})
+}
+
+func (h RatePlanHandler) DeleteRatePlan(c *gin.Context) {
+	if err := h.services.Delete(c.Param("id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete rate plan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Rate plan deleted successfully"})
+}
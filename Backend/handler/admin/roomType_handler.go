@@ -2,8 +2,10 @@ package admin
 
 import (
 	"astro-backend/models"
+	adminRepo "astro-backend/repository/admin"
 	"astro-backend/service/admin"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,17 +18,15 @@ func NewRoomTypeHandler(s admin.RoomTypeService) RoomTypeHandler {
 	return RoomTypeHandler{s}
 }	
 
+// GetAllRoomTypes mengembalikan daftar room type per halaman. Filter: name.
 func (h RoomTypeHandler) GetAllRoomTypes(c *gin.Context) {
-	roomTypes, err := h.services.GetAll()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve room types"})
-		return
-	}
-	if len(roomTypes) == 0 {
-		c.JSON(http.StatusOK, gin.H{"message": "Data Room Types Kosong"})
+	q, ok := listQuery(c, nameSortFields)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, roomTypes)
+
+	roomTypes, err := h.services.List(adminRepo.RoomTypeListFilter{Name: strings.TrimSpace(c.Query("name"))}, q)
+	respondList(c, roomTypes, err)
 }
 func (h RoomTypeHandler) CreateRoomType(c *gin.Context) {
 	var roomType models.RoomType
@@ -37,7 +37,7 @@ func (h RoomTypeHandler) CreateRoomType(c *gin.Context) {
 
 	createdRoomType, err := h.services.Create(roomType)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create room type"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -45,14 +45,14 @@ func (h RoomTypeHandler) CreateRoomType(c *gin.Context) {
 }
 func (h RoomTypeHandler) UpdateRoomType(c *gin.Context) {
 	id := c.Param("id")
-	var roomType models.RoomType
+	var input admin.UpdateRoomTypeInput
 
-	if err := c.ShouldBindJSON(&roomType); err != nil {
+	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
 		return
 	}
-	if err := h.services.Update(id, roomType); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update room type"})
+	if err := h.services.Update(id, input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Room type updated successfully"})
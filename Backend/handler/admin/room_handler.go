@@ -1,15 +1,13 @@
 package admin
 
 import (
+	adminRepo "astro-backend/repository/admin"
 	"astro-backend/service/admin"
 	"github.com/gin-gonic/gin"
-	"fmt"
 	"strconv"
-	"time"
+	"strings"
 
 	"net/http"
-    "path/filepath"
-    "os"
     "go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -44,41 +42,20 @@ func (h *RoomHandler) CreateRoom(c *gin.Context) {
 	price, _ := strconv.ParseFloat(priceStr, 64)
 	capacity, _ := strconv.Atoi(capacityStr)
 
-	// Ambil banyak gambar
-	files := form.File["images"]
-
-	if len(files) == 0 {
+	if len(form.File["images"]) == 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Minimal upload 1 gambar"})
 		return
 	}
 
-	imagePaths := []string{}
-	
-	// [SETUP FOLDER] Pastikan folder ada
-	os.MkdirAll("uploads/rooms", os.ModePerm)
-
-	for _, file := range files {
-		// 1. Buat nama file unik
-		filename := fmt.Sprintf("%d_%s", time.Now().Unix(), file.Filename)
-		
-		// 2. Tentukan PATH FISIK (tempat file akan disimpan di disk server)
-		savePath := filepath.Join("uploads", "rooms", filename) // Menggunakan path relatif tanpa '/' di awal
-		
-		// 3. Tentukan PATH URL (yang akan disimpan ke DB dan diakses browser)
-		// [PERBAIKAN FOKUS] Ini HARUS diawali dengan '/' agar server statis (router.Static("/uploads", "./uploads")) bisa bekerja
-		imageURLPath := filepath.Join("/uploads", "rooms", filename) // <--- PATH DENGAN GARIS MIRING AWAL
-
-		if err := c.SaveUploadedFile(file, savePath); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal upload gambar"})
-			return
-		}
-
-		// [PERBAIKAN FOKUS] Simpan PATH URL ke dalam array
-		imagePaths = append(imagePaths, imageURLPath) // <-- Menggunakan imageURLPath
+	// gambar divalidasi dari isinya lalu diproses worker di belakang
+	images, err := stageRoomUploads(c)
+	if err != nil {
+		respondUploadError(c, err)
+		return
 	}
 
 	// Kirim ke service
-	err = h.service.CreateRoom(name, description, roomNumber, price, TypeID, capacity, bedType, category, facID, imagePaths)
+	err = h.service.CreateRoom(name, description, roomNumber, price, TypeID, capacity, bedType, category, facID, images)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -88,14 +65,51 @@ func (h *RoomHandler) CreateRoom(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"message": "Room berhasil dibuat!"})
 }
 
+// GetAll mengembalikan daftar kamar per halaman. Filter: category, bed_type,
+// room_type_id, availability (true/false), facilities (boleh diulang atau
+// dipisah koma).
 func (h *RoomHandler) GetAll(c *gin.Context) {
-	rooms, err := h.service.GetAll()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	q, ok := listQuery(c, roomSortFields)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, rooms)
+	filter := adminRepo.RoomListFilter{
+		Category: c.Query("category"),
+		BedType:  c.Query("bed_type"),
+	}
+	if v := c.Query("room_type_id"); v != "" {
+		oid, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "room_type_id tidak valid"})
+			return
+		}
+		filter.RoomTypeID = &oid
+	}
+	if v := c.Query("availability"); v != "" {
+		available, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "availability harus true atau false"})
+			return
+		}
+		filter.Availability = &available
+	}
+	for _, v := range c.QueryArray("facilities") {
+		for _, id := range strings.Split(v, ",") {
+			if id = strings.TrimSpace(id); id == "" {
+				continue
+			}
+			oid, err := primitive.ObjectIDFromHex(id)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID fasilitas tidak valid: " + id})
+				return
+			}
+			filter.FacilityIDs = append(filter.FacilityIDs, oid)
+		}
+	}
+
+	rooms, err := h.service.List(filter, q)
+	respondList(c, rooms, err)
 }
 
 func (h *RoomHandler) Delete(c *gin.Context) {
@@ -108,45 +122,83 @@ func (h *RoomHandler) Delete(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Room deleted"})
 }
+// Update mengubah sebagian data kamar: hanya field form yang dikirim yang
+// diubah. Field angka / ID yang dikirim kosong dianggap tidak dikirim.
 func (h *RoomHandler) Update(c *gin.Context) {
 	id := c.Param("id")
 
-	name := c.PostForm("name")
-	description := c.PostForm("description")
-	roomNumber := c.PostForm("room_number")
-	price, _ := strconv.ParseFloat(c.PostForm("price_per_night"), 64)
-	TypeID := c.PostForm("room_type_id")
-	capacity, _ := strconv.Atoi(c.PostForm("capacity"))
-	bedType := c.PostForm("bed_type")
-	category := c.PostForm("category")
-	facID := c.PostFormArray("facilities_id")
-
-	// Ambil file images
-	form, _ := c.MultipartForm()
-	files := form.File["images"]
-
-	var imagePaths []string
-	for _, file := range files {
-		filename := primitive.NewObjectID().Hex() + filepath.Ext(file.Filename)
-		savePath := filepath.Join("uploads/rooms", filename)
+	input := admin.UpdateRoomInput{
+		Name:        formField(c, "name"),
+		Description: formField(c, "description"),
+		RoomNumber:  formField(c, "room_number"),
+		BedType:     formField(c, "bed_type"),
+		Category:    formField(c, "category"),
+	}
+	if v := formValue(c, "room_type_id"); v != "" {
+		input.RoomTypeID = &v
+	}
+	if ids, ok := c.GetPostFormArray("facilities_id"); ok {
+		input.FacilityIDs = &ids
+	}
 
-		os.MkdirAll("uploads/rooms", os.ModePerm)
-		if err := c.SaveUploadedFile(file, savePath); err != nil {
-			c.JSON(500, gin.H{"error": err.Error()})
+	// form create memakai "price", form lama memakai "price_per_night"
+	price := formValue(c, "price_per_night")
+	if price == "" {
+		price = formValue(c, "price")
+	}
+	if price != "" {
+		v, err := strconv.ParseFloat(price, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "price_per_night tidak valid"})
 			return
 		}
-
-		imagePaths = append(imagePaths, "/uploads/rooms/"+filename)
+		input.PricePerNight = &v
+	}
+	if v := formValue(c, "capacity"); v != "" {
+		capacity, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "capacity tidak valid"})
+			return
+		}
+		input.Capacity = &capacity
+	}
+	if v := formValue(c, "availability"); v != "" {
+		available, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "availability harus true atau false"})
+			return
+		}
+		input.Availability = &available
 	}
 
-	// Kirim data ke service (service cukup terima data jadi)
-	err := h.service.UpdateRoom(id, name, description, roomNumber, price, TypeID, capacity, bedType, category, facID, imagePaths)
+	// foto baru ditambahkan ke galeri; kelola foto lama lewat endpoint images
+	images, err := stageRoomUploads(c)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		respondUploadError(c, err)
+		return
+	}
+	input.Images = images
+
+	if err := h.service.UpdateRoom(id, input); err != nil {
+		respondRoomError(c, err)
 		return
 	}
 
 	c.JSON(200, gin.H{"message": "Room updated successfully"})
 }
 
+// formField mengembalikan nilai form kalau field itu dikirim, nil kalau
+// tidak, sehingga string kosong tetap bisa dipakai untuk mengosongkan.
+func formField(c *gin.Context, key string) *string {
+	v, ok := c.GetPostForm(key)
+	if !ok {
+		return nil
+	}
+	return &v
+}
 
+// formValue mengembalikan nilai form yang sudah di-trim; kosong kalau tidak
+// dikirim.
+func formValue(c *gin.Context, key string) string {
+	return strings.TrimSpace(c.PostForm(key))
+}
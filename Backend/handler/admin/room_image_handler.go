@@ -0,0 +1,156 @@
+package admin
+
+import (
+	adminRepo "astro-backend/repository/admin"
+	"astro-backend/service/admin"
+	"astro-backend/service/media"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoomImageHandler struct {
+	services admin.RoomImageService
+}
+
+func NewRoomImageHandler(services admin.RoomImageService) RoomImageHandler {
+	return RoomImageHandler{services}
+}
+
+// AddImages menambahkan foto ke galeri kamar. Multipart: images (file,
+// boleh banyak), alt dan caption (opsional, urutannya sama dengan images).
+// Foto baru berstatus PROCESSING sampai variannya selesai dibuat.
+func (h RoomImageHandler) AddImages(c *gin.Context) {
+	images, err := stageRoomUploads(c)
+	if err != nil {
+		respondUploadError(c, err)
+		return
+	}
+
+	gallery, err := h.services.AddImages(c.Param("id"), images)
+	if err != nil {
+		respondRoomError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"data": gallery, "message": "Images added successfully"})
+}
+
+// UpdateImage mengubah alt text dan/atau caption satu foto.
+func (h RoomImageHandler) UpdateImage(c *gin.Context) {
+	var input admin.UpdateRoomImageInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+		return
+	}
+
+	gallery, err := h.services.UpdateImage(c.Param("id"), c.Param("imageId"), input)
+	if err != nil {
+		respondRoomError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gallery, "message": "Image updated successfully"})
+}
+
+func (h RoomImageHandler) DeleteImage(c *gin.Context) {
+	gallery, err := h.services.DeleteImage(c.Param("id"), c.Param("imageId"))
+	if err != nil {
+		respondRoomError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gallery, "message": "Image deleted successfully"})
+}
+
+// ReorderImages menerima {"image_ids": [...]} berisi semua foto dalam
+// urutan baru.
+func (h RoomImageHandler) ReorderImages(c *gin.Context) {
+	var body struct {
+		ImageIDs []string `json:"image_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "image_ids wajib diisi"})
+		return
+	}
+
+	gallery, err := h.services.ReorderImages(c.Param("id"), body.ImageIDs)
+	if err != nil {
+		respondRoomError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gallery, "message": "Images reordered successfully"})
+}
+
+func (h RoomImageHandler) SetPrimaryImage(c *gin.Context) {
+	gallery, err := h.services.SetPrimary(c.Param("id"), c.Param("imageId"))
+	if err != nil {
+		respondRoomError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gallery, "message": "Cover image updated successfully"})
+}
+
+func respondRoomError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, admin.ErrRoomNotFound), errors.Is(err, admin.ErrImageNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, adminRepo.ErrRoomChanged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// respondUploadError memetakan error validasi upload ke status HTTP.
+func respondUploadError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, media.ErrUnsupportedType):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	case errors.Is(err, media.ErrFileTooLarge), errors.Is(err, media.ErrImageTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal upload gambar"})
+	}
+}
+
+// stageRoomUploads memvalidasi file "images" dari multipart form dan
+// menyalinnya ke file staging. Kalau satu file ditolak, semua file yang
+// sudah di-stage dibuang. Request yang bukan multipart atau tanpa file
+// menghasilkan slice kosong.
+func stageRoomUploads(c *gin.Context) ([]admin.NewRoomImage, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, nil
+	}
+
+	alts := form.Value["alt"]
+	captions := form.Value["caption"]
+	limits := media.LimitsFromEnv()
+
+	var images []admin.NewRoomImage
+	for i, file := range form.File["images"] {
+		upload, err := media.Stage(file, limits)
+		if err != nil {
+			for _, img := range images {
+				media.Discard(img.Upload)
+			}
+			return nil, fmt.Errorf("%s: %w", file.Filename, err)
+		}
+
+		image := admin.NewRoomImage{Upload: upload}
+		if i < len(alts) {
+			image.Alt = alts[i]
+		}
+		if i < len(captions) {
+			image.Caption = captions[i]
+		}
+		images = append(images, image)
+	}
+
+	return images, nil
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"astro-backend/service/auth"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -29,14 +30,103 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	user, err := h.service.Login(request.Email, request.Password)
+	result, err := h.service.Login(request.Email, request.Password, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		respondLoginError(c, err)
+		return
+	}
+
+	// hash password tidak boleh ikut terkirim ke client
+	user := result.User
+	user.Password = ""
+
+	if result.TwoFactorRequired {
+		c.JSON(http.StatusOK, gin.H{
+			"message":             "Masukkan kode 2FA untuk melanjutkan",
+			"two_factor_required": true,
+			"challenge_token":     result.ChallengeToken,
+		})
+		return
+	}
+
+	if result.TwoFactorSetupRequired {
+		c.JSON(http.StatusOK, gin.H{
+			"message":                   "Role Anda wajib memakai 2FA, aktifkan 2FA untuk melanjutkan",
+			"two_factor_setup_required": true,
+			"setup_token":               result.ChallengeToken,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Login berhasil",
+		"user":          user,
+		"access_token":  result.Tokens.AccessToken,
+		"refresh_token": result.Tokens.RefreshToken,
+		"token_type":    result.Tokens.TokenType,
+		"expires_in":    result.Tokens.ExpiresIn,
+	})
+}
+
+// VerifyTwoFactor adalah langkah kedua login: challenge_token dari Login
+// ditukar dengan token biasa memakai kode TOTP atau recovery code.
+func (h *AuthHandler) VerifyTwoFactor(c *gin.Context) {
+	var request struct {
+		ChallengeToken string `json:"challenge_token"`
+		Code           string `json:"code"`
+		RecoveryCode   string `json:"recovery_code"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil || request.ChallengeToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "challenge_token wajib diisi"})
+		return
+	}
+
+	user, tokens, err := h.service.VerifyTwoFactor(request.ChallengeToken, request.Code, request.RecoveryCode, clientInfo(c))
+	if err != nil {
+		respondLoginError(c, err)
 		return
 	}
 
+	user.Password = ""
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Login berhasil",
-		"user":    user,
+		"message":       "Login berhasil",
+		"user":          user,
+		"access_token":  tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"token_type":    tokens.TokenType,
+		"expires_in":    tokens.ExpiresIn,
 	})
 }
+
+func respondLoginError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, auth.ErrTooManyAttempts):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case errors.Is(err, auth.ErrEmailNotVerified):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, auth.ErrInvalidCredentials), errors.Is(err, auth.ErrInvalidTwoFactorCode):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var request struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil || request.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "refresh_token wajib diisi"})
+		return
+	}
+
+	tokens, err := h.service.Refresh(request.RefreshToken, clientInfo(c))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"astro-backend/service/auth"
+	"astro-backend/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func clientInfo(c *gin.Context) auth.ClientInfo {
+	userAgent := c.Request.UserAgent()
+
+	device := c.GetHeader("X-Device-Name")
+	if device == "" {
+		device = deviceFromUserAgent(userAgent)
+	}
+
+	return auth.ClientInfo{
+		IP:        utils.ExtractIP(c.Request),
+		UserAgent: userAgent,
+		Device:    device,
+		Endpoint:  c.Request.URL.Path,
+		Method:    c.Request.Method,
+	}
+}
+
+// deviceFromUserAgent membuat label device sederhana ("Chrome di Windows")
+// untuk daftar sesi. Tidak perlu akurat, hanya membantu user mengenali sesi.
+func deviceFromUserAgent(ua string) string {
+	browser := "Browser"
+	switch {
+	case strings.Contains(ua, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "Safari/"):
+		browser = "Safari"
+	case ua == "":
+		return "Tidak diketahui"
+	}
+
+	os := ""
+	switch {
+	case strings.Contains(ua, "Android"):
+		os = "Android"
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPad"):
+		os = "iOS"
+	case strings.Contains(ua, "Windows"):
+		os = "Windows"
+	case strings.Contains(ua, "Mac OS X"):
+		os = "macOS"
+	case strings.Contains(ua, "Linux"):
+		os = "Linux"
+	}
+
+	if os == "" {
+		return browser
+	}
+	return browser + " di " + os
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"astro-backend/service/auth"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oidcStateCookie mengikat state login SSO ke browser yang memulainya.
+const oidcStateCookie = "oidc_state"
+
+type OIDCHandler struct {
+	service auth.OIDCService
+}
+
+func NewOIDCHandler(service auth.OIDCService) *OIDCHandler {
+	return &OIDCHandler{service}
+}
+
+// Login mengarahkan browser ke halaman login IdP. Dengan ?format=json URL
+// authorize dikembalikan sebagai JSON (untuk SPA yang mengatur redirect sendiri).
+// Kedua mode memasang cookie state, jadi SPA harus memanggilnya dengan
+// credentials supaya cookie tersimpan di browser.
+func (h *OIDCHandler) Login(c *gin.Context) {
+	login, err := h.service.AuthURL()
+	if err != nil {
+		status := http.StatusBadGateway
+		if errors.Is(err, auth.ErrOIDCDisabled) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	setStateCookie(c, login.StateCookie, int(login.MaxAge.Seconds()))
+
+	if c.Query("format") == "json" {
+		c.JSON(http.StatusOK, gin.H{"auth_url": login.URL})
+		return
+	}
+
+	c.Redirect(http.StatusFound, login.URL)
+}
+
+// Callback menerima redirect dari IdP. Kalau OIDC_POST_LOGIN_REDIRECT diisi,
+// token dikirim ke frontend lewat fragment URL (tidak ikut terkirim ke server
+// maupun tercatat di log akses); kalau tidak, token dikembalikan sebagai JSON.
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	// cookie state hanya berlaku untuk satu callback
+	stateCookie, _ := c.Cookie(oidcStateCookie)
+	setStateCookie(c, "", -1)
+
+	if idpErr := c.Query("error"); idpErr != "" {
+		h.respondError(c, http.StatusUnauthorized, errors.New("login SSO dibatalkan: "+idpErr))
+		return
+	}
+
+	user, tokens, err := h.service.Callback(c.Query("code"), c.Query("state"), stateCookie, clientInfo(c))
+	if err != nil {
+		status := http.StatusUnauthorized
+		if errors.Is(err, auth.ErrOIDCDisabled) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, auth.ErrOIDCNoAccount) || errors.Is(err, auth.ErrOIDCNoRole) {
+			status = http.StatusForbidden
+		}
+		h.respondError(c, status, err)
+		return
+	}
+
+	if target := os.Getenv("OIDC_POST_LOGIN_REDIRECT"); target != "" {
+		fragment := url.Values{}
+		fragment.Set("access_token", tokens.AccessToken)
+		fragment.Set("refresh_token", tokens.RefreshToken)
+		fragment.Set("token_type", tokens.TokenType)
+		fragment.Set("expires_in", strconv.FormatInt(tokens.ExpiresIn, 10))
+		c.Redirect(http.StatusFound, target+"#"+fragment.Encode())
+		return
+	}
+
+	user.Password = ""
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Login berhasil",
+		"user":          user,
+		"access_token":  tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"token_type":    tokens.TokenType,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+// setStateCookie menyimpan (atau menghapus, maxAge < 0) cookie state. Lax
+// supaya cookie tetap terkirim saat IdP me-redirect kembali ke callback.
+func setStateCookie(c *gin.Context, value string, maxAge int) {
+	secure := c.Request.TLS != nil || gin.Mode() == gin.ReleaseMode
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, value, maxAge, "/login/oidc", "", secure, true)
+}
+
+func (h *OIDCHandler) respondError(c *gin.Context, status int, err error) {
+	if target := os.Getenv("OIDC_POST_LOGIN_REDIRECT"); target != "" {
+		c.Redirect(http.StatusFound, target+"#"+url.Values{"error": {err.Error()}}.Encode())
+		return
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
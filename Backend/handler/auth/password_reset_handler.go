@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"astro-backend/service/auth"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PasswordResetHandler struct {
+	service auth.PasswordResetService
+}
+
+func NewPasswordResetHandler(service auth.PasswordResetService) *PasswordResetHandler {
+	return &PasswordResetHandler{service}
+}
+
+func (h *PasswordResetHandler) RequestReset(c *gin.Context) {
+	var request struct {
+		Email string `json:"email"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil || request.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email wajib diisi"})
+		return
+	}
+
+	if err := h.service.RequestReset(request.Email, clientInfo(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memproses permintaan reset password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Jika email terdaftar, link reset password sudah dikirim"})
+}
+
+// ShowReset adalah tujuan link di email reset password kalau
+// PASSWORD_RESET_URL kosong. Kalau diisi, browser diarahkan ke halaman
+// reset di frontend beserta tokennya.
+func (h *PasswordResetHandler) ShowReset(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token wajib diisi"})
+		return
+	}
+
+	if os.Getenv("PASSWORD_RESET_URL") != "" {
+		c.Redirect(http.StatusFound, auth.PasswordResetLink(token))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "kirim POST /password/reset dengan token dan password baru",
+		"token":   token,
+	})
+}
+
+func (h *PasswordResetHandler) ConfirmReset(c *gin.Context) {
+	var request struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	if err := h.service.ConfirmReset(request.Token, request.Password, clientInfo(c)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password berhasil diubah, silakan login ulang"})
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	adminRepo "astro-backend/repository/admin"
+	"astro-backend/service/auth"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RegisterHandler struct {
+	service auth.RegisterService
+}
+
+func NewRegisterHandler(service auth.RegisterService) *RegisterHandler {
+	return &RegisterHandler{service}
+}
+
+func (h *RegisterHandler) Register(c *gin.Context) {
+	var request struct {
+		Name     string `json:"Name"`
+		Email    string `json:"Email"`
+		NoTlp    string `json:"NoTlp"`
+		Password string `json:"Password"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	user, err := h.service.Register(request.Name, request.Email, request.NoTlp, request.Password)
+	if err != nil {
+		if errors.Is(err, adminRepo.ErrEmailExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Registrasi berhasil, cek email Anda untuk verifikasi",
+		"user":    user,
+	})
+}
+
+func (h *RegisterHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		var request struct {
+			Token string `json:"token"`
+		}
+		_ = c.ShouldBindJSON(&request)
+		token = request.Token
+	}
+
+	if err := h.service.VerifyEmail(token); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email berhasil diverifikasi, silakan login"})
+}
+
+func (h *RegisterHandler) ResendVerification(c *gin.Context) {
+	var request struct {
+		Email string `json:"email"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	if err := h.service.ResendVerification(request.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengirim email verifikasi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Jika email terdaftar dan belum diverifikasi, link verifikasi sudah dikirim"})
+}
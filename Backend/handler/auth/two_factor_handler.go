@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"astro-backend/middleware"
+	"astro-backend/service/auth"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorHandler struct {
+	service     auth.TwoFactorService
+	authService auth.AuthService
+}
+
+func NewTwoFactorHandler(service auth.TwoFactorService, authService auth.AuthService) *TwoFactorHandler {
+	return &TwoFactorHandler{service, authService}
+}
+
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	secret, uri, err := h.service.Setup(user)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Scan QR dari provisioning_uri lalu konfirmasi dengan kode dari aplikasi authenticator",
+		"secret":           secret,
+		"provisioning_uri": uri,
+	})
+}
+
+// Enable mengaktifkan 2FA. Kalau dipanggil dengan setup_token dari login
+// (role yang wajib 2FA), response juga berisi token login biasa.
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var request struct {
+		Code string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil || request.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code wajib diisi"})
+		return
+	}
+
+	codes, err := h.service.Enable(user, request.Code, clientInfo(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{
+		"message":        "2FA berhasil diaktifkan, simpan recovery code di tempat aman",
+		"recovery_codes": codes,
+	}
+
+	if c.GetString(middleware.ContextTokenType) == auth.TokenTypeMFASetup {
+		tokens, err := h.authService.CompleteLogin(user, clientInfo(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "2FA aktif, tetapi gagal membuat sesi login"})
+			return
+		}
+		response["access_token"] = tokens.AccessToken
+		response["refresh_token"] = tokens.RefreshToken
+		response["token_type"] = tokens.TokenType
+		response["expires_in"] = tokens.ExpiresIn
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var request struct {
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recovery_code"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	if err := h.service.Disable(user, request.Code, request.RecoveryCode, clientInfo(c)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "2FA berhasil dinonaktifkan"})
+}
+
+func (h *TwoFactorHandler) GetRequiredRoles(c *gin.Context) {
+	roles, err := h.service.RequiredRoles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil pengaturan 2FA"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"require_2fa_roles": roles})
+}
+
+func (h *TwoFactorHandler) SetRequiredRoles(c *gin.Context) {
+	var request struct {
+		Roles []string `json:"require_2fa_roles"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	if err := h.service.SetRequiredRoles(request.Roles); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pengaturan 2FA berhasil disimpan", "require_2fa_roles": request.Roles})
+}
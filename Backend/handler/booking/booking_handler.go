@@ -0,0 +1,231 @@
+package booking
+
+import (
+	"astro-backend/constants"
+	"astro-backend/middleware"
+	bookingRepo "astro-backend/repository/booking"
+	"astro-backend/service/booking"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type BookingHandler struct {
+	service booking.BookingService
+}
+
+func NewBookingHandler(service booking.BookingService) *BookingHandler {
+	return &BookingHandler{service}
+}
+
+// actor membaca user dari AuthMiddleware. Staff ditentukan dari permission
+// booking:read (lihat constants.RolePermissions).
+func actor(c *gin.Context) (booking.Actor, bool) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return booking.Actor{}, false
+	}
+	return booking.Actor{User: user, Staff: middleware.Can(user.Role, constants.PermBookingRead)}, true
+}
+
+func (h *BookingHandler) CreateBooking(c *gin.Context) {
+	a, ok := actor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input booking.CreateBookingInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format JSON tidak valid"})
+		return
+	}
+
+	result, err := h.service.Create(a, input)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, booking.ErrRoomNotAvailable) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Booking berhasil dibuat", "data": result})
+}
+
+func (h *BookingHandler) GetAllBookings(c *gin.Context) {
+	a, ok := actor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	filter := bookingRepo.BookingFilter{Status: c.Query("status")}
+	if uid := c.Query("user_id"); uid != "" {
+		oid, err := primitive.ObjectIDFromHex(uid)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "user_id tidak valid"})
+			return
+		}
+		filter.UserID = &oid
+	}
+	if rid := c.Query("room_id"); rid != "" {
+		oid, err := primitive.ObjectIDFromHex(rid)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "room_id tidak valid"})
+			return
+		}
+		filter.RoomID = &oid
+	}
+
+	bookings, err := h.service.GetAll(a, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data booking"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": bookings})
+}
+
+func (h *BookingHandler) GetBooking(c *gin.Context) {
+	a, ok := actor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := h.service.GetByID(a, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+func (h *BookingHandler) CancelBooking(c *gin.Context) {
+	a, ok := actor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var request struct {
+		Reason string `json:"reason"`
+	}
+	// body opsional
+	_ = c.ShouldBindJSON(&request)
+
+	result, err := h.service.Cancel(a, c.Param("id"), request.Reason)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, booking.ErrBookingNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Booking berhasil dibatalkan", "data": result})
+}
+
+// ModifyBooking mengubah tanggal, kamar atau jumlah tamu. Snapshot sebelum
+// dan sesudah dicatat ke activity log (Before / After).
+func (h *BookingHandler) ModifyBooking(c *gin.Context) {
+	a, ok := actor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input booking.ModifyBookingInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format JSON tidak valid"})
+		return
+	}
+
+	before, after, err := h.service.Modify(a, c.Param("id"), input)
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, booking.ErrBookingNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, booking.ErrRoomNotAvailable):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	middleware.RecordChange(c.Request.Context(), "bookings", after.Id.Hex(), before, after)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Booking berhasil diubah",
+		"data":             after,
+		"price_difference": after.PriceDifference,
+	})
+}
+
+// SearchAvailability adalah endpoint publik untuk mencari kamar kosong.
+// Query: check_in, check_out (YYYY-MM-DD), guests, room_type_id,
+// facilities (boleh diulang atau dipisah koma), min_price, max_price.
+func (h *BookingHandler) SearchAvailability(c *gin.Context) {
+	query := booking.AvailabilityQuery{
+		CheckIn:    c.Query("check_in"),
+		CheckOut:   c.Query("check_out"),
+		RoomTypeID: c.Query("room_type_id"),
+	}
+
+	if v := c.Query("guests"); v != "" {
+		guests, err := strconv.Atoi(v)
+		if err != nil || guests < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "guests tidak valid"})
+			return
+		}
+		query.Guests = guests
+	}
+
+	for _, v := range c.QueryArray("facilities") {
+		for _, id := range strings.Split(v, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				query.FacilityIDs = append(query.FacilityIDs, id)
+			}
+		}
+	}
+
+	var err error
+	if query.MinPrice, err = priceQuery(c, "min_price"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if query.MaxPrice, err = priceQuery(c, "max_price"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rooms, err := h.service.SearchAvailability(query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rooms})
+}
+
+// priceQuery membaca query harga opsional; nil kalau tidak diisi.
+func priceQuery(c *gin.Context, key string) (*float64, error) {
+	v := c.Query(key)
+	if v == "" {
+		return nil, nil
+	}
+	price, err := strconv.ParseFloat(v, 64)
+	if err != nil || price < 0 {
+		return nil, errors.New(key + " tidak valid")
+	}
+	return &price, nil
+}
@@ -0,0 +1,87 @@
+package booking
+
+import (
+	"astro-backend/service/booking"
+	"errors"
+	"net/http"
+
+	bookingRepo "astro-backend/repository/booking"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HoldHandler struct {
+	service booking.HoldService
+}
+
+func NewHoldHandler(service booking.HoldService) *HoldHandler {
+	return &HoldHandler{service}
+}
+
+func (h *HoldHandler) CreateHold(c *gin.Context) {
+	a, ok := actor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input booking.CreateBookingInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format JSON tidak valid"})
+		return
+	}
+
+	hold, err := h.service.Create(a, input)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, booking.ErrRoomNotAvailable) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Kamar berhasil ditahan", "data": hold})
+}
+
+func (h *HoldHandler) ConfirmHold(c *gin.Context) {
+	a, ok := actor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := h.service.Confirm(a, c.Param("id"))
+	if err != nil {
+		respondHoldError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Booking berhasil dibuat", "data": result})
+}
+
+func (h *HoldHandler) ReleaseHold(c *gin.Context) {
+	a, ok := actor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.Release(a, c.Param("id")); err != nil {
+		respondHoldError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Hold berhasil dilepas"})
+}
+
+func respondHoldError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, booking.ErrHoldNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, bookingRepo.ErrHoldNotActive):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
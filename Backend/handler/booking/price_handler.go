@@ -0,0 +1,33 @@
+package booking
+
+import (
+	"astro-backend/service/pricing"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PriceHandler struct {
+	service pricing.PricingService
+}
+
+func NewPriceHandler(service pricing.PricingService) *PriceHandler {
+	return &PriceHandler{service}
+}
+
+// GetRoomPrice mengembalikan harga per malam kamar untuk rentang tanggal
+// (?check_in=YYYY-MM-DD&check_out=YYYY-MM-DD) beserta totalnya.
+func (h *PriceHandler) GetRoomPrice(c *gin.Context) {
+	price, err := h.service.PriceRoom(c.Param("id"), c.Query("check_in"), c.Query("check_out"))
+	if errors.Is(err, pricing.ErrRoomNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, price)
+}
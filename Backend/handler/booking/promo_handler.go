@@ -0,0 +1,75 @@
+package booking
+
+import (
+	adminRepo "astro-backend/repository/admin"
+	"astro-backend/service/booking"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromoHandler struct {
+	service booking.PromoService
+}
+
+func NewPromoHandler(service booking.PromoService) *PromoHandler {
+	return &PromoHandler{service}
+}
+
+// ValidatePromo menghitung harga dengan promo tanpa memakai kuota.
+func (h *PromoHandler) ValidatePromo(c *gin.Context) {
+	a, ok := actor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input booking.PromoInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format JSON tidak valid"})
+		return
+	}
+
+	result, err := h.service.Validate(a, input)
+	if err != nil {
+		respondPromoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"valid": true, "data": result})
+}
+
+// RedeemPromo memakai promo untuk booking PENDING.
+func (h *PromoHandler) RedeemPromo(c *gin.Context) {
+	a, ok := actor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input booking.PromoInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format JSON tidak valid"})
+		return
+	}
+
+	result, err := h.service.Redeem(a, input)
+	if err != nil {
+		respondPromoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Promo berhasil dipakai", "data": result})
+}
+
+func respondPromoError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, booking.ErrBookingNotFound), errors.Is(err, booking.ErrQuoteNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, adminRepo.ErrPromoLimitReached):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,61 @@
+package booking
+
+import (
+	"astro-backend/service/booking"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QuoteHandler struct {
+	service booking.QuoteService
+}
+
+func NewQuoteHandler(service booking.QuoteService) *QuoteHandler {
+	return &QuoteHandler{service}
+}
+
+// CreateQuote menghitung dan menyimpan quote. ID quote bisa dikirim sebagai
+// quote_id saat membuat booking atau hold supaya harganya sama persis.
+func (h *QuoteHandler) CreateQuote(c *gin.Context) {
+	a, ok := actor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input booking.QuoteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format JSON tidak valid"})
+		return
+	}
+
+	quote, err := h.service.Create(a, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": quote})
+}
+
+func (h *QuoteHandler) GetQuote(c *gin.Context) {
+	a, ok := actor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	quote, err := h.service.GetByID(a, c.Param("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, booking.ErrQuoteNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": quote})
+}
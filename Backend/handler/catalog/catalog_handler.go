@@ -0,0 +1,85 @@
+package catalog
+
+import (
+	"astro-backend/service/catalog"
+	"astro-backend/utils"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CatalogHandler struct {
+	service catalog.CatalogService
+}
+
+func NewCatalogHandler(service catalog.CatalogService) *CatalogHandler {
+	return &CatalogHandler{service}
+}
+
+// ListRooms adalah katalog kamar publik. Query: page, limit, cursor,
+// sort_by (price|capacity), sort_order (asc|desc), category, bed_type,
+// room_type_id, facilities (boleh diulang atau dipisah koma).
+func (h *CatalogHandler) ListRooms(c *gin.Context) {
+	q, err := utils.ParseListQuery(c.Request.URL.Query(), catalog.RoomSortFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter := catalog.RoomFilter{
+		Category:   c.Query("category"),
+		BedType:    c.Query("bed_type"),
+		RoomTypeID: c.Query("room_type_id"),
+	}
+	for _, v := range c.QueryArray("facilities") {
+		for _, id := range strings.Split(v, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				filter.FacilityIDs = append(filter.FacilityIDs, id)
+			}
+		}
+	}
+
+	page, err := h.service.ListRooms(filter, q)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *CatalogHandler) GetRoom(c *gin.Context) {
+	room, err := h.service.GetRoom(c.Param("id"))
+	if errors.Is(err, catalog.ErrRoomNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": room})
+}
+
+func (h *CatalogHandler) ListRoomTypes(c *gin.Context) {
+	roomTypes, err := h.service.ListRoomTypes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": roomTypes})
+}
+
+func (h *CatalogHandler) ListFacilities(c *gin.Context) {
+	facilities, err := h.service.ListFacilities()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": facilities})
+}
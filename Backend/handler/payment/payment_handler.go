@@ -0,0 +1,193 @@
+package payment
+
+import (
+	"astro-backend/constants"
+	"astro-backend/middleware"
+	paymentRepo "astro-backend/repository/payment"
+	"astro-backend/service/booking"
+	"astro-backend/service/payment"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxWebhookBody membatasi ukuran payload webhook.
+const maxWebhookBody = 1 << 20
+
+type PaymentHandler struct {
+	service payment.PaymentService
+}
+
+func NewPaymentHandler(service payment.PaymentService) *PaymentHandler {
+	return &PaymentHandler{service}
+}
+
+// actor membaca user dari AuthMiddleware. Staff (payment:read) bisa melihat
+// semua pembayaran dan membuat tagihan kamar.
+func actor(c *gin.Context) (booking.Actor, bool) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return booking.Actor{}, false
+	}
+	return booking.Actor{User: user, Staff: middleware.Can(user.Role, constants.PermPaymentRead)}, true
+}
+
+func (h *PaymentHandler) CreatePayment(c *gin.Context) {
+	a, ok := actor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input payment.ChargeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format JSON tidak valid"})
+		return
+	}
+
+	result, err := h.service.Charge(a, input)
+	if err != nil {
+		respondPaymentError(c, err)
+		return
+	}
+
+	// pembayaran yang ditolak provider tetap tercatat, tapi dilaporkan sebagai 402
+	status := http.StatusCreated
+	if result.Status == constants.PaymentStatusFailed {
+		status = http.StatusPaymentRequired
+	}
+	c.JSON(status, gin.H{"data": result})
+}
+
+func (h *PaymentHandler) GetAllPayments(c *gin.Context) {
+	a, ok := actor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	filter := paymentRepo.PaymentFilter{
+		PayableType: c.Query("payable_type"),
+		Status:      c.Query("status"),
+	}
+	if id := c.Query("payable_id"); id != "" {
+		oid, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "payable_id tidak valid"})
+			return
+		}
+		filter.PayableID = &oid
+	}
+
+	payments, err := h.service.GetAll(a, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data pembayaran"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": payments})
+}
+
+func (h *PaymentHandler) GetPayment(c *gin.Context) {
+	a, ok := actor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := h.service.GetByID(a, c.Param("id"))
+	if err != nil {
+		respondPaymentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// RefreshPayment menanyakan status terbaru ke provider.
+func (h *PaymentHandler) RefreshPayment(c *gin.Context) {
+	a, ok := actor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := h.service.Refresh(a, c.Param("id"))
+	if err != nil {
+		respondPaymentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+func (h *PaymentHandler) CapturePayment(c *gin.Context) {
+	var request struct {
+		Amount float64 `json:"amount"`
+	}
+	// body opsional; tanpa amount berarti capture penuh
+	_ = c.ShouldBindJSON(&request)
+
+	result, err := h.service.Capture(c.Param("id"), request.Amount)
+	if err != nil {
+		respondPaymentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pembayaran berhasil di-capture", "data": result})
+}
+
+func (h *PaymentHandler) RefundPayment(c *gin.Context) {
+	var request struct {
+		Amount float64 `json:"amount"`
+		Reason string  `json:"reason"`
+	}
+	// body opsional; tanpa amount berarti refund seluruh sisa dana
+	_ = c.ShouldBindJSON(&request)
+
+	result, err := h.service.Refund(c.Param("id"), request.Amount, request.Reason)
+	if err != nil {
+		respondPaymentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Refund berhasil", "data": result})
+}
+
+// Webhook menerima event dari provider. Tidak memakai login; keaslian
+// request dicek lewat signature HMAC.
+func (h *PaymentHandler) Webhook(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal membaca payload"})
+		return
+	}
+
+	if err := h.service.HandleWebhook(c.Param("provider"), c.Request.Header, body); err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, payment.ErrInvalidSignature):
+			status = http.StatusUnauthorized
+		case errors.Is(err, payment.ErrPaymentNotFound), errors.Is(err, payment.ErrUnknownProvider):
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"received": true})
+}
+
+func respondPaymentError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, payment.ErrPaymentNotFound), errors.Is(err, booking.ErrBookingNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, payment.ErrInvalidOperation), errors.Is(err, payment.ErrNothingToPay):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"os"
+)
+
+// Message adalah email sederhana berformat teks.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender mengirim email. Implementasi dipilih lewat env MAIL_DRIVER.
+type Sender interface {
+	Send(msg Message) error
+}
+
+// NewSenderFromEnv memilih implementasi Sender:
+//   - MAIL_DRIVER=smtp  -> kirim lewat SMTP_HOST / SMTP_PORT
+//   - selain itu        -> tulis ke folder outbox (MAIL_OUTBOX_DIR), cocok untuk offline/dev
+func NewSenderFromEnv() Sender {
+	from := getEnv("MAIL_FROM", "no-reply@astro.local")
+
+	switch os.Getenv("MAIL_DRIVER") {
+	case "smtp":
+		return NewSMTPSender(
+			os.Getenv("SMTP_HOST"),
+			getEnv("SMTP_PORT", "587"),
+			os.Getenv("SMTP_USERNAME"),
+			os.Getenv("SMTP_PASSWORD"),
+			from,
+		)
+	default:
+		return NewFileSender(getEnv("MAIL_OUTBOX_DIR", "outbox"), from)
+	}
+}
+
+func getEnv(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
+	}
+	return def
+}
@@ -4,15 +4,22 @@ import (
 	"astro-backend/config"
 	"astro-backend/routes"
 	"astro-backend/middleware"
+	"astro-backend/scheduler"
 
 	activityRepo "astro-backend/repository/activityLog"
 	activityService "astro-backend/service/activityLog"
+	adminRepo "astro-backend/repository/admin"
+	authRepo "astro-backend/repository/auth"
 
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
-	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
@@ -32,6 +39,25 @@ func main() {
 		log.Fatalf("❌ MongoDB is nil. Check connection.")
 	}
 
+	// state login SSO yang ditinggalkan dihapus oleh TTL index
+	if err := authRepo.NewOIDCStateRepository().EnsureIndexes(); err != nil {
+		log.Fatalf("❌ Gagal membuat index oidc_state: %v", err)
+	}
+
+	// foto format lama (string URL) diberi ID supaya bisa dikelola per foto
+	if err := adminRepo.NewRoomRepository().MigrateLegacyImages(); err != nil {
+		log.Printf("⚠️ Gagal migrasi foto kamar: %v", err)
+	}
+
+	// foto yang tertinggal PROCESSING dari proses sebelumnya tidak akan
+	// pernah selesai karena file staging-nya sudah hilang; harus jalan
+	// sebelum image processor dimulai di AdminRoutes
+	if n, err := adminRepo.NewRoomRepository().FailStaleProcessingImages(); err != nil {
+		log.Printf("⚠️ Gagal menandai foto kamar yang tertinggal: %v", err)
+	} else if n > 0 {
+		log.Printf("⚠️ %d kamar punya foto PROCESSING yang tertinggal, ditandai FAILED", n)
+	}
+
 	// === 3. Setup Gin Mode ===
 	ginMode := os.Getenv("GIN_MODE")
 	if ginMode == "" {
@@ -39,6 +65,12 @@ func main() {
 	}
 	gin.SetMode(ginMode)
 
+	// secret JWT yang diketahui publik berarti siapa pun bisa memalsukan token
+	// Admin; di release mode server menolak jalan tanpa secret yang layak
+	if gin.Mode() == gin.ReleaseMode && len(os.Getenv("JWT_SECRET")) < 32 {
+		log.Fatalf("❌ JWT_SECRET wajib diisi (minimal 32 karakter) saat GIN_MODE=release")
+	}
+
 	r := gin.Default()
 
 	// === 4. Init Activity Log Dependencies ===
@@ -56,24 +88,49 @@ func main() {
 	flushTimeout := 2 * time.Second
 	aService := activityService.NewActivityLogService(aRepo, batchSize, flushTimeout)
 	// === 5. Register Middlewares ===
-	r.Use(gin.WrapH(
-		middleware.ActivityLoggerMiddleware(aService)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// pass-through handler for gin, this will be replaced by Gin router
-			// we just need a wrapper for middleware
-		})),
-	))
+	// WrapHTTP membungkus seluruh chain gin, jadi logger melihat status response
+	// dan user_id / user_email yang diisi AuthMiddleware
+	r.Use(middleware.WrapHTTP(middleware.ActivityLoggerMiddleware(aService)))
 	// === 6. Register Routes ===
-	routes.AuthRoutes(r)
-	routes.AdminRoutes(r)
+	routes.AuthRoutes(r, aService)
+	imageProcessor := routes.AdminRoutes(r, aService)
+	holdService := routes.BookingRoutes(r, aService)
+	routes.CatalogRoutes(r)
+	routes.PaymentRoutes(r, aService)
+	routes.ActivityLogRoutes(r, aService)
+
+	// === 7. Background Jobs ===
+	// hold kamar yang kedaluwarsa dilepas berkala (HOLD_EXPIRY_INTERVAL)
+	holdExpiryJob := scheduler.NewHoldExpiryJob(holdService, aService)
+	go holdExpiryJob.Start(context.Background())
+	defer holdExpiryJob.Stop()
 
-	// === 7. Run Server ===
+	// === 8. Run Server ===
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
-	fmt.Printf("🚀 Server running on port %s\n", port)
-	if err := r.Run(":" + port); err != nil {
-		log.Fatalf("❌ Failed starting server: %v", err)
+	srv := &http.Server{Addr: ":" + port, Handler: r}
+	go func() {
+		fmt.Printf("🚀 Server running on port %s\n", port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("❌ Failed starting server: %v", err)
+		}
+	}()
+
+	// === 9. Graceful Shutdown ===
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	fmt.Println("🛑 Shutting down server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("❌ Server shutdown: %v", err)
 	}
+
+	// antrian foto diselesaikan sebelum koneksi MongoDB ditutup
+	imageProcessor.Stop()
 }
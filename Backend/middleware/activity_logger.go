@@ -5,6 +5,7 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -63,6 +64,16 @@ func ActivityLoggerMiddleware(svc activityLog.ActivityLogService) func(next http
 				r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 			}
 
+			// diisi APIKeyAuth kalau request memakai API key
+			keyRef := &APIKeyRef{}
+			r = r.WithContext(context.WithValue(r.Context(), ContextAPIKey, keyRef))
+			// diisi handler lewat RecordChange untuk snapshot before / after
+			auditRef := &AuditRef{}
+			r = r.WithContext(context.WithValue(r.Context(), ContextAudit, auditRef))
+			// diisi Authorize kalau request ditolak karena permission
+			securityRef := &SecurityRef{}
+			r = r.WithContext(context.WithValue(r.Context(), ContextSecurity, securityRef))
+
 			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK, buf: bytes.NewBuffer(nil)}
 			// call next
 			next.ServeHTTP(rec, r)
@@ -82,12 +93,21 @@ func ActivityLoggerMiddleware(svc activityLog.ActivityLogService) func(next http
 			}
 
 			// Attempt to populate user info from context (if authentication middleware set them)
-			if uid, ok := r.Context().Value("user_id").(primitive.ObjectID); ok {
+			if uid, ok := r.Context().Value(ContextUserID).(primitive.ObjectID); ok {
 				al.UserID = &uid
 			}
-			if email, ok := r.Context().Value("user_email").(string); ok {
+			if email, ok := r.Context().Value(ContextUserEmail).(string); ok {
 				al.UserEmail = email
 			}
+			// session dari token lebih dipercaya daripada header X-Session-Id
+			if sid, ok := r.Context().Value(ContextSessionID).(string); ok && sid != "" {
+				al.SessionID = sid
+			}
+
+			if !keyRef.ID.IsZero() {
+				keyID := keyRef.ID
+				al.APIKeyID = &keyID
+			}
 
 			// If request body JSON, sanitize and include
 			if len(reqBodyCopy) > 0 {
@@ -112,9 +132,24 @@ func ActivityLoggerMiddleware(svc activityLog.ActivityLogService) func(next http
 				al.ActionType = "OTHER"
 			}
 			// Special-case booking endpoints - you can adjust pattern matching to your routes
-			if r.URL.Path == "/api/bookings" && r.Method == http.MethodPost {
+			if (strings.HasPrefix(r.URL.Path, "/api/bookings") || strings.HasPrefix(r.URL.Path, "/api/holds") || r.URL.Path == "/api/promos/redeem") && r.Method != http.MethodGet {
 				al.ActionType = constants.ActBooking
 				al.Category = constants.CategoryCritical
+				al.Resource = "bookings"
+			}
+			if strings.HasPrefix(r.URL.Path, "/api/payments") && r.Method != http.MethodGet {
+				al.ActionType = constants.ActPayment
+				al.Category = constants.CategoryCritical
+				al.Resource = "payments"
+			}
+
+			if auditRef.Before != nil || auditRef.After != nil {
+				al.Before = auditRef.Before
+				al.After = auditRef.After
+				al.ResourceID = auditRef.ResourceID
+				if auditRef.Resource != "" {
+					al.Resource = auditRef.Resource
+				}
 			}
 
 			// Set status text
@@ -127,6 +162,17 @@ func ActivityLoggerMiddleware(svc activityLog.ActivityLogService) func(next http
 			al.Metadata = primitive.M{
 				"latency_ms": duration.Milliseconds(),
 			}
+			if al.APIKeyID != nil {
+				al.Metadata["api_key_name"] = keyRef.Name
+			}
+			if securityRef.Message != "" {
+				al.ActionType = constants.ActAdmin
+				al.Category = constants.CategorySecurity
+				al.Message = securityRef.Message
+				for k, v := range securityRef.Metadata {
+					al.Metadata[k] = v
+				}
+			}
 
 			// Non-blocking log (best-effort). Log errors locally only.
 			if err := svc.Log(context.Background(), al); err != nil {
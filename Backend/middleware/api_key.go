@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"astro-backend/service/auth"
+	"context"
+	"net/http"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ContextAPIKey menyimpan *APIKeyRef di request context.
+const ContextAPIKey = "api_key"
+
+// APIKeyRef diisi APIKeyAuth setelah key valid. ActivityLoggerMiddleware
+// memasang pointer kosong sebelum handler jalan, karena router lain (mis.
+// mux di dalam gin.WrapH) membuat request baru yang context-nya tidak
+// kembali ke logger.
+type APIKeyRef struct {
+	ID   primitive.ObjectID
+	Name string
+}
+
+// APIKeyAuth memvalidasi header "Authorization: Bearer <api key>" (atau
+// X-API-Key) dan memastikan key punya scope yang diminta.
+func APIKeyAuth(svc auth.APIKeyService, scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw := r.Header.Get("X-API-Key")
+			if raw == "" {
+				raw, _ = strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			}
+			raw = strings.TrimSpace(raw)
+			if raw == "" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			key, err := svc.Authenticate(raw, scope)
+			if err != nil {
+				status := http.StatusUnauthorized
+				if !key.Id.IsZero() {
+					// key valid tapi scope kurang
+					status = http.StatusForbidden
+					setAPIKeyRef(r, key.Id, key.Name)
+				}
+				http.Error(w, err.Error(), status)
+				return
+			}
+
+			if !setAPIKeyRef(r, key.Id, key.Name) {
+				r = r.WithContext(context.WithValue(r.Context(), ContextAPIKey, &APIKeyRef{ID: key.Id, Name: key.Name}))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// setAPIKeyRef mengisi APIKeyRef yang sudah dipasang logger. Mengembalikan
+// false kalau context belum punya APIKeyRef.
+func setAPIKeyRef(r *http.Request, id primitive.ObjectID, name string) bool {
+	ref, ok := r.Context().Value(ContextAPIKey).(*APIKeyRef)
+	if !ok {
+		return false
+	}
+	ref.ID = id
+	ref.Name = name
+	return true
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ContextAudit menyimpan *AuditRef di request context.
+const ContextAudit = "audit"
+
+// AuditRef diisi handler lewat RecordChange. Sama seperti APIKeyRef,
+// ActivityLoggerMiddleware memasang pointer kosong sebelum handler jalan lalu
+// menyalin isinya ke ActivityLog.Before / After.
+type AuditRef struct {
+	Resource   string
+	ResourceID string
+	Before     primitive.M
+	After      primitive.M
+}
+
+// RecordChange mencatat snapshot sebelum dan sesudah perubahan untuk
+// activity log request ini. Tidak melakukan apa-apa kalau logger tidak aktif.
+func RecordChange(ctx context.Context, resource, resourceID string, before, after any) {
+	ref, ok := ctx.Value(ContextAudit).(*AuditRef)
+	if !ok || ref == nil {
+		return
+	}
+
+	ref.Resource = resource
+	ref.ResourceID = resourceID
+	ref.Before = snapshot(before)
+	ref.After = snapshot(after)
+}
+
+// snapshot mengubah struct menjadi map memakai tag bson, jadi isinya sama
+// dengan dokumen di database.
+func snapshot(v any) primitive.M {
+	if v == nil {
+		return nil
+	}
+	raw, err := bson.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var m primitive.M
+	if err := bson.Unmarshal(raw, &m); err != nil {
+		return nil
+	}
+	return m
+}
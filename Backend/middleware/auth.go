@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"astro-backend/models"
+	"astro-backend/service/auth"
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Key yang dipakai untuk menyimpan identitas user di gin context dan
+// request context. ActivityLoggerMiddleware membaca key yang sama.
+const (
+	ContextUserID    = "user_id"
+	ContextUserEmail = "user_email"
+	ContextUserRole  = "user_role"
+	ContextUser      = "user"
+	ContextTokenType = "token_type"
+	ContextSessionID = "session_id"
+)
+
+// AuthMiddleware memvalidasi access token dari header Authorization
+// ("Bearer <token>") lalu mengisi user_id (primitive.ObjectID) dan
+// user_email ke context. allowedTypes opsional, default hanya access token.
+func AuthMiddleware(svc auth.AuthService, allowedTypes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || strings.TrimSpace(token) == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token tidak ditemukan"})
+			return
+		}
+
+		user, claims, err := svc.Authenticate(strings.TrimSpace(token), allowedTypes...)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(ContextUserID, user.Id)
+		c.Set(ContextUserEmail, user.Email)
+		c.Set(ContextUserRole, user.Role)
+		c.Set(ContextUser, user)
+		c.Set(ContextTokenType, claims.Type)
+		c.Set(ContextSessionID, claims.SessionID)
+
+		ctx := context.WithValue(c.Request.Context(), ContextUserID, user.Id)
+		ctx = context.WithValue(ctx, ContextUserEmail, user.Email)
+		ctx = context.WithValue(ctx, ContextUserRole, user.Role)
+		ctx = context.WithValue(ctx, ContextSessionID, claims.SessionID)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// CurrentUser mengembalikan user yang diisi AuthMiddleware.
+func CurrentUser(c *gin.Context) (models.User, bool) {
+	v, ok := c.Get(ContextUser)
+	if !ok {
+		return models.User{}, false
+	}
+	user, ok := v.(models.User)
+	return user, ok
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WrapHTTP membungkus middleware net/http supaya bisa dipasang lewat r.Use
+// dan tetap membungkus seluruh chain gin (handler dan middleware berikutnya).
+func WrapHTTP(mw func(http.Handler) http.Handler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		called := false
+		mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			c.Request = r
+			c.Writer = &wrappedWriter{ResponseWriter: c.Writer, w: w}
+			c.Next()
+
+			// Middleware lain (mis. AuthMiddleware) mengganti c.Request dengan
+			// context baru. Salin kembali ke r supaya middleware luar bisa
+			// membaca user_id / user_email setelah handler selesai.
+			*r = *c.Request
+		})).ServeHTTP(c.Writer, c.Request)
+
+		if !called {
+			c.Abort()
+		}
+	}
+}
+
+// wrappedWriter meneruskan semua penulisan response ke writer milik
+// middleware net/http (mis. responseRecorder) supaya bisa direkam.
+type wrappedWriter struct {
+	gin.ResponseWriter
+	w http.ResponseWriter
+}
+
+func (ww *wrappedWriter) WriteHeader(code int) {
+	ww.w.WriteHeader(code)
+}
+
+func (ww *wrappedWriter) Write(b []byte) (int, error) {
+	return ww.w.Write(b)
+}
+
+func (ww *wrappedWriter) WriteString(s string) (int, error) {
+	return ww.w.Write([]byte(s))
+}
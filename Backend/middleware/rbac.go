@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	"astro-backend/service/activityLog"
+	"astro-backend/utils"
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ContextSecurity menyimpan *SecurityRef di request context.
+const ContextSecurity = "security"
+
+// SecurityRef diisi Authorize saat request ditolak. Sama seperti AuditRef,
+// ActivityLoggerMiddleware memasang pointer kosong lalu mencatat request itu
+// sebagai log SECURITY, jadi penolakan hanya tercatat satu kali.
+type SecurityRef struct {
+	Message  string
+	Metadata primitive.M
+}
+
+// Can mengecek apakah role punya permission tertentu. Nama role dibandingkan
+// tanpa memperhatikan huruf besar/kecil karena data lama tidak seragam.
+func Can(role, permission string) bool {
+	for r, perms := range constants.RolePermissions {
+		if !strings.EqualFold(r, strings.TrimSpace(role)) {
+			continue
+		}
+		for _, p := range perms {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Authorize harus dipasang setelah AuthMiddleware. Request dari role yang
+// tidak punya permission ditolak dengan 403 dan dicatat sebagai log SECURITY
+// (lewat SecurityRef kalau request dibungkus ActivityLoggerMiddleware).
+func Authorize(permission string, logSvc activityLog.ActivityLogService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString(ContextUserRole)
+		if Can(role, permission) {
+			c.Next()
+			return
+		}
+
+		metadata := primitive.M{"role": role, "permission": permission}
+		if ref, ok := c.Request.Context().Value(ContextSecurity).(*SecurityRef); ok && ref != nil {
+			ref.Message = "akses ditolak"
+			ref.Metadata = metadata
+		} else if logSvc != nil {
+			// tanpa ActivityLoggerMiddleware penolakan dicatat langsung
+			entry := models.ActivityLog{
+				UserEmail:      c.GetString(ContextUserEmail),
+				ActionType:     constants.ActAdmin,
+				Category:       constants.CategorySecurity,
+				Endpoint:       c.Request.URL.Path,
+				Method:         c.Request.Method,
+				IPAddress:      utils.ExtractIP(c.Request),
+				UserAgent:      c.Request.UserAgent(),
+				ResponseStatus: http.StatusForbidden,
+				Message:        "akses ditolak",
+				Metadata:       metadata,
+				Status:         constants.StatusFailed,
+			}
+			if uid, ok := c.Get(ContextUserID); ok {
+				if oid, ok := uid.(primitive.ObjectID); ok {
+					entry.UserID = &oid
+				}
+			}
+			if err := logSvc.Log(context.Background(), entry); err != nil {
+				log.Error().Err(err).Msg("activity log failed")
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Anda tidak punya akses ke resource ini"})
+	}
+}
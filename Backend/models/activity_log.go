@@ -11,6 +11,7 @@ type ActivityLog struct {
 	ID             primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
 	UserID         *primitive.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty"` // nullable untuk guest
 	UserEmail      string              `bson:"user_email,omitempty" json:"user_email,omitempty"`
+	APIKeyID       *primitive.ObjectID `bson:"api_key_id,omitempty" json:"api_key_id,omitempty"` // jika request memakai API key
 	SessionID      string              `bson:"session_id,omitempty" json:"session_id,omitempty"` // jika ada
 	RequestID      string              `bson:"request_id,omitempty" json:"request_id,omitempty"` // trace id
 	ActionType     string              `bson:"action_type" json:"action_type"`                   // CREATE, UPDATE, DELETE, LOGIN, BOOKING, PAYMENT...
@@ -0,0 +1,19 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// APIKey adalah kredensial untuk integrasi (bukan user). Key mentah hanya
+// ditampilkan sekali saat dibuat / dirotasi; yang disimpan hanya hash-nya.
+type APIKey struct {
+	Id         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Name       string              `bson:"name" json:"name"`
+	Prefix     string              `bson:"prefix" json:"prefix"` // potongan awal key untuk identifikasi
+	KeyHash    string              `bson:"key_hash" json:"-"`
+	Scopes     []string            `bson:"scopes" json:"scopes"`
+	ExpiresAt  *primitive.DateTime `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+	LastUsedAt *primitive.DateTime `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+	RevokedAt  *primitive.DateTime `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	RotatedAt  *primitive.DateTime `bson:"rotated_at,omitempty" json:"rotated_at,omitempty"`
+	CreatedBy  *primitive.ObjectID `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	CreatedAt  primitive.DateTime  `bson:"created_at" json:"created_at"`
+}
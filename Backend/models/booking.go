@@ -0,0 +1,36 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+type Booking struct {
+	Id            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID        primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	RoomID        primitive.ObjectID  `bson:"room_id" json:"room_id"`
+	CheckIn       primitive.DateTime  `bson:"check_in" json:"check_in"`   // tengah malam UTC
+	CheckOut      primitive.DateTime  `bson:"check_out" json:"check_out"` // tengah malam UTC, tidak ikut dihitung
+	Nights        int                 `bson:"nights" json:"nights"`
+	Guests        int                 `bson:"guests" json:"guests"`
+	Status        string              `bson:"status" json:"status"`
+	PricePerNight float64             `bson:"price_per_night" json:"price_per_night"`
+	Subtotal      float64             `bson:"subtotal" json:"subtotal"`
+	ServiceCharge float64             `bson:"service_charge" json:"service_charge"`
+	Discount      float64             `bson:"discount,omitempty" json:"discount,omitempty"`
+	PromoCode     string              `bson:"promo_code,omitempty" json:"promo_code,omitempty"`
+	Tax           float64             `bson:"tax" json:"tax"`
+	TotalPrice    float64             `bson:"total_price" json:"total_price"`                     // subtotal - diskon + service charge + pajak
+	AmountPaid    float64             `bson:"amount_paid,omitempty" json:"amount_paid,omitempty"` // dari payment yang sudah di-capture
+	NightlyRates  []NightlyRate       `bson:"nightly_rates,omitempty" json:"nightly_rates,omitempty"`
+	QuoteID       *primitive.ObjectID `bson:"quote_id,omitempty" json:"quote_id,omitempty"`
+	// PriceDifference adalah selisih total dari perubahan terakhir:
+	// positif = kurang bayar, negatif = dikembalikan ke tamu.
+	PriceDifference float64             `bson:"price_difference,omitempty" json:"price_difference,omitempty"`
+	ModifiedAt      *primitive.DateTime `bson:"modified_at,omitempty" json:"modified_at,omitempty"`
+	Notes           string              `bson:"notes,omitempty" json:"notes,omitempty"`
+	CancelledAt     *primitive.DateTime `bson:"cancelled_at,omitempty" json:"cancelled_at,omitempty"`
+	CancelReason    string              `bson:"cancel_reason,omitempty" json:"cancel_reason,omitempty"`
+	Cancellation    *CancellationResult `bson:"cancellation,omitempty" json:"cancellation,omitempty"`
+	CreatedAt       primitive.DateTime  `bson:"created_at" json:"created_at"`
+	UpdatedAt       primitive.DateTime  `bson:"updated_at" json:"updated_at"`
+
+	Room *Room `bson:"room,omitempty" json:"room,omitempty"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// CancellationPolicy dipasang di RoomType. Pembatalan gratis kalau dilakukan
+// paling lambat FreeUntilDays hari sebelum check-in; setelah itu dikenai
+// denda PenaltyType. NonRefundable berarti tidak ada refund sama sekali.
+type CancellationPolicy struct {
+	NonRefundable  bool    `bson:"non_refundable" json:"non_refundable"`
+	FreeUntilDays  int     `bson:"free_until_days" json:"free_until_days"`
+	PenaltyType    string  `bson:"penalty_type,omitempty" json:"penalty_type,omitempty"` // PERCENT / FIRST_NIGHT
+	PenaltyPercent float64 `bson:"penalty_percent,omitempty" json:"penalty_percent,omitempty"`
+}
+
+// CancellationResult adalah hasil evaluasi policy saat booking dibatalkan.
+type CancellationResult struct {
+	Rule              string              `bson:"rule" json:"rule"`
+	Policy            *CancellationPolicy `bson:"policy,omitempty" json:"policy,omitempty"`
+	DaysBeforeArrival int                 `bson:"days_before_arrival" json:"days_before_arrival"`
+	AmountPaid        float64             `bson:"amount_paid" json:"amount_paid"`
+	Penalty           float64             `bson:"penalty" json:"penalty"`
+	Refund            float64             `bson:"refund" json:"refund"`
+	EvaluatedAt       primitive.DateTime  `bson:"evaluated_at" json:"evaluated_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// OIDCLoginState menyimpan data sementara antara redirect ke IdP dan
+// callback: hash parameter state, PKCE code verifier dan nonce.
+type OIDCLoginState struct {
+	Id           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	StateHash    string             `bson:"state_hash" json:"-"`
+	CodeVerifier string             `bson:"code_verifier" json:"-"`
+	Nonce        string             `bson:"nonce" json:"-"`
+	ExpiresAt    primitive.DateTime `bson:"expires_at" json:"expires_at"`
+	CreatedAt    primitive.DateTime `bson:"created_at" json:"created_at"`
+}
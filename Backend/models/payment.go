@@ -0,0 +1,36 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Payment adalah satu transaksi ke payment provider. PayableType dan
+// PayableID menunjuk tagihan yang dibayar: booking (menginap) atau
+// room_charge (tagihan tambahan atas sebuah kamar).
+type Payment struct {
+	Id             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	PayableType    string             `bson:"payable_type" json:"payable_type"`
+	PayableID      primitive.ObjectID `bson:"payable_id" json:"payable_id"`
+	UserID         primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Description    string             `bson:"description,omitempty" json:"description,omitempty"`
+	Amount         float64            `bson:"amount" json:"amount"`
+	Currency       string             `bson:"currency" json:"currency"`
+	Provider       string             `bson:"provider" json:"provider"`
+	ProviderRef    string             `bson:"provider_ref,omitempty" json:"provider_ref,omitempty"`
+	Status         string             `bson:"status" json:"status"`
+	CapturedAmount float64            `bson:"captured_amount" json:"captured_amount"`
+	RefundedAmount float64            `bson:"refunded_amount" json:"refunded_amount"`
+	FailureReason  string             `bson:"failure_reason,omitempty" json:"failure_reason,omitempty"`
+	Events         []PaymentEvent     `bson:"events" json:"events"`
+	CreatedAt      primitive.DateTime `bson:"created_at" json:"created_at"`
+	UpdatedAt      primitive.DateTime `bson:"updated_at" json:"updated_at"`
+}
+
+// PaymentEvent adalah riwayat perubahan status pembayaran. EventID diisi
+// untuk event dari webhook supaya event yang sama tidak diproses dua kali.
+type PaymentEvent struct {
+	Type      string             `bson:"type" json:"type"`
+	EventID   string             `bson:"event_id,omitempty" json:"event_id,omitempty"`
+	Status    string             `bson:"status" json:"status"`
+	Amount    float64            `bson:"amount,omitempty" json:"amount,omitempty"`
+	Note      string             `bson:"note,omitempty" json:"note,omitempty"`
+	CreatedAt primitive.DateTime `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// PromoCode adalah kode diskon untuk kampanye marketing. Diskon dihitung
+// dari subtotal kamar (sebelum service charge dan pajak).
+type PromoCode struct {
+	ID           primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Code         string               `bson:"code" json:"code"` // disimpan huruf besar
+	Description  string               `bson:"description,omitempty" json:"description,omitempty"`
+	DiscountType string               `bson:"discount_type" json:"discount_type"` // PERCENT / FIXED
+	Value        float64              `bson:"value" json:"value"`
+	MaxDiscount  float64              `bson:"max_discount,omitempty" json:"max_discount,omitempty"` // batas untuk PERCENT, 0 = tanpa batas
+	ValidFrom    *primitive.DateTime  `bson:"valid_from,omitempty" json:"valid_from,omitempty"`
+	ValidUntil   *primitive.DateTime  `bson:"valid_until,omitempty" json:"valid_until,omitempty"`
+	MinNights    int                  `bson:"min_nights,omitempty" json:"min_nights,omitempty"`
+	RoomTypeIDs  []primitive.ObjectID `bson:"room_type_ids,omitempty" json:"room_type_ids,omitempty"` // kosong = semua tipe
+	UsageLimit   int                  `bson:"usage_limit" json:"usage_limit"`                         // total pemakaian, 0 = tanpa batas
+	PerUserLimit int                  `bson:"per_user_limit" json:"per_user_limit"`                   // per user, 0 = tanpa batas
+	UsedCount    int                  `bson:"used_count" json:"used_count"`
+	Active       bool                 `bson:"active" json:"active"`
+	CreatedAt    primitive.DateTime   `bson:"created_at" json:"created_at"`
+	UpdatedAt    primitive.DateTime   `bson:"updated_at" json:"updated_at"`
+}
+
+// PromoRedemption mencatat pemakaian promo code pada sebuah booking.
+type PromoRedemption struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	PromoID   primitive.ObjectID `bson:"promo_id" json:"promo_id"`
+	Code      string             `bson:"code" json:"code"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	BookingID primitive.ObjectID `bson:"booking_id" json:"booking_id"`
+	Discount  float64            `bson:"discount" json:"discount"`
+	CreatedAt primitive.DateTime `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Quote adalah penawaran harga untuk satu kamar dan rentang tanggal. Quote
+// disimpan dengan masa berlaku supaya booking / hold yang memakai QuoteID
+// dikenai jumlah yang sama persis dengan yang ditampilkan ke tamu.
+type Quote struct {
+	Id            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID        primitive.ObjectID `bson:"user_id" json:"user_id"`
+	RoomID        primitive.ObjectID `bson:"room_id" json:"room_id"`
+	CheckIn       primitive.DateTime `bson:"check_in" json:"check_in"`
+	CheckOut      primitive.DateTime `bson:"check_out" json:"check_out"`
+	Nights        int                `bson:"nights" json:"nights"`
+	Guests        int                `bson:"guests" json:"guests"`
+	Currency      string             `bson:"currency" json:"currency"`
+	NightlyRates  []NightlyRate      `bson:"nightly_rates" json:"nightly_rates"`
+	Subtotal      float64            `bson:"subtotal" json:"subtotal"`
+	Charges       []QuoteCharge      `bson:"charges" json:"charges"`
+	ServiceCharge float64            `bson:"service_charge" json:"service_charge"`
+	Tax           float64            `bson:"tax" json:"tax"`
+	GrandTotal    float64            `bson:"grand_total" json:"grand_total"`
+	ExpiresAt     primitive.DateTime `bson:"expires_at" json:"expires_at"`
+	CreatedAt     primitive.DateTime `bson:"created_at" json:"created_at"`
+}
+
+// QuoteCharge adalah satu baris biaya tambahan di atas subtotal kamar.
+type QuoteCharge struct {
+	Code        string  `bson:"code" json:"code"` // SERVICE_CHARGE, TAX
+	Description string  `bson:"description" json:"description"`
+	Percent     float64 `bson:"percent" json:"percent"`
+	Base        float64 `bson:"base" json:"base"`
+	Amount      float64 `bson:"amount" json:"amount"`
+}
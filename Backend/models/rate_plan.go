@@ -0,0 +1,41 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// RatePlan menentukan harga per malam untuk satu kamar (RoomID) atau semua
+// kamar dengan tipe tertentu (RoomTypeID). Urutan harga di dalam satu plan:
+// season yang mencakup tanggal > DayRates (per hari) > BaseRate. Nilai 0
+// berarti tidak diatur dan dilanjutkan ke aturan berikutnya.
+type RatePlan struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Name       string              `bson:"name" json:"name"`
+	RoomID     *primitive.ObjectID `bson:"room_id,omitempty" json:"room_id,omitempty"`
+	RoomTypeID *primitive.ObjectID `bson:"room_type_id,omitempty" json:"room_type_id,omitempty"`
+	Priority   int                 `bson:"priority" json:"priority"` // lebih besar menang di level yang sama
+	Active     bool                `bson:"active" json:"active"`
+	BaseRate   float64             `bson:"base_rate" json:"base_rate"`
+	// key: nama hari bahasa Inggris huruf kecil (mis. "saturday"), atau
+	// "weekday" / "weekend" (lihat HOTEL_WEEKEND_DAYS). Nama hari menang.
+	DayRates  map[string]float64 `bson:"day_rates,omitempty" json:"day_rates,omitempty"`
+	Seasons   []SeasonRate       `bson:"seasons,omitempty" json:"seasons,omitempty"`
+	CreatedAt primitive.DateTime `bson:"created_at" json:"created_at"`
+	UpdatedAt primitive.DateTime `bson:"updated_at" json:"updated_at"`
+}
+
+// SeasonRate menimpa harga plan pada rentang tanggal StartDate..EndDate
+// (keduanya inklusif, format YYYY-MM-DD).
+type SeasonRate struct {
+	Name      string             `bson:"name" json:"name"`
+	StartDate string             `bson:"start_date" json:"start_date"`
+	EndDate   string             `bson:"end_date" json:"end_date"`
+	Priority  int                `bson:"priority" json:"priority"` // kalau season tumpang tindih
+	Rate      float64            `bson:"rate" json:"rate"`
+	DayRates  map[string]float64 `bson:"day_rates,omitempty" json:"day_rates,omitempty"`
+}
+
+// NightlyRate adalah harga satu malam hasil resolusi pricing service.
+type NightlyRate struct {
+	Date   string  `bson:"date" json:"date"`
+	Rate   float64 `bson:"rate" json:"rate"`
+	Source string  `bson:"source" json:"source"` // mis. "Standard / season: Lebaran", "room"
+}
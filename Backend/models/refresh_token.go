@@ -0,0 +1,16 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// RefreshToken menyimpan hash refresh token yang sudah diterbitkan.
+// Token mentah tidak pernah disimpan, hanya SHA-256 dari JTI-nya.
+type RefreshToken struct {
+	Id         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID     primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	SessionID  primitive.ObjectID  `bson:"session_id,omitempty" json:"session_id,omitempty"`
+	TokenHash  string              `bson:"token_hash" json:"-"`
+	ExpiresAt  primitive.DateTime  `bson:"expires_at" json:"expires_at"`
+	RevokedAt  *primitive.DateTime `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	ReplacedBy *primitive.ObjectID `bson:"replaced_by,omitempty" json:"replaced_by,omitempty"`
+	CreatedAt  primitive.DateTime  `bson:"created_at" json:"created_at"`
+}
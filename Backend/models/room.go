@@ -10,7 +10,7 @@ type Room struct {
 	Description    string             `bson:"description" json:"description"`
 	RoomNumber     string             `bson:"room_number" json:"room_number"`
 	PricePerNight  float64            `bson:"price_per_night" json:"price_per_night"`
-	Images         []string           `bson:"images" json:"images"`
+	Images         []RoomImage        `bson:"images" json:"images"`
 	Bed_type	   string             `bson:"bed_type" json:"bed_type"`
 	RoomTypeID     primitive.ObjectID   `bson:"room_type_id" json:"room_type_id"`
 	Capacity       int                `bson:"capacity" json:"capacity"`
@@ -6,4 +6,6 @@ type RoomType struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	Name        string             `bson:"name" json:"name"`
 	Description string             `bson:"description" json:"description"`
-}
\ No newline at end of file
+	// CancellationPolicy nil berarti pembatalan selalu refund penuh
+	CancellationPolicy *CancellationPolicy `bson:"cancellation_policy,omitempty" json:"cancellation_policy,omitempty"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// RoomHold menahan kamar untuk seorang tamu selama checkout. Malam yang
+// ditahan disimpan di room_night dengan booking_id = ID hold; saat hold
+// dikonfirmasi, booking dibuat dengan ID yang sama sehingga inventori tidak
+// perlu dipesan ulang.
+type RoomHold struct {
+	Id            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID        primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	RoomID        primitive.ObjectID  `bson:"room_id" json:"room_id"`
+	CheckIn       primitive.DateTime  `bson:"check_in" json:"check_in"`
+	CheckOut      primitive.DateTime  `bson:"check_out" json:"check_out"`
+	Nights        int                 `bson:"nights" json:"nights"`
+	Guests        int                 `bson:"guests" json:"guests"`
+	PricePerNight float64             `bson:"price_per_night" json:"price_per_night"`
+	Subtotal      float64             `bson:"subtotal" json:"subtotal"`
+	ServiceCharge float64             `bson:"service_charge" json:"service_charge"`
+	Tax           float64             `bson:"tax" json:"tax"`
+	TotalPrice    float64             `bson:"total_price" json:"total_price"` // subtotal + service charge + pajak
+	NightlyRates  []NightlyRate       `bson:"nightly_rates,omitempty" json:"nightly_rates,omitempty"`
+	QuoteID       *primitive.ObjectID `bson:"quote_id,omitempty" json:"quote_id,omitempty"`
+	Notes         string              `bson:"notes,omitempty" json:"notes,omitempty"`
+	Status        string              `bson:"status" json:"status"`
+	ExpiresAt     primitive.DateTime  `bson:"expires_at" json:"expires_at"`
+	ReleasedAt    *primitive.DateTime `bson:"released_at,omitempty" json:"released_at,omitempty"`
+	CreatedAt     primitive.DateTime  `bson:"created_at" json:"created_at"`
+	UpdatedAt     primitive.DateTime  `bson:"updated_at" json:"updated_at"`
+}
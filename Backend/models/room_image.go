@@ -0,0 +1,63 @@
+package models
+
+import (
+	"astro-backend/constants"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RoomImage adalah satu foto di galeri kamar. Urutan di Room.Images adalah
+// urutan tampil; tepat satu foto ditandai Primary sebagai cover.
+type RoomImage struct {
+	ID      primitive.ObjectID `bson:"_id" json:"id"`
+	URL     string             `bson:"url" json:"url"`
+	Alt     string             `bson:"alt" json:"alt"`
+	Caption string             `bson:"caption" json:"caption"`
+	Primary bool               `bson:"primary" json:"primary"`
+
+	// diisi worker setelah upload diproses (constants.ImageStatus*); kosong
+	// untuk foto lama yang sudah siap dipakai
+	Status   string         `bson:"status,omitempty" json:"status,omitempty"`
+	Width    int            `bson:"width,omitempty" json:"width,omitempty"`
+	Height   int            `bson:"height,omitempty" json:"height,omitempty"`
+	Variants *ImageVariants `bson:"variants,omitempty" json:"variants,omitempty"`
+}
+
+// ImageVariants adalah URL versi kecil sebuah foto (lihat
+// constants.ImageVariant*).
+type ImageVariants struct {
+	Thumbnail string `bson:"thumbnail" json:"thumbnail"`
+	Medium    string `bson:"medium" json:"medium"`
+	Large     string `bson:"large" json:"large"`
+}
+
+// Ready bernilai true kalau foto sudah bisa ditampilkan.
+func (img RoomImage) Ready() bool {
+	return img.Status == "" || img.Status == constants.ImageStatusReady
+}
+
+// UnmarshalBSONValue juga menerima format lama, yaitu URL berupa string
+// biasa. Foto dari format lama belum punya ID sampai dimigrasi
+// (lihat RoomRepository.MigrateLegacyImages).
+func (img *RoomImage) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	if t == bson.TypeString {
+		url, ok := bson.RawValue{Type: t, Value: data}.StringValueOK()
+		if !ok {
+			return errors.New("url foto tidak valid")
+		}
+		*img = RoomImage{URL: url}
+		return nil
+	}
+
+	// alias tanpa method ini supaya tidak rekursif
+	type roomImage RoomImage
+	var out roomImage
+	if err := bson.UnmarshalValue(t, data, &out); err != nil {
+		return err
+	}
+	*img = RoomImage(out)
+	return nil
+}
@@ -0,0 +1,14 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// RoomNight adalah satu malam inventori kamar yang sudah terpakai. Unique
+// index (room_id, date) menjamin satu kamar hanya bisa dipakai satu booking
+// per malam walaupun ada request bersamaan.
+type RoomNight struct {
+	Id        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	RoomID    primitive.ObjectID `bson:"room_id" json:"room_id"`
+	Date      primitive.DateTime `bson:"date" json:"date"` // tengah malam UTC
+	BookingID primitive.ObjectID `bson:"booking_id" json:"booking_id"`
+	CreatedAt primitive.DateTime `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,11 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// SecuritySetting adalah pengaturan keamanan global. Hanya ada satu dokumen
+// dengan _id "security".
+type SecuritySetting struct {
+	ID              string             `bson:"_id" json:"-"`
+	Require2FARoles []string           `bson:"require_2fa_roles" json:"require_2fa_roles"`
+	UpdatedAt       primitive.DateTime `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Session adalah satu sesi login (satu device). Access dan refresh token
+// membawa ID sesi (claim "sid"), jadi mencabut sesi langsung membuat semua
+// token sesi tersebut ditolak.
+type Session struct {
+	Id            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID        primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	Device        string              `bson:"device,omitempty" json:"device,omitempty"`
+	IPAddress     string              `bson:"ip_address,omitempty" json:"ip_address,omitempty"`
+	UserAgent     string              `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
+	CreatedAt     primitive.DateTime  `bson:"created_at" json:"created_at"`
+	LastSeenAt    primitive.DateTime  `bson:"last_seen_at" json:"last_seen_at"`
+	ExpiresAt     primitive.DateTime  `bson:"expires_at" json:"expires_at"`
+	RevokedAt     *primitive.DateTime `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	RevokedReason string              `bson:"revoked_reason,omitempty" json:"revoked_reason,omitempty"`
+}
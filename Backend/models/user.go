@@ -1,16 +1,38 @@
 package models
 
-import(
+import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type User struct {
-	Id       primitive.ObjectID 	`bson:"_id,omitempty" json:"id"`
-	Name     string             	`bson:"Name" json:"Name"`
-	Email    string             	`bson:"Email" json:"Email"`
-	NoTlp    string             	`bson:"NoTlp" json:"NoTlp"`
-	Password string          	    `bson:"Password" json:"Password"`
-	Role     string             	`bson:"Role" json:"Role"`
-	CreatedAt primitive.DateTime   `bson:"CreatedAt" json:"CreatedAt"`
-	UpdatedAt primitive.DateTime   `bson:"UpdatedAt" json:"UpdatedAt"`
-}
\ No newline at end of file
+	Id        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"Name" json:"Name"`
+	Email     string             `bson:"Email" json:"Email"`
+	NoTlp     string             `bson:"NoTlp" json:"NoTlp"`
+	Password  string             `bson:"Password" json:"Password,omitempty"`
+	Role      string             `bson:"Role" json:"Role"`
+	CreatedAt primitive.DateTime `bson:"CreatedAt" json:"CreatedAt"`
+	UpdatedAt primitive.DateTime `bson:"UpdatedAt" json:"UpdatedAt"`
+
+	// nil = akun lama / dibuat admin (dianggap sudah terverifikasi)
+	EmailVerified   *bool               `bson:"EmailVerified,omitempty" json:"EmailVerified,omitempty"`
+	EmailVerifiedAt *primitive.DateTime `bson:"EmailVerifiedAt,omitempty" json:"EmailVerifiedAt,omitempty"`
+
+	// token yang diterbitkan sebelum waktu ini dianggap tidak berlaku
+	PasswordChangedAt *primitive.DateTime `bson:"PasswordChangedAt,omitempty" json:"PasswordChangedAt,omitempty"`
+
+	// proteksi brute-force login
+	FailedLoginAttempts int                 `bson:"FailedLoginAttempts,omitempty" json:"-"`
+	LockedUntil         *primitive.DateTime `bson:"LockedUntil,omitempty" json:"LockedUntil,omitempty"`
+
+	// two-factor authentication (TOTP). Recovery code disimpan dalam bentuk hash.
+	TwoFactorEnabled  bool     `bson:"TwoFactorEnabled,omitempty" json:"TwoFactorEnabled"`
+	TOTPSecret        string   `bson:"TOTPSecret,omitempty" json:"-"`
+	TOTPPendingSecret string   `bson:"TOTPPendingSecret,omitempty" json:"-"`
+	TOTPLastStep      int64    `bson:"TOTPLastStep,omitempty" json:"-"`
+	RecoveryCodes     []string `bson:"RecoveryCodes,omitempty" json:"-"`
+
+	// akun yang login lewat SSO (OpenID Connect): pasangan issuer + subject dari IdP
+	OIDCIssuer  string `bson:"OIDCIssuer,omitempty" json:"OIDCIssuer,omitempty"`
+	OIDCSubject string `bson:"OIDCSubject,omitempty" json:"-"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// UserToken adalah token sekali pakai yang dikirim ke email user
+// (mis. verifikasi email). Yang disimpan hanya hash SHA-256 dari token.
+type UserToken struct {
+	Id        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	Purpose   string              `bson:"purpose" json:"purpose"`
+	TokenHash string              `bson:"token_hash" json:"-"`
+	ExpiresAt primitive.DateTime  `bson:"expires_at" json:"expires_at"`
+	UsedAt    *primitive.DateTime `bson:"used_at,omitempty" json:"used_at,omitempty"`
+	CreatedAt primitive.DateTime  `bson:"created_at" json:"created_at"`
+}
@@ -3,7 +3,11 @@ package admin
 import (
 	"astro-backend/config"
 	"astro-backend/models"
+	"astro-backend/repository/listing"
+	"astro-backend/utils"
 	"context"
+	"errors"
+	"regexp"
 	"time"
 
 
@@ -13,11 +17,22 @@ import (
 
 type FacilityRepository interface {
 	GetAll() ([]models.Facility, error)
+	List(filter FacilityListFilter, q utils.ListQuery) (utils.ListPage[models.Facility], error)
 	Create(facility models.Facility) (models.Facility, error)
-	Update(id string, facility models.Facility) error
+	Update(id string, patch FacilityPatch) error
 	Delete(id string) error
 }
 
+// FacilityPatch berisi field fasilitas yang diubah; field nil tidak
+// disentuh.
+type FacilityPatch struct {
+	Name *string `bson:"name,omitempty"`
+}
+
+type FacilityListFilter struct {
+	Name string
+}
+
 type facilityRepository struct{}
 
 func NewFacilityRepository() FacilityRepository {
@@ -42,6 +57,21 @@ func (*facilityRepository) GetAll() ([]models.Facility, error) {
 
 	return facilities, nil
 }
+// List mengembalikan satu halaman fasilitas; Name dicocokkan sebagian tanpa
+// membedakan huruf besar kecil.
+func (*facilityRepository) List(filter FacilityListFilter, q utils.ListQuery) (utils.ListPage[models.Facility], error) {
+	collection := config.GetMongoCollection("facilities")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	match := bson.M{}
+	if filter.Name != "" {
+		match["name"] = bson.M{"$regex": regexp.QuoteMeta(filter.Name), "$options": "i"}
+	}
+
+	return listing.Find[models.Facility](ctx, collection, match, q, nil)
+}
 func (*facilityRepository) Create(facility models.Facility) (models.Facility, error) {
 	collection := config.GetMongoCollection("facilities")
 
@@ -55,22 +85,31 @@ func (*facilityRepository) Create(facility models.Facility) (models.Facility, er
 
 	return facility, nil
 }
-func (*facilityRepository) Update(id string, facility models.Facility) error {
+func (*facilityRepository) Update(id string, patch FacilityPatch) error {
 	collection := config.GetMongoCollection("facilities")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("ID tidak valid")
+	}
+
+	// fasilitas cuma punya name; patch kosong tidak perlu ke database
+	if patch.Name == nil {
+		return nil
+	}
+
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": patch})
 	if err != nil {
 		return err
 	}
 
-	updateData := bson.M{
-		"name":  facility.Name,
+	if result.MatchedCount == 0 {
+		return errors.New("Fasilitas tidak ditemukan")
 	}
 
-	_, err = collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": updateData})
-	return err
+	return nil
 }
 func (*facilityRepository) Delete(id string) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -0,0 +1,278 @@
+package admin
+
+import (
+	"astro-backend/config"
+	"astro-backend/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrPromoLimitReached dikembalikan kalau kuota total atau per user habis.
+var ErrPromoLimitReached = errors.New("Kuota promo code sudah habis")
+
+type PromoCodeRepository interface {
+	GetAll() ([]models.PromoCode, error)
+	GetByID(id string) (models.PromoCode, error)
+	FindByCode(code string) (models.PromoCode, error)
+	Create(promo models.PromoCode) error
+	Update(id string, promo models.PromoCode) error
+	Delete(id string) error
+	// ReserveUsage menambah pemakaian total dan per user secara atomik;
+	// gagal dengan ErrPromoLimitReached kalau salah satu kuota habis.
+	ReserveUsage(promo models.PromoCode, userID primitive.ObjectID) error
+	// ReleaseUsage mengembalikan kuota yang sudah di-reserve.
+	ReleaseUsage(promoID, userID primitive.ObjectID) error
+	// UserUsage mengembalikan berapa kali user sudah memakai promo.
+	UserUsage(promoID, userID primitive.ObjectID) (int, error)
+	CreateRedemption(redemption models.PromoRedemption) error
+	EnsureIndexes() error
+}
+
+type promoCodeRepository struct{}
+
+func NewPromoCodeRepository() PromoCodeRepository {
+	return &promoCodeRepository{}
+}
+
+func (*promoCodeRepository) GetAll() ([]models.PromoCode, error) {
+	collection := config.GetMongoCollection("promo_code")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	promos := []models.PromoCode{}
+	if err := cursor.All(ctx, &promos); err != nil {
+		return nil, err
+	}
+
+	return promos, nil
+}
+
+func (*promoCodeRepository) GetByID(id string) (models.PromoCode, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.PromoCode{}, errors.New("ID tidak valid")
+	}
+
+	collection := config.GetMongoCollection("promo_code")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var promo models.PromoCode
+	if err := collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&promo); err != nil {
+		return promo, errors.New("Promo code tidak ditemukan")
+	}
+
+	return promo, nil
+}
+
+func (*promoCodeRepository) FindByCode(code string) (models.PromoCode, error) {
+	collection := config.GetMongoCollection("promo_code")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var promo models.PromoCode
+	if err := collection.FindOne(ctx, bson.M{"code": code}).Decode(&promo); err != nil {
+		return promo, errors.New("Promo code tidak ditemukan")
+	}
+
+	return promo, nil
+}
+
+func (*promoCodeRepository) Create(promo models.PromoCode) error {
+	collection := config.GetMongoCollection("promo_code")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, promo)
+	if mongo.IsDuplicateKeyError(err) {
+		return errors.New("Promo code sudah dipakai")
+	}
+	return err
+}
+
+// Update tidak menyentuh used_count supaya edit dari admin tidak menimpa
+// pemakaian yang terjadi bersamaan.
+func (*promoCodeRepository) Update(id string, promo models.PromoCode) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("ID tidak valid")
+	}
+
+	collection := config.GetMongoCollection("promo_code")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{
+		"code":           promo.Code,
+		"description":    promo.Description,
+		"discount_type":  promo.DiscountType,
+		"value":          promo.Value,
+		"max_discount":   promo.MaxDiscount,
+		"valid_from":     promo.ValidFrom,
+		"valid_until":    promo.ValidUntil,
+		"min_nights":     promo.MinNights,
+		"room_type_ids":  promo.RoomTypeIDs,
+		"usage_limit":    promo.UsageLimit,
+		"per_user_limit": promo.PerUserLimit,
+		"active":         promo.Active,
+		"updated_at":     promo.UpdatedAt,
+	}})
+	if mongo.IsDuplicateKeyError(err) {
+		return errors.New("Promo code sudah dipakai")
+	}
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("Promo code tidak ditemukan")
+	}
+
+	return nil
+}
+
+func (*promoCodeRepository) Delete(id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("ID tidak valid")
+	}
+
+	collection := config.GetMongoCollection("promo_code")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = collection.DeleteOne(ctx, bson.M{"_id": objID})
+	return err
+}
+
+// ReserveUsage memakai dua update bersyarat:
+//  1. counter promo_usage per (promo_id, user_id) di-upsert dengan filter
+//     count < per_user_limit. Kalau kuota habis, filter tidak cocok dan upsert
+//     menabrak unique index, jadi dua request bersamaan tidak bisa lolos.
+//  2. used_count promo dinaikkan dengan filter used_count < usage_limit.
+//     Kalau gagal, counter per user dikembalikan.
+func (*promoCodeRepository) ReserveUsage(promo models.PromoCode, userID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	usage := config.GetMongoCollection("promo_usage")
+	filter := bson.M{"promo_id": promo.ID, "user_id": userID}
+	if promo.PerUserLimit > 0 {
+		filter["count"] = bson.M{"$lt": promo.PerUserLimit}
+	}
+	_, err := usage.UpdateOne(ctx, filter,
+		bson.M{"$inc": bson.M{"count": 1}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrPromoLimitReached
+	}
+	if err != nil {
+		return err
+	}
+
+	promoFilter := bson.M{"_id": promo.ID, "active": true}
+	if promo.UsageLimit > 0 {
+		promoFilter["used_count"] = bson.M{"$lt": promo.UsageLimit}
+	}
+	result, err := config.GetMongoCollection("promo_code").UpdateOne(ctx, promoFilter,
+		bson.M{"$inc": bson.M{"used_count": 1}},
+	)
+	if err == nil && result.MatchedCount == 0 {
+		err = ErrPromoLimitReached
+	}
+	if err != nil {
+		_, _ = usage.UpdateOne(ctx, bson.M{"promo_id": promo.ID, "user_id": userID}, bson.M{"$inc": bson.M{"count": -1}})
+		return err
+	}
+
+	return nil
+}
+
+func (*promoCodeRepository) ReleaseUsage(promoID, userID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := config.GetMongoCollection("promo_code").UpdateOne(ctx,
+		bson.M{"_id": promoID, "used_count": bson.M{"$gt": 0}},
+		bson.M{"$inc": bson.M{"used_count": -1}},
+	); err != nil {
+		return err
+	}
+
+	_, err := config.GetMongoCollection("promo_usage").UpdateOne(ctx,
+		bson.M{"promo_id": promoID, "user_id": userID, "count": bson.M{"$gt": 0}},
+		bson.M{"$inc": bson.M{"count": -1}},
+	)
+	return err
+}
+
+func (*promoCodeRepository) UserUsage(promoID, userID primitive.ObjectID) (int, error) {
+	collection := config.GetMongoCollection("promo_usage")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var usage struct {
+		Count int `bson:"count"`
+	}
+	err := collection.FindOne(ctx, bson.M{"promo_id": promoID, "user_id": userID}).Decode(&usage)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	return usage.Count, err
+}
+
+func (*promoCodeRepository) CreateRedemption(redemption models.PromoRedemption) error {
+	collection := config.GetMongoCollection("promo_redemption")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, redemption)
+	return err
+}
+
+// EnsureIndexes membuat unique index kode promo dan counter per user.
+// Index promo_usage wajib ada supaya batas per user atomik.
+func (*promoCodeRepository) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := config.GetMongoCollection("promo_code").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "code", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		return err
+	}
+
+	if _, err := config.GetMongoCollection("promo_usage").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "promo_id", Value: 1}, {Key: "user_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		return err
+	}
+
+	_, err := config.GetMongoCollection("promo_redemption").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "booking_id", Value: 1}},
+	})
+	return err
+}
@@ -0,0 +1,142 @@
+package admin
+
+import (
+	"astro-backend/config"
+	"astro-backend/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RatePlanRepository interface {
+	GetAll() ([]models.RatePlan, error)
+	GetByID(id string) (models.RatePlan, error)
+	Create(plan models.RatePlan) error
+	Update(id string, plan models.RatePlan) error
+	Delete(id string) error
+	FindActiveForRoom(roomID, roomTypeID primitive.ObjectID) ([]models.RatePlan, error)
+}
+
+type ratePlanRepository struct{}
+
+func NewRatePlanRepository() RatePlanRepository {
+	return &ratePlanRepository{}
+}
+
+func (*ratePlanRepository) GetAll() ([]models.RatePlan, error) {
+	collection := config.GetMongoCollection("rate_plan")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "priority", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	plans := []models.RatePlan{}
+	if err := cursor.All(ctx, &plans); err != nil {
+		return nil, err
+	}
+
+	return plans, nil
+}
+
+func (*ratePlanRepository) GetByID(id string) (models.RatePlan, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.RatePlan{}, errors.New("ID tidak valid")
+	}
+
+	collection := config.GetMongoCollection("rate_plan")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var plan models.RatePlan
+	if err := collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&plan); err != nil {
+		return plan, errors.New("Rate plan tidak ditemukan")
+	}
+
+	return plan, nil
+}
+
+func (*ratePlanRepository) Create(plan models.RatePlan) error {
+	collection := config.GetMongoCollection("rate_plan")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, plan)
+	return err
+}
+
+func (*ratePlanRepository) Update(id string, plan models.RatePlan) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("ID tidak valid")
+	}
+
+	collection := config.GetMongoCollection("rate_plan")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// ReplaceOne supaya room_id / room_type_id / season yang dihapus ikut hilang
+	plan.ID = objID
+	result, err := collection.ReplaceOne(ctx, bson.M{"_id": objID}, plan)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("Rate plan tidak ditemukan")
+	}
+
+	return nil
+}
+
+func (*ratePlanRepository) Delete(id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("ID tidak valid")
+	}
+
+	collection := config.GetMongoCollection("rate_plan")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = collection.DeleteOne(ctx, bson.M{"_id": objID})
+	return err
+}
+
+// FindActiveForRoom mengambil plan aktif untuk kamar tersebut atau tipenya.
+// Urutan prioritas ditentukan pricing service.
+func (*ratePlanRepository) FindActiveForRoom(roomID, roomTypeID primitive.ObjectID) ([]models.RatePlan, error) {
+	collection := config.GetMongoCollection("rate_plan")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	or := bson.A{bson.M{"room_id": roomID}}
+	if !roomTypeID.IsZero() {
+		or = append(or, bson.M{"room_type_id": roomTypeID, "room_id": bson.M{"$exists": false}})
+	}
+
+	cursor, err := collection.Find(ctx, bson.M{"active": true, "$or": or})
+	if err != nil {
+		return nil, err
+	}
+
+	plans := []models.RatePlan{}
+	if err := cursor.All(ctx, &plans); err != nil {
+		return nil, err
+	}
+
+	return plans, nil
+}
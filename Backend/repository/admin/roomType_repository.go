@@ -3,7 +3,11 @@ package admin
 import(
 	"astro-backend/models"
 	"astro-backend/config"
+	"astro-backend/repository/listing"
+	"astro-backend/utils"
 	"context"
+	"errors"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -12,10 +16,25 @@ import(
 
 type RoomTypeRepository interface {
     GetAll() ([]models.RoomType, error)
+	List(filter RoomTypeListFilter, q utils.ListQuery) (utils.ListPage[models.RoomType], error)
+	GetByID(id string) (models.RoomType, error)
 	Create(roomType models.RoomType) (models.RoomType, error)
-	Update(id string, roomType models.RoomType) error
+	Update(id string, patch RoomTypePatch) error
 	Delete(id string) error
 }
+// RoomTypePatch berisi field room type yang diubah; field nil tidak
+// disentuh. RemoveCancellationPolicy menghapus kebijakan pembatalan.
+type RoomTypePatch struct {
+	Name                     *string                    `bson:"name,omitempty"`
+	Description              *string                    `bson:"description,omitempty"`
+	CancellationPolicy       *models.CancellationPolicy `bson:"cancellation_policy,omitempty"`
+	RemoveCancellationPolicy bool                       `bson:"-"`
+}
+
+type RoomTypeListFilter struct {
+	Name string
+}
+
 type roomTypeRepository struct{}
 
 func NewRoomTypeRepository() RoomTypeRepository {
@@ -40,6 +59,39 @@ func (*roomTypeRepository) GetAll() ([]models.RoomType, error) {
 
 	return roomType, nil
 }
+// List mengembalikan satu halaman room type; Name dicocokkan sebagian tanpa
+// membedakan huruf besar kecil.
+func (*roomTypeRepository) List(filter RoomTypeListFilter, q utils.ListQuery) (utils.ListPage[models.RoomType], error) {
+	collection := config.GetMongoCollection("roomType")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	match := bson.M{}
+	if filter.Name != "" {
+		match["name"] = bson.M{"$regex": regexp.QuoteMeta(filter.Name), "$options": "i"}
+	}
+
+	return listing.Find[models.RoomType](ctx, collection, match, q, nil)
+}
+func (*roomTypeRepository) GetByID(id string) (models.RoomType, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.RoomType{}, errors.New("ID tidak valid")
+	}
+
+	collection := config.GetMongoCollection("roomType")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var roomType models.RoomType
+	if err := collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&roomType); err != nil {
+		return roomType, errors.New("Room type tidak ditemukan")
+	}
+
+	return roomType, nil
+}
 func (*roomTypeRepository) Create(roomType models.RoomType) (models.RoomType, error) {
     collection := config.GetMongoCollection("roomType")
 
@@ -53,25 +105,37 @@ func (*roomTypeRepository) Create(roomType models.RoomType) (models.RoomType, er
 
 	return roomType, nil
 }
-func (*roomTypeRepository) Update(id string, roomType models.RoomType) error {
+func (*roomTypeRepository) Update(id string, patch RoomTypePatch) error {
 	collection := config.GetMongoCollection("roomType")
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return err
+		return errors.New("ID tidak valid")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	updateData := bson.M{
-		"name":  roomType.Name,
-		"description" : roomType.Description,
+	update := bson.M{}
+	if patch.Name != nil || patch.Description != nil || patch.CancellationPolicy != nil {
+		update["$set"] = patch
+	}
+	if patch.RemoveCancellationPolicy {
+		update["$unset"] = bson.M{"cancellation_policy": ""}
+	}
+	if len(update) == 0 {
+		return nil
 	}
 
-	_, err = collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": updateData})
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return err
+	}
 
-	return err
+	if result.MatchedCount == 0 {
+		return errors.New("Room type tidak ditemukan")
+	}
 
+	return nil
 }
 func (*roomTypeRepository) Delete(id string) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -2,7 +2,10 @@ package admin
 
 import (
 	"astro-backend/config"
+	"astro-backend/constants"
 	"astro-backend/models"
+	"astro-backend/repository/listing"
+	"astro-backend/utils"
 	"context"
 	"errors"
 	"time"
@@ -10,15 +13,63 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	
 )
 
 type RoomRepository interface {
 	Create(room models.Room) error
-	Update(id string, room models.Room) error
+	Update(id string, patch RoomPatch) error
 	Delete(id string) error
-	GetAll() ([]models.Room, error)
 	GetByID(id string) (models.Room, error)
+	SearchAvailable(filter RoomSearchFilter) ([]models.Room, error)
+	List(filter RoomListFilter, q utils.ListQuery) (utils.ListPage[models.Room], error)
+	UpdateImages(id primitive.ObjectID, images []models.RoomImage, version primitive.DateTime) (models.Room, error)
+	SetImageResult(roomID, imageID primitive.ObjectID, result models.RoomImage) (bool, error)
+	MigrateLegacyImages() error
+	FailStaleProcessingImages() (int64, error)
+}
+
+// RoomSearchFilter adalah kriteria pencarian kamar kosong. CheckIn dan
+// CheckOut wajib (tengah malam UTC), field lain opsional.
+type RoomSearchFilter struct {
+	CheckIn     time.Time
+	CheckOut    time.Time
+	Guests      int
+	RoomTypeID  *primitive.ObjectID
+	FacilityIDs []primitive.ObjectID // kamar harus punya semua fasilitas ini
+	MinPrice    *float64
+	MaxPrice    *float64
+}
+
+// ErrRoomChanged dikembalikan UpdateImages kalau kamar sudah diubah proses
+// lain sejak dibaca.
+var ErrRoomChanged = errors.New("Data kamar baru saja berubah, silakan coba lagi")
+
+// RoomPatch berisi field kamar yang diubah. Field nil tidak ikut di-$set,
+// jadi data lama tetap utuh. Galeri foto diubah lewat UpdateImages.
+type RoomPatch struct {
+	Name          *string               `bson:"name,omitempty"`
+	Description   *string               `bson:"description,omitempty"`
+	RoomNumber    *string               `bson:"room_number,omitempty"`
+	PricePerNight *float64              `bson:"price_per_night,omitempty"`
+	RoomTypeID    *primitive.ObjectID   `bson:"room_type_id,omitempty"`
+	Capacity      *int                  `bson:"capacity,omitempty"`
+	BedType       *string               `bson:"bed_type,omitempty"`
+	Category      *string               `bson:"category,omitempty"`
+	FacilitiesID  *[]primitive.ObjectID `bson:"facilities_id,omitempty"`
+	Availability  *bool                 `bson:"availability,omitempty"`
+	UpdatedAt     primitive.DateTime    `bson:"updated_at"`
+}
+
+// RoomListFilter adalah filter daftar kamar. Field kosong / nil tidak
+// dipakai.
+type RoomListFilter struct {
+	Category     string
+	BedType      string
+	RoomTypeID   *primitive.ObjectID
+	FacilityIDs  []primitive.ObjectID // kamar harus punya semua fasilitas ini
+	Availability *bool
 }
 
 type roomRepository struct{}
@@ -41,21 +92,30 @@ func (*roomRepository) Create(room models.Room) error {
 	return err
 }
 
-func (r *roomRepository) Update(id string, room models.Room) error {
+func (r *roomRepository) Update(id string, patch RoomPatch) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return err
+		return errors.New("ID tidak valid")
 	}
 
 	collection := config.GetMongoCollection("room")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err = collection.UpdateOne(ctx,
+	patch.UpdatedAt = primitive.NewDateTimeFromTime(time.Now())
+	result, err := collection.UpdateOne(ctx,
 		bson.M{"_id": objID},
-		bson.M{"$set": room},
+		bson.M{"$set": patch},
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("data tidak ditemukan")
+	}
+
+	return nil
 }
 
 func (*roomRepository) Delete(id string) error {
@@ -72,12 +132,41 @@ func (*roomRepository) Delete(id string) error {
 	return err
 }
 
-func (*roomRepository) GetAll() ([]models.Room, error) {
+func (*roomRepository) GetByID(id string) (models.Room, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.Room{}, errors.New("ID tidak valid")
+	}
+
 	collection := config.GetMongoCollection("room")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "_id", Value: objectID}}}},
+	}
+	pipeline = append(pipeline, roomLookupStages()...)
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return models.Room{}, err
+	}
+
+	var rooms []models.Room
+	if err := cursor.All(ctx, &rooms); err != nil {
+		return models.Room{}, err
+	}
+
+	if len(rooms) == 0 {
+		return models.Room{}, errors.New("data tidak ditemukan")
+	}
+
+	return rooms[0], nil
+}
+
+// roomLookupStages menempelkan data room type dan fasilitas ke kamar.
+func roomLookupStages() mongo.Pipeline {
+	return mongo.Pipeline{
 		{{Key: "$lookup", Value: bson.D{
 			{Key: "from", Value: "roomType"},
 			{Key: "localField", Value: "room_type_id"},
@@ -91,59 +180,229 @@ func (*roomRepository) GetAll() ([]models.Room, error) {
 			{Key: "as", Value: "facilities"},
 		}}},
 	}
+}
+
+// SearchAvailable mencari kamar yang memenuhi filter dan tidak punya malam
+// terpakai (room_night) dalam rentang tanggal.
+func (*roomRepository) SearchAvailable(filter RoomSearchFilter) ([]models.Room, error) {
+	collection := config.GetMongoCollection("room")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	match := bson.M{"availability": true}
+	if filter.Guests > 0 {
+		match["capacity"] = bson.M{"$gte": filter.Guests}
+	}
+	if filter.RoomTypeID != nil {
+		match["room_type_id"] = *filter.RoomTypeID
+	}
+	if len(filter.FacilityIDs) > 0 {
+		match["facilities_id"] = bson.M{"$all": filter.FacilityIDs}
+	}
+	price := bson.M{}
+	if filter.MinPrice != nil {
+		price["$gte"] = *filter.MinPrice
+	}
+	if filter.MaxPrice != nil {
+		price["$lte"] = *filter.MaxPrice
+	}
+	if len(price) > 0 {
+		match["price_per_night"] = price
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		// malam yang sudah terpakai booking aktif maupun hold checkout
+		{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: "room_night"},
+			{Key: "let", Value: bson.D{{Key: "roomId", Value: "$_id"}}},
+			{Key: "pipeline", Value: mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{
+					"date": bson.M{
+						"$gte": primitive.NewDateTimeFromTime(filter.CheckIn),
+						"$lt":  primitive.NewDateTimeFromTime(filter.CheckOut),
+					},
+					"$expr": bson.M{"$eq": bson.A{"$room_id", "$$roomId"}},
+				}}},
+				{{Key: "$limit", Value: 1}},
+			}},
+			{Key: "as", Value: "conflicts"},
+		}}},
+		{{Key: "$match", Value: bson.M{"conflicts": bson.M{"$size": 0}}}},
+		{{Key: "$project", Value: bson.M{"conflicts": 0}}},
+		{{Key: "$sort", Value: bson.D{{Key: "price_per_night", Value: 1}}}},
+	}
+	pipeline = append(pipeline, roomLookupStages()...)
 
 	cursor, err := collection.Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
 	}
 
-	var rooms []models.Room
-	if err = cursor.All(ctx, &rooms); err != nil {
+	rooms := []models.Room{}
+	if err := cursor.All(ctx, &rooms); err != nil {
 		return nil, err
 	}
 
 	return rooms, nil
 }
 
-func (*roomRepository) GetByID(id string) (models.Room, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		return models.Room{}, errors.New("ID tidak valid")
+// List mengembalikan satu halaman kamar sesuai filter, lengkap dengan room
+// type dan fasilitas.
+func (*roomRepository) List(filter RoomListFilter, q utils.ListQuery) (utils.ListPage[models.Room], error) {
+	collection := config.GetMongoCollection("room")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	match := bson.M{}
+	if filter.Category != "" {
+		match["category"] = filter.Category
+	}
+	if filter.BedType != "" {
+		match["bed_type"] = filter.BedType
+	}
+	if filter.RoomTypeID != nil {
+		match["room_type_id"] = *filter.RoomTypeID
+	}
+	if len(filter.FacilityIDs) > 0 {
+		match["facilities_id"] = bson.M{"$all": filter.FacilityIDs}
+	}
+	if filter.Availability != nil {
+		match["availability"] = *filter.Availability
 	}
 
+	return listing.Find[models.Room](ctx, collection, match, q, roomLookupStages())
+}
+
+// UpdateImages menyimpan galeri foto kamar. version adalah updated_at saat
+// kamar dibaca; kalau sudah berbeda, ErrRoomChanged dikembalikan supaya
+// perubahan galeri yang bersamaan tidak saling menimpa.
+func (*roomRepository) UpdateImages(id primitive.ObjectID, images []models.RoomImage, version primitive.DateTime) (models.Room, error) {
 	collection := config.GetMongoCollection("room")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	pipeline := mongo.Pipeline{
-		{{Key: "$match", Value: bson.D{{Key: "_id", Value: objectID}}}},
-		{{Key: "$lookup", Value: bson.D{
-			{Key: "from", Value: "roomType"},
-			{Key: "localField", Value: "room_type_id"},
-			{Key: "foreignField", Value: "_id"},
-			{Key: "as", Value: "room_type"},
-		}}},
-		{{Key: "$lookup", Value: bson.D{
-			{Key: "from", Value: "facilities"},
-			{Key: "localField", Value: "facilities_id"},
-			{Key: "foreignField", Value: "_id"},
-			{Key: "as", Value: "facilities"},
-		}}},
+	filter := bson.M{"_id": id, "updated_at": version}
+	if version == 0 {
+		// kamar lama yang belum pernah punya updated_at
+		filter["updated_at"] = bson.M{"$exists": false}
 	}
 
-	cursor, err := collection.Aggregate(ctx, pipeline)
+	var room models.Room
+	err := collection.FindOneAndUpdate(ctx, filter,
+		bson.M{"$set": bson.M{
+			"images":     images,
+			"updated_at": primitive.NewDateTimeFromTime(time.Now()),
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&room)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.Room{}, ErrRoomChanged
+	}
 	if err != nil {
 		return models.Room{}, err
 	}
 
+	return room, nil
+}
+
+// MigrateLegacyImages mengubah foto format lama (URL string) menjadi
+// models.RoomImage dengan ID sendiri. Foto pertama jadi cover kalau belum
+// ada. Aman dijalankan berulang kali.
+func (*roomRepository) MigrateLegacyImages() error {
+	collection := config.GetMongoCollection("room")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cursor, err := collection.Find(ctx,
+		bson.M{"images": bson.M{"$type": "string"}},
+		options.Find().SetProjection(bson.M{"images": 1}),
+	)
+	if err != nil {
+		return err
+	}
+
 	var rooms []models.Room
 	if err := cursor.All(ctx, &rooms); err != nil {
-		return models.Room{}, err
+		return err
 	}
 
-	if len(rooms) == 0 {
-		return models.Room{}, errors.New("data tidak ditemukan")
+	for _, room := range rooms {
+		hasPrimary := false
+		for i := range room.Images {
+			if room.Images[i].ID.IsZero() {
+				room.Images[i].ID = primitive.NewObjectID()
+			}
+			hasPrimary = hasPrimary || room.Images[i].Primary
+		}
+		if !hasPrimary && len(room.Images) > 0 {
+			room.Images[0].Primary = true
+		}
+
+		if _, err := collection.UpdateOne(ctx,
+			bson.M{"_id": room.Id},
+			bson.M{"$set": bson.M{"images": room.Images}},
+		); err != nil {
+			return err
+		}
 	}
 
-	return rooms[0], nil
+	return nil
+}
+
+// SetImageResult menyimpan hasil pemrosesan satu foto (URL, ukuran, varian
+// dan status). updated_at ikut diperbarui supaya UpdateImages yang membaca
+// galeri sebelum hasil ini masuk tidak menimpanya. Bernilai false kalau
+// foto sudah dihapus dari galeri.
+func (*roomRepository) SetImageResult(roomID, imageID primitive.ObjectID, result models.RoomImage) (bool, error) {
+	collection := config.GetMongoCollection("room")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	set := bson.M{
+		"images.$.status": result.Status,
+		"updated_at":      primitive.NewDateTimeFromTime(time.Now()),
+	}
+	if result.URL != "" {
+		set["images.$.url"] = result.URL
+		set["images.$.width"] = result.Width
+		set["images.$.height"] = result.Height
+		set["images.$.variants"] = result.Variants
+	}
+
+	res, err := collection.UpdateOne(ctx,
+		bson.M{"_id": roomID, "images._id": imageID},
+		bson.M{"$set": set},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return res.MatchedCount == 1, nil
+}
+
+// FailStaleProcessingImages menandai foto yang masih PROCESSING sebagai
+// FAILED. Dipanggil saat startup: file staging job yang belum selesai
+// sebelum server berhenti sudah hilang, jadi foto itu tidak akan pernah
+// diproses. Mengembalikan jumlah kamar yang diubah.
+func (*roomRepository) FailStaleProcessingImages() (int64, error) {
+	collection := config.GetMongoCollection("room")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	res, err := collection.UpdateMany(ctx,
+		bson.M{"images.status": constants.ImageStatusProcessing},
+		bson.M{"$set": bson.M{
+			"images.$[img].status": constants.ImageStatusFailed,
+			"updated_at":           primitive.NewDateTimeFromTime(time.Now()),
+		}},
+		options.Update().SetArrayFilters(options.ArrayFilters{
+			Filters: []interface{}{bson.M{"img.status": constants.ImageStatusProcessing}},
+		}),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.ModifiedCount, nil
 }
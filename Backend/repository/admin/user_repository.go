@@ -3,45 +3,87 @@ package admin
 import (
 	"astro-backend/config"
 	"astro-backend/models"
+	"astro-backend/repository/listing"
+	"astro-backend/utils"
 	"context"
 	"errors"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"golang.org/x/crypto/bcrypt"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type UserRepository interface {
 	Create(user models.User) error
 	Delete(id string) error
-	Update(id string, user models.User) error
+	Update(id string, patch UserPatch) error
 	FindByEmail(Email string) (models.User, error)
-	GetAllUsers() ([]models.User, error)
+	FindByID(id string) (models.User, error)
+	List(filter UserListFilter, q utils.ListQuery) (utils.ListPage[models.User], error)
+	MarkEmailVerified(id primitive.ObjectID) error
+	FindByOIDCSubject(issuer, subject string) (models.User, error)
+	LinkOIDC(id primitive.ObjectID, issuer, subject string) error
+	UpdatePassword(id primitive.ObjectID, hashedPassword string) error
+	RehashPassword(id primitive.ObjectID, oldHash, newHash string) error
+	IncrementFailedLogin(id primitive.ObjectID) (int, error)
+	LockUntil(id primitive.ObjectID, until time.Time) error
+	ResetFailedLogin(id primitive.ObjectID) error
+	SetPendingTOTPSecret(id primitive.ObjectID, secret string) error
+	EnableTwoFactor(id primitive.ObjectID, secret string, recoveryHashes []string) error
+	DisableTwoFactor(id primitive.ObjectID) error
+	UseRecoveryCode(id primitive.ObjectID, hash string) (bool, error)
+	UseTOTPStep(id primitive.ObjectID, step int64) (bool, error)
+	EnsureIndexes() error
 }
 
+// UserListFilter adalah filter daftar user. EmailPrefix dicocokkan tanpa
+// membedakan huruf besar kecil.
+type UserListFilter struct {
+	Role        string
+	EmailPrefix string
+}
+
+// UserPatch berisi field user yang diubah; field nil tidak disentuh.
+// Password harus sudah di-hash.
+type UserPatch struct {
+	Name              *string             `bson:"Name,omitempty"`
+	Email             *string             `bson:"Email,omitempty"`
+	NoTlp             *string             `bson:"NoTlp,omitempty"`
+	Role              *string             `bson:"Role,omitempty"`
+	Password          *string             `bson:"Password,omitempty"`
+	PasswordChangedAt *primitive.DateTime `bson:"PasswordChangedAt,omitempty"`
+	UpdatedAt         primitive.DateTime  `bson:"UpdatedAt"`
+}
+
+// ErrEmailExists dikembalikan Create kalau email sudah dipakai user lain.
+var ErrEmailExists = errors.New("Email sudah terdaftar")
+
 type userRepository struct{}
 
 func NewUserRepository() UserRepository {
 	return &userRepository{}
 }
-func (*userRepository) GetAllUsers() ([]models.User, error) {
+// List mengembalikan satu halaman user. Hash password tidak ikut diambil.
+func (*userRepository) List(filter UserListFilter, q utils.ListQuery) (utils.ListPage[models.User], error) {
 	collection := config.GetMongoCollection("user")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	var allUser []models.User
-
-	cursor, err := collection.Find(ctx, bson.M{})
-	if err != nil {
-		return nil, err
+	match := bson.M{}
+	if filter.Role != "" {
+		match["Role"] = filter.Role
 	}
-	if err = cursor.All(ctx, &allUser); err != nil {
-		return nil, err
+	if filter.EmailPrefix != "" {
+		match["Email"] = bson.M{"$regex": "^" + regexp.QuoteMeta(filter.EmailPrefix), "$options": "i"}
 	}
 
-	return allUser, nil
+	return listing.Find[models.User](ctx, collection, match, q, mongo.Pipeline{
+		{{Key: "$project", Value: bson.M{"Password": 0}}},
+	})
 }
 func (*userRepository) Create(user models.User) error {
 	collection := config.GetMongoCollection("user")
@@ -50,6 +92,9 @@ func (*userRepository) Create(user models.User) error {
 	defer cancel()
 
 	_, err := collection.InsertOne(ctx, user)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrEmailExists
+	}
 	return err
 }
 func (r *userRepository) Delete(id string) error {
@@ -74,7 +119,7 @@ func (r *userRepository) Delete(id string) error {
 
 	return nil
 }
-func (r *userRepository) Update(id string, user models.User) error {
+func (r *userRepository) Update(id string, patch UserPatch) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return errors.New("ID tidak valid")
@@ -84,23 +129,18 @@ func (r *userRepository) Update(id string, user models.User) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	updateData := bson.M{
-		"Name":  user.Name,
-		"Email": user.Email,
-		"NoTlp": user.NoTlp,
-		"Role":  user.Role,
+	now := primitive.NewDateTimeFromTime(time.Now())
+	patch.UpdatedAt = now
+	// Authenticate dan Refresh menolak token yang terbit sebelum
+	// PasswordChangedAt; pencabutan sesi dilakukan di service
+	if patch.Password != nil {
+		patch.PasswordChangedAt = &now
 	}
 
-	// Jika password ingin diubah, hash ulang
-	if user.Password != "" {
-		hashed, err := bcrypt.GenerateFromPassword([]byte(user.Password), 14)
-		if err != nil {
-			return errors.New("gagal hash password")
-		}
-		updateData["Password"] = string(hashed)
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": patch})
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrEmailExists
 	}
-
-	result, err := collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": updateData})
 	if err != nil {
 		return err
 	}
@@ -117,8 +157,10 @@ func (r *userRepository) FindByEmail(Email string) (models.User, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	// collation sama dengan unique index Email, jadi pencarian tidak peka
+	// huruf besar/kecil (akun lama mungkin tersimpan dengan huruf besar)
 	var user models.User
-	err := collection.FindOne(ctx, bson.M{"Email": Email}).Decode(&user)
+	err := collection.FindOne(ctx, bson.M{"Email": Email}, options.FindOne().SetCollation(emailCollation)).Decode(&user)
 
 	if err != nil {
 		return user, errors.New("Email tidak ditemukan")
@@ -126,3 +168,280 @@ func (r *userRepository) FindByEmail(Email string) (models.User, error) {
 
 	return user, nil
 }
+func (r *userRepository) FindByID(id string) (models.User, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.User{}, errors.New("ID tidak valid")
+	}
+
+	collection := config.GetMongoCollection("user")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var user models.User
+	if err := collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&user); err != nil {
+		return user, errors.New("User dengan ID tersebut tidak ditemukan")
+	}
+
+	return user, nil
+}
+func (r *userRepository) MarkEmailVerified(id primitive.ObjectID) error {
+	collection := config.GetMongoCollection("user")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"EmailVerified":   true,
+		"EmailVerifiedAt": now,
+		"UpdatedAt":       now,
+	}})
+	return err
+}
+
+func (r *userRepository) FindByOIDCSubject(issuer, subject string) (models.User, error) {
+	collection := config.GetMongoCollection("user")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var user models.User
+	err := collection.FindOne(ctx, bson.M{"OIDCIssuer": issuer, "OIDCSubject": subject}).Decode(&user)
+	if err != nil {
+		return user, errors.New("User SSO tidak ditemukan")
+	}
+
+	return user, nil
+}
+
+// LinkOIDC menautkan user ke akun IdP. Role tidak diubah di sini. Email
+// dianggap terverifikasi karena sudah diverifikasi IdP.
+func (r *userRepository) LinkOIDC(id primitive.ObjectID, issuer, subject string) error {
+	collection := config.GetMongoCollection("user")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"OIDCIssuer":    issuer,
+		"OIDCSubject":   subject,
+		"EmailVerified": true,
+		"UpdatedAt":     now,
+	}})
+	return err
+}
+
+// UpdatePassword menyimpan hash password baru dan mencatat PasswordChangedAt
+// supaya token lama bisa ditolak.
+func (r *userRepository) UpdatePassword(id primitive.ObjectID, hashedPassword string) error {
+	collection := config.GetMongoCollection("user")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"Password":          hashedPassword,
+		"PasswordChangedAt": now,
+		"UpdatedAt":         now,
+	}})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("User dengan ID tersebut tidak ditemukan")
+	}
+
+	return nil
+}
+
+// RehashPassword mengganti hash lama dengan hash baru untuk password yang
+// sama (upgrade cost). PasswordChangedAt tidak diubah supaya sesi lain tetap
+// berlaku, dan filter hash lama mencegah menimpa password yang baru diganti.
+func (r *userRepository) RehashPassword(id primitive.ObjectID, oldHash, newHash string) error {
+	collection := config.GetMongoCollection("user")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "Password": oldHash},
+		bson.M{"$set": bson.M{"Password": newHash}},
+	)
+	return err
+}
+
+// IncrementFailedLogin menambah FailedLoginAttempts secara atomik dan
+// mengembalikan nilai terbarunya.
+func (r *userRepository) IncrementFailedLogin(id primitive.ObjectID) (int, error) {
+	collection := config.GetMongoCollection("user")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var user models.User
+	err := collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id},
+		bson.M{"$inc": bson.M{"FailedLoginAttempts": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&user)
+	if err != nil {
+		return 0, err
+	}
+
+	return user.FailedLoginAttempts, nil
+}
+
+func (r *userRepository) LockUntil(id primitive.ObjectID, until time.Time) error {
+	collection := config.GetMongoCollection("user")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"LockedUntil": primitive.NewDateTimeFromTime(until),
+	}})
+	return err
+}
+
+func (r *userRepository) ResetFailedLogin(id primitive.ObjectID) error {
+	collection := config.GetMongoCollection("user")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set":   bson.M{"FailedLoginAttempts": 0},
+		"$unset": bson.M{"LockedUntil": ""},
+	})
+	return err
+}
+
+func (r *userRepository) SetPendingTOTPSecret(id primitive.ObjectID, secret string) error {
+	collection := config.GetMongoCollection("user")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"TOTPPendingSecret": secret}})
+	return err
+}
+
+func (r *userRepository) EnableTwoFactor(id primitive.ObjectID, secret string, recoveryHashes []string) error {
+	collection := config.GetMongoCollection("user")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{
+			"TwoFactorEnabled": true,
+			"TOTPSecret":       secret,
+			"RecoveryCodes":    recoveryHashes,
+			"UpdatedAt":        primitive.NewDateTimeFromTime(time.Now()),
+		},
+		// TOTPLastStep sudah diisi oleh kode yang mengaktifkan 2FA
+		"$unset": bson.M{"TOTPPendingSecret": ""},
+	})
+	return err
+}
+
+func (r *userRepository) DisableTwoFactor(id primitive.ObjectID) error {
+	collection := config.GetMongoCollection("user")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set":   bson.M{"TwoFactorEnabled": false, "UpdatedAt": primitive.NewDateTimeFromTime(time.Now())},
+		"$unset": bson.M{"TOTPSecret": "", "TOTPPendingSecret": "", "TOTPLastStep": "", "RecoveryCodes": ""},
+	})
+	return err
+}
+
+// UseRecoveryCode menghapus hash recovery code dari user. Bernilai true kalau
+// kode tersebut ada (dan sekarang sudah terpakai).
+func (r *userRepository) UseRecoveryCode(id primitive.ObjectID, hash string) (bool, error) {
+	collection := config.GetMongoCollection("user")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "RecoveryCodes": hash},
+		bson.M{"$pull": bson.M{"RecoveryCodes": hash}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// UseTOTPStep menyimpan time step TOTP terakhir yang dipakai. Bernilai false
+// kalau step yang sama (atau lebih lama) sudah pernah dipakai (replay).
+func (r *userRepository) UseTOTPStep(id primitive.ObjectID, step int64) (bool, error) {
+	collection := config.GetMongoCollection("user")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "$or": bson.A{
+			bson.M{"TOTPLastStep": bson.M{"$exists": false}},
+			bson.M{"TOTPLastStep": bson.M{"$lt": step}},
+		}},
+		bson.M{"$set": bson.M{"TOTPLastStep": step}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+// emailCollation membandingkan email tanpa memperhatikan huruf besar/kecil
+// (strength 2), jadi User@x.com dan user@x.com dianggap sama.
+var emailCollation = &options.Collation{Locale: "en", Strength: 2}
+
+// EnsureIndexes membuat unique index untuk Email supaya dua pendaftaran
+// bersamaan dengan email yang sama tidak bisa lolos. Index lama yang masih
+// peka huruf besar/kecil diganti.
+func (r *userRepository) EnsureIndexes() error {
+	collection := config.GetMongoCollection("user")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "Email", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("Email_ci").SetCollation(emailCollation),
+		},
+		{
+			// satu akun IdP hanya boleh tertaut ke satu user
+			Keys: bson.D{{Key: "OIDCIssuer", Value: 1}, {Key: "OIDCSubject", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"OIDCSubject": bson.M{"$exists": true}}),
+		},
+	})
+	if err != nil {
+		// Email_1 tetap dipakai; mis. ada email yang hanya beda huruf besar/kecil
+		return err
+	}
+
+	// index lama baru dibuang setelah Email_ci pasti ada, supaya collection
+	// tidak pernah tanpa unique index email
+	if _, err := collection.Indexes().DropOne(ctx, "Email_1"); err != nil {
+		var cmdErr mongo.CommandError
+		// IndexNotFound (27) berarti tidak ada yang perlu diganti
+		if !errors.As(err, &cmdErr) || cmdErr.Code != 27 {
+			return err
+		}
+	}
+	return nil
+}
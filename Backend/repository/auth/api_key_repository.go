@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"astro-backend/config"
+	"astro-backend/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type APIKeyRepository interface {
+	Create(key models.APIKey) error
+	GetAll() ([]models.APIKey, error)
+	FindByID(id string) (models.APIKey, error)
+	FindByHash(hash string) (models.APIKey, error)
+	UpdateKey(id primitive.ObjectID, prefix, hash string) error
+	Revoke(id primitive.ObjectID) error
+	TouchLastUsed(id primitive.ObjectID) error
+	EnsureIndexes() error
+}
+
+type apiKeyRepository struct{}
+
+func NewAPIKeyRepository() APIKeyRepository {
+	return &apiKeyRepository{}
+}
+
+func (*apiKeyRepository) Create(key models.APIKey) error {
+	collection := config.GetMongoCollection("api_key")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, key)
+	return err
+}
+
+func (*apiKeyRepository) GetAll() ([]models.APIKey, error) {
+	collection := config.GetMongoCollection("api_key")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []models.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (*apiKeyRepository) FindByID(id string) (models.APIKey, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.APIKey{}, errors.New("ID tidak valid")
+	}
+
+	collection := config.GetMongoCollection("api_key")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var key models.APIKey
+	if err := collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&key); err != nil {
+		return key, errors.New("API key tidak ditemukan")
+	}
+
+	return key, nil
+}
+
+func (*apiKeyRepository) FindByHash(hash string) (models.APIKey, error) {
+	collection := config.GetMongoCollection("api_key")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var key models.APIKey
+	if err := collection.FindOne(ctx, bson.M{"key_hash": hash}).Decode(&key); err != nil {
+		return key, errors.New("API key tidak ditemukan")
+	}
+
+	return key, nil
+}
+
+func (*apiKeyRepository) UpdateKey(id primitive.ObjectID, prefix, hash string) error {
+	collection := config.GetMongoCollection("api_key")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{
+			"prefix":     prefix,
+			"key_hash":   hash,
+			"rotated_at": primitive.NewDateTimeFromTime(time.Now()),
+		}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("API key tidak ditemukan atau sudah dicabut")
+	}
+
+	return nil
+}
+
+func (*apiKeyRepository) Revoke(id primitive.ObjectID) error {
+	collection := config.GetMongoCollection("api_key")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": primitive.NewDateTimeFromTime(time.Now())}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("API key tidak ditemukan atau sudah dicabut")
+	}
+
+	return nil
+}
+
+func (*apiKeyRepository) TouchLastUsed(id primitive.ObjectID) error {
+	collection := config.GetMongoCollection("api_key")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"last_used_at": primitive.NewDateTimeFromTime(time.Now()),
+	}})
+	return err
+}
+
+func (*apiKeyRepository) EnsureIndexes() error {
+	collection := config.GetMongoCollection("api_key")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "key_hash", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
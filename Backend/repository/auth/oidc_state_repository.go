@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"astro-backend/config"
+	"astro-backend/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type OIDCStateRepository interface {
+	Create(state models.OIDCLoginState) error
+	Consume(stateHash string) (models.OIDCLoginState, error)
+	EnsureIndexes() error
+}
+
+type oidcStateRepository struct{}
+
+func NewOIDCStateRepository() OIDCStateRepository {
+	return &oidcStateRepository{}
+}
+
+func (*oidcStateRepository) Create(state models.OIDCLoginState) error {
+	collection := config.GetMongoCollection("oidc_state")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, state)
+	return err
+}
+
+// Consume mengambil dan menghapus state dalam satu operasi, jadi satu state
+// hanya bisa dipakai sekali.
+func (*oidcStateRepository) Consume(stateHash string) (models.OIDCLoginState, error) {
+	collection := config.GetMongoCollection("oidc_state")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var state models.OIDCLoginState
+	err := collection.FindOneAndDelete(ctx, bson.M{
+		"state_hash": stateHash,
+		"expires_at": bson.M{"$gt": primitive.NewDateTimeFromTime(time.Now())},
+	}).Decode(&state)
+	if err != nil {
+		return state, errors.New("state login SSO tidak valid atau sudah kedaluwarsa")
+	}
+
+	return state, nil
+}
+
+// EnsureIndexes membuat index pencarian state dan TTL index supaya state
+// login yang ditinggalkan terhapus sendiri setelah kedaluwarsa.
+func (*oidcStateRepository) EnsureIndexes() error {
+	collection := config.GetMongoCollection("oidc_state")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "state_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"astro-backend/config"
+	"astro-backend/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RefreshTokenRepository interface {
+	Create(token models.RefreshToken) error
+	FindByHash(hash string) (models.RefreshToken, error)
+	Revoke(id primitive.ObjectID, replacedBy *primitive.ObjectID) error
+	RevokeAllByUser(userID primitive.ObjectID) error
+}
+
+type refreshTokenRepository struct{}
+
+func NewRefreshTokenRepository() RefreshTokenRepository {
+	return &refreshTokenRepository{}
+}
+
+func (*refreshTokenRepository) Create(token models.RefreshToken) error {
+	collection := config.GetMongoCollection("refresh_token")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, token)
+	return err
+}
+
+func (*refreshTokenRepository) FindByHash(hash string) (models.RefreshToken, error) {
+	collection := config.GetMongoCollection("refresh_token")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var token models.RefreshToken
+	if err := collection.FindOne(ctx, bson.M{"token_hash": hash}).Decode(&token); err != nil {
+		return token, errors.New("refresh token tidak ditemukan")
+	}
+
+	return token, nil
+}
+
+// Revoke menandai token sebagai dicabut. Filter revoked_at memastikan satu
+// token hanya bisa dirotasi sekali walaupun ada dua request bersamaan.
+func (*refreshTokenRepository) Revoke(id primitive.ObjectID, replacedBy *primitive.ObjectID) error {
+	collection := config.GetMongoCollection("refresh_token")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	updateData := bson.M{"revoked_at": primitive.NewDateTimeFromTime(time.Now())}
+	if replacedBy != nil {
+		updateData["replaced_by"] = *replacedBy
+	}
+
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": updateData},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("refresh token sudah tidak berlaku")
+	}
+
+	return nil
+}
+
+func (*refreshTokenRepository) RevokeAllByUser(userID primitive.ObjectID) error {
+	collection := config.GetMongoCollection("refresh_token")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": primitive.NewDateTimeFromTime(time.Now())}},
+	)
+	return err
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"astro-backend/config"
+	"astro-backend/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const securitySettingID = "security"
+
+type SecuritySettingRepository interface {
+	Get() (models.SecuritySetting, error)
+	Save(setting models.SecuritySetting) error
+}
+
+type securitySettingRepository struct{}
+
+func NewSecuritySettingRepository() SecuritySettingRepository {
+	return &securitySettingRepository{}
+}
+
+// Get mengembalikan setting kosong (bukan error) kalau dokumen belum pernah disimpan.
+func (*securitySettingRepository) Get() (models.SecuritySetting, error) {
+	collection := config.GetMongoCollection("setting")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	setting := models.SecuritySetting{ID: securitySettingID}
+	err := collection.FindOne(ctx, bson.M{"_id": securitySettingID}).Decode(&setting)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return setting, nil
+	}
+
+	return setting, err
+}
+
+func (*securitySettingRepository) Save(setting models.SecuritySetting) error {
+	collection := config.GetMongoCollection("setting")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	setting.ID = securitySettingID
+	setting.UpdatedAt = primitive.NewDateTimeFromTime(time.Now())
+
+	_, err := collection.ReplaceOne(ctx, bson.M{"_id": securitySettingID}, setting, options.Replace().SetUpsert(true))
+	return err
+}
@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"astro-backend/config"
+	"astro-backend/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SessionRepository interface {
+	Create(session models.Session) error
+	FindByID(id primitive.ObjectID) (models.Session, error)
+	ListActiveByUser(userID primitive.ObjectID) ([]models.Session, error)
+	Touch(id primitive.ObjectID, ip string, expiresAt *time.Time) error
+	Revoke(id primitive.ObjectID, reason string) error
+	RevokeAllByUser(userID primitive.ObjectID, reason string) (int64, error)
+}
+
+type sessionRepository struct{}
+
+func NewSessionRepository() SessionRepository {
+	return &sessionRepository{}
+}
+
+func (*sessionRepository) Create(session models.Session) error {
+	collection := config.GetMongoCollection("session")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, session)
+	return err
+}
+
+func (*sessionRepository) FindByID(id primitive.ObjectID) (models.Session, error) {
+	collection := config.GetMongoCollection("session")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var session models.Session
+	if err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&session); err != nil {
+		return session, errors.New("sesi tidak ditemukan")
+	}
+
+	return session, nil
+}
+
+func (*sessionRepository) ListActiveByUser(userID primitive.ObjectID) ([]models.Session, error) {
+	collection := config.GetMongoCollection("session")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": primitive.NewDateTimeFromTime(time.Now())},
+	}
+
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "last_seen_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []models.Session{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// Touch memperbarui last_seen_at dan IP terakhir. expiresAt diisi saat
+// refresh token dirotasi supaya umur sesi ikut diperpanjang.
+func (*sessionRepository) Touch(id primitive.ObjectID, ip string, expiresAt *time.Time) error {
+	collection := config.GetMongoCollection("session")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	updateData := bson.M{"last_seen_at": primitive.NewDateTimeFromTime(time.Now())}
+	if ip != "" {
+		updateData["ip_address"] = ip
+	}
+	if expiresAt != nil {
+		updateData["expires_at"] = primitive.NewDateTimeFromTime(*expiresAt)
+	}
+
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": updateData})
+	return err
+}
+
+func (*sessionRepository) Revoke(id primitive.ObjectID, reason string) error {
+	collection := config.GetMongoCollection("session")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{
+			"revoked_at":     primitive.NewDateTimeFromTime(time.Now()),
+			"revoked_reason": reason,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("sesi tidak ditemukan atau sudah dicabut")
+	}
+
+	return nil
+}
+
+func (*sessionRepository) RevokeAllByUser(userID primitive.ObjectID, reason string) (int64, error) {
+	collection := config.GetMongoCollection("session")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{
+			"revoked_at":     primitive.NewDateTimeFromTime(time.Now()),
+			"revoked_reason": reason,
+		}},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
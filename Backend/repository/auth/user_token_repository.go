@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"astro-backend/config"
+	"astro-backend/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type UserTokenRepository interface {
+	Create(token models.UserToken) error
+	Find(hash, purpose string) (models.UserToken, error)
+	Consume(hash, purpose string) (models.UserToken, error)
+	InvalidateByUser(userID primitive.ObjectID, purpose string) error
+}
+
+type userTokenRepository struct{}
+
+func NewUserTokenRepository() UserTokenRepository {
+	return &userTokenRepository{}
+}
+
+func (*userTokenRepository) Create(token models.UserToken) error {
+	collection := config.GetMongoCollection("user_token")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, token)
+	return err
+}
+
+// Find mencari token yang masih berlaku tanpa memakainya, untuk validasi
+// sebelum Consume.
+func (*userTokenRepository) Find(hash, purpose string) (models.UserToken, error) {
+	collection := config.GetMongoCollection("user_token")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var token models.UserToken
+	err := collection.FindOne(ctx, activeTokenFilter(hash, purpose, time.Now())).Decode(&token)
+	if err != nil {
+		return token, errors.New("token tidak valid atau sudah kedaluwarsa")
+	}
+
+	return token, nil
+}
+
+// Consume menandai token sebagai terpakai dalam satu operasi atomik, jadi
+// token yang sama tidak bisa dipakai dua kali.
+func (*userTokenRepository) Consume(hash, purpose string) (models.UserToken, error) {
+	collection := config.GetMongoCollection("user_token")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	update := bson.M{"$set": bson.M{"used_at": primitive.NewDateTimeFromTime(now)}}
+
+	var token models.UserToken
+	err := collection.FindOneAndUpdate(ctx, activeTokenFilter(hash, purpose, now), update).Decode(&token)
+	if err != nil {
+		return token, errors.New("token tidak valid atau sudah kedaluwarsa")
+	}
+
+	return token, nil
+}
+
+func activeTokenFilter(hash, purpose string, now time.Time) bson.M {
+	return bson.M{
+		"token_hash": hash,
+		"purpose":    purpose,
+		"used_at":    bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": primitive.NewDateTimeFromTime(now)},
+	}
+}
+
+func (*userTokenRepository) InvalidateByUser(userID primitive.ObjectID, purpose string) error {
+	collection := config.GetMongoCollection("user_token")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "purpose": purpose, "used_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"used_at": primitive.NewDateTimeFromTime(time.Now())}},
+	)
+	return err
+}
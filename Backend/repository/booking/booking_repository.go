@@ -0,0 +1,313 @@
+package booking
+
+import (
+	"astro-backend/config"
+	"astro-backend/constants"
+	"astro-backend/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// BookingFilter membatasi hasil GetAll. Field kosong tidak dipakai.
+type BookingFilter struct {
+	UserID *primitive.ObjectID
+	RoomID *primitive.ObjectID
+	Status string
+}
+
+type BookingRepository interface {
+	Create(booking models.Booking) error
+	GetAll(filter BookingFilter) ([]models.Booking, error)
+	GetByID(id string) (models.Booking, error)
+	HasOverlap(roomID primitive.ObjectID, checkIn, checkOut time.Time) (bool, error)
+	HasOverlapExcept(roomID primitive.ObjectID, checkIn, checkOut time.Time, excludeID primitive.ObjectID) (bool, error)
+	Cancel(id primitive.ObjectID, reason string, result models.CancellationResult) error
+	ApplyPromo(booking models.Booking) error
+	Modify(before, after models.Booking) error
+	// AddPayment menambah (atau mengurangi, untuk refund) amount_paid.
+	// Booking PENDING yang sudah lunas otomatis menjadi CONFIRMED.
+	AddPayment(id primitive.ObjectID, amount float64) error
+}
+
+type bookingRepository struct{}
+
+func NewBookingRepository() BookingRepository {
+	return &bookingRepository{}
+}
+
+func (*bookingRepository) Create(booking models.Booking) error {
+	collection := config.GetMongoCollection("booking")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, booking)
+	return err
+}
+
+// roomLookup menempelkan data kamar ke booking (field "room").
+func roomLookup() mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: "room"},
+			{Key: "localField", Value: "room_id"},
+			{Key: "foreignField", Value: "_id"},
+			{Key: "as", Value: "room"},
+		}}},
+		{{Key: "$unwind", Value: bson.D{
+			{Key: "path", Value: "$room"},
+			{Key: "preserveNullAndEmptyArrays", Value: true},
+		}}},
+	}
+}
+
+func (*bookingRepository) GetAll(filter BookingFilter) ([]models.Booking, error) {
+	collection := config.GetMongoCollection("booking")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	match := bson.M{}
+	if filter.UserID != nil {
+		match["user_id"] = *filter.UserID
+	}
+	if filter.RoomID != nil {
+		match["room_id"] = *filter.RoomID
+	}
+	if filter.Status != "" {
+		match["status"] = filter.Status
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: -1}}}},
+	}
+	pipeline = append(pipeline, roomLookup()...)
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	bookings := []models.Booking{}
+	if err := cursor.All(ctx, &bookings); err != nil {
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+func (*bookingRepository) GetByID(id string) (models.Booking, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.Booking{}, errors.New("ID tidak valid")
+	}
+
+	collection := config.GetMongoCollection("booking")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "_id", Value: objID}}}},
+	}
+	pipeline = append(pipeline, roomLookup()...)
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return models.Booking{}, err
+	}
+
+	var bookings []models.Booking
+	if err := cursor.All(ctx, &bookings); err != nil {
+		return models.Booking{}, err
+	}
+
+	if len(bookings) == 0 {
+		return models.Booking{}, errors.New("Booking tidak ditemukan")
+	}
+
+	return bookings[0], nil
+}
+
+// HasOverlap mengecek apakah kamar sudah dipesan pada rentang tanggal
+// tersebut. Dua rentang bentrok kalau check_in < checkOut dan check_out > checkIn.
+func (r *bookingRepository) HasOverlap(roomID primitive.ObjectID, checkIn, checkOut time.Time) (bool, error) {
+	return r.HasOverlapExcept(roomID, checkIn, checkOut, primitive.NilObjectID)
+}
+
+// HasOverlapExcept sama dengan HasOverlap tapi mengabaikan booking excludeID,
+// dipakai saat booking itu sendiri diubah.
+func (*bookingRepository) HasOverlapExcept(roomID primitive.ObjectID, checkIn, checkOut time.Time, excludeID primitive.ObjectID) (bool, error) {
+	collection := config.GetMongoCollection("booking")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"room_id":   roomID,
+		"status":    bson.M{"$in": constants.BookingActiveStatuses},
+		"check_in":  bson.M{"$lt": primitive.NewDateTimeFromTime(checkOut)},
+		"check_out": bson.M{"$gt": primitive.NewDateTimeFromTime(checkIn)},
+	}
+	if !excludeID.IsZero() {
+		filter["_id"] = bson.M{"$ne": excludeID}
+	}
+
+	count, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// Cancel mengubah status menjadi CANCELLED hanya kalau booking masih aktif,
+// jadi booking yang sama tidak bisa dibatalkan dua kali.
+func (*bookingRepository) Cancel(id primitive.ObjectID, reason string, result models.CancellationResult) error {
+	collection := config.GetMongoCollection("booking")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	update, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": bson.M{"$in": constants.BookingActiveStatuses}},
+		bson.M{"$set": bson.M{
+			"status":        constants.BookingStatusCancelled,
+			"cancelled_at":  now,
+			"cancel_reason": reason,
+			"cancellation":  result,
+			"updated_at":    now,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if update.MatchedCount == 0 {
+		return errors.New("Booking tidak bisa dibatalkan")
+	}
+
+	return nil
+}
+
+// ApplyPromo menyimpan promo code dan harga baru. Hanya booking PENDING yang
+// belum memakai promo yang bisa diubah, jadi satu booking tidak bisa
+// memakai dua promo.
+func (*bookingRepository) ApplyPromo(booking models.Booking) error {
+	collection := config.GetMongoCollection("booking")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := collection.UpdateOne(ctx,
+		bson.M{
+			"_id":        booking.Id,
+			"status":     constants.BookingStatusPending,
+			"promo_code": bson.M{"$exists": false},
+		},
+		bson.M{"$set": bson.M{
+			"promo_code":     booking.PromoCode,
+			"discount":       booking.Discount,
+			"service_charge": booking.ServiceCharge,
+			"tax":            booking.Tax,
+			"total_price":    booking.TotalPrice,
+			"updated_at":     booking.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("Promo hanya bisa dipakai pada booking PENDING yang belum memakai promo")
+	}
+
+	return nil
+}
+
+// Modify menyimpan perubahan booking. Filter updated_at memastikan booking
+// tidak berubah sejak dibaca (mis. dibatalkan atau diubah request lain).
+func (*bookingRepository) Modify(before, after models.Booking) error {
+	collection := config.GetMongoCollection("booking")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := collection.UpdateOne(ctx,
+		bson.M{
+			"_id":        before.Id,
+			"status":     bson.M{"$in": constants.BookingActiveStatuses},
+			"updated_at": before.UpdatedAt,
+		},
+		bson.M{
+			"$set": bson.M{
+				"room_id":          after.RoomID,
+				"check_in":         after.CheckIn,
+				"check_out":        after.CheckOut,
+				"nights":           after.Nights,
+				"guests":           after.Guests,
+				"price_per_night":  after.PricePerNight,
+				"subtotal":         after.Subtotal,
+				"promo_code":       after.PromoCode,
+				"discount":         after.Discount,
+				"service_charge":   after.ServiceCharge,
+				"tax":              after.Tax,
+				"total_price":      after.TotalPrice,
+				"nightly_rates":    after.NightlyRates,
+				"price_difference": after.PriceDifference,
+				"modified_at":      after.ModifiedAt,
+				"updated_at":       after.UpdatedAt,
+			},
+			// harga quote tidak berlaku lagi setelah booking diubah
+			"$unset": bson.M{"quote_id": ""},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("Booking sudah berubah atau tidak aktif, silakan muat ulang")
+	}
+
+	return nil
+}
+
+func (*bookingRepository) AddPayment(id primitive.ObjectID, amount float64) error {
+	collection := config.GetMongoCollection("booking")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{
+			"$inc": bson.M{"amount_paid": amount},
+			"$set": bson.M{"updated_at": now},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("Booking tidak ditemukan")
+	}
+
+	_, err = collection.UpdateOne(ctx,
+		bson.M{
+			"_id":    id,
+			"status": constants.BookingStatusPending,
+			"$expr":  bson.M{"$gte": bson.A{"$amount_paid", "$total_price"}},
+		},
+		bson.M{"$set": bson.M{"status": constants.BookingStatusConfirmed, "updated_at": now}},
+	)
+	return err
+}
@@ -0,0 +1,72 @@
+package booking
+
+import (
+	"astro-backend/config"
+	"astro-backend/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type QuoteRepository interface {
+	Create(quote models.Quote) error
+	GetByID(id string) (models.Quote, error)
+	EnsureIndexes() error
+}
+
+type quoteRepository struct{}
+
+func NewQuoteRepository() QuoteRepository {
+	return &quoteRepository{}
+}
+
+func (*quoteRepository) Create(quote models.Quote) error {
+	collection := config.GetMongoCollection("quote")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, quote)
+	return err
+}
+
+// GetByID juga mengembalikan quote yang sudah kedaluwarsa; pengecekan masa
+// berlaku dilakukan service supaya pesan errornya bisa dibedakan.
+func (*quoteRepository) GetByID(id string) (models.Quote, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.Quote{}, errors.New("ID tidak valid")
+	}
+
+	collection := config.GetMongoCollection("quote")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var quote models.Quote
+	if err := collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&quote); err != nil {
+		return quote, errors.New("Quote tidak ditemukan")
+	}
+
+	return quote, nil
+}
+
+// EnsureIndexes membuat TTL index; quote dihapus MongoDB sehari setelah
+// kedaluwarsa. Booking menyimpan salinan harganya sendiri.
+func (*quoteRepository) EnsureIndexes() error {
+	collection := config.GetMongoCollection("quote")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32((24 * time.Hour).Seconds())),
+	})
+	return err
+}
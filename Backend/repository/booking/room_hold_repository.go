@@ -0,0 +1,143 @@
+package booking
+
+import (
+	"astro-backend/config"
+	"astro-backend/constants"
+	"astro-backend/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrHoldNotActive dikembalikan kalau hold sudah dikonfirmasi, dilepas atau kedaluwarsa.
+var ErrHoldNotActive = errors.New("Hold sudah tidak aktif")
+
+type RoomHoldRepository interface {
+	Create(hold models.RoomHold) error
+	GetByID(id string) (models.RoomHold, error)
+	Confirm(id primitive.ObjectID) error
+	Release(id primitive.ObjectID, status string) error
+	FindExpired(now time.Time, limit int64) ([]models.RoomHold, error)
+	EnsureIndexes() error
+}
+
+type roomHoldRepository struct{}
+
+func NewRoomHoldRepository() RoomHoldRepository {
+	return &roomHoldRepository{}
+}
+
+func (*roomHoldRepository) Create(hold models.RoomHold) error {
+	collection := config.GetMongoCollection("room_hold")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, hold)
+	return err
+}
+
+func (*roomHoldRepository) GetByID(id string) (models.RoomHold, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.RoomHold{}, errors.New("ID tidak valid")
+	}
+
+	collection := config.GetMongoCollection("room_hold")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var hold models.RoomHold
+	if err := collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&hold); err != nil {
+		return hold, errors.New("Hold tidak ditemukan")
+	}
+
+	return hold, nil
+}
+
+// Confirm menandai hold aktif yang belum kedaluwarsa sebagai CONFIRMED.
+// Filter status membuat hold tidak bisa dikonfirmasi sekaligus dilepas job.
+func (*roomHoldRepository) Confirm(id primitive.ObjectID) error {
+	collection := config.GetMongoCollection("room_hold")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": constants.HoldStatusActive, "expires_at": bson.M{"$gt": now}},
+		bson.M{"$set": bson.M{"status": constants.HoldStatusConfirmed, "updated_at": now}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrHoldNotActive
+	}
+
+	return nil
+}
+
+// Release mengubah hold aktif menjadi RELEASED / EXPIRED.
+func (*roomHoldRepository) Release(id primitive.ObjectID, status string) error {
+	collection := config.GetMongoCollection("room_hold")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": constants.HoldStatusActive},
+		bson.M{"$set": bson.M{"status": status, "released_at": now, "updated_at": now}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrHoldNotActive
+	}
+
+	return nil
+}
+
+func (*roomHoldRepository) FindExpired(now time.Time, limit int64) ([]models.RoomHold, error) {
+	collection := config.GetMongoCollection("room_hold")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := collection.Find(ctx, bson.M{
+		"status":     constants.HoldStatusActive,
+		"expires_at": bson.M{"$lte": primitive.NewDateTimeFromTime(now)},
+	}, options.Find().SetSort(bson.D{{Key: "expires_at", Value: 1}}).SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	holds := []models.RoomHold{}
+	if err := cursor.All(ctx, &holds); err != nil {
+		return nil, err
+	}
+
+	return holds, nil
+}
+
+func (*roomHoldRepository) EnsureIndexes() error {
+	collection := config.GetMongoCollection("room_hold")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}},
+	})
+	return err
+}
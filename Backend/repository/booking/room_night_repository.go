@@ -0,0 +1,169 @@
+package booking
+
+import (
+	"astro-backend/config"
+	"astro-backend/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrRoomNightTaken dikembalikan Reserve kalau salah satu malam sudah
+// dipakai booking lain.
+var ErrRoomNightTaken = errors.New("kamar sudah terpakai pada salah satu malam")
+
+type RoomNightRepository interface {
+	Reserve(roomID, bookingID primitive.ObjectID, checkIn, checkOut time.Time) error
+	Release(bookingID primitive.ObjectID) error
+	// ReserveDates dan ReleaseDates dipakai saat booking diubah: hanya malam
+	// yang bertambah / berkurang yang disentuh.
+	ReserveDates(roomID, bookingID primitive.ObjectID, dates []time.Time) error
+	ReleaseDates(roomID, bookingID primitive.ObjectID, dates []time.Time) error
+	EnsureIndexes() error
+}
+
+type roomNightRepository struct{}
+
+func NewRoomNightRepository() RoomNightRepository {
+	return &roomNightRepository{}
+}
+
+// Reserve menyimpan satu dokumen per malam dari checkIn sampai sebelum
+// checkOut. Kalau ada malam yang bentrok (duplicate key), malam yang sempat
+// tersimpan untuk booking ini dihapus lagi dan ErrRoomNightTaken dikembalikan.
+func (*roomNightRepository) Reserve(roomID, bookingID primitive.ObjectID, checkIn, checkOut time.Time) error {
+	collection := config.GetMongoCollection("room_night")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	nights := []any{}
+	for d := checkIn; d.Before(checkOut); d = d.AddDate(0, 0, 1) {
+		nights = append(nights, models.RoomNight{
+			Id:        primitive.NewObjectID(),
+			RoomID:    roomID,
+			Date:      primitive.NewDateTimeFromTime(d),
+			BookingID: bookingID,
+			CreatedAt: now,
+		})
+	}
+
+	_, err := collection.InsertMany(ctx, nights, options.InsertMany().SetOrdered(true))
+	if err == nil {
+		return nil
+	}
+
+	// rollback malam yang sudah masuk; pakai context baru karena ctx bisa saja sudah habis
+	cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cleanupCancel()
+	if _, cleanupErr := collection.DeleteMany(cleanupCtx, bson.M{"booking_id": bookingID}); cleanupErr != nil {
+		return cleanupErr
+	}
+
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrRoomNightTaken
+	}
+	return err
+}
+
+// Release mengembalikan semua malam milik booking ke inventori.
+func (*roomNightRepository) Release(bookingID primitive.ObjectID) error {
+	collection := config.GetMongoCollection("room_night")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.DeleteMany(ctx, bson.M{"booking_id": bookingID})
+	return err
+}
+
+// ReserveDates seperti Reserve, tapi kalau bentrok hanya malam yang baru
+// dimasukkan yang dihapus; malam lama milik booking tetap ada.
+func (*roomNightRepository) ReserveDates(roomID, bookingID primitive.ObjectID, dates []time.Time) error {
+	if len(dates) == 0 {
+		return nil
+	}
+
+	collection := config.GetMongoCollection("room_night")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	nights := []any{}
+	ids := []primitive.ObjectID{}
+	for _, d := range dates {
+		id := primitive.NewObjectID()
+		ids = append(ids, id)
+		nights = append(nights, models.RoomNight{
+			Id:        id,
+			RoomID:    roomID,
+			Date:      primitive.NewDateTimeFromTime(d),
+			BookingID: bookingID,
+			CreatedAt: now,
+		})
+	}
+
+	_, err := collection.InsertMany(ctx, nights, options.InsertMany().SetOrdered(true))
+	if err == nil {
+		return nil
+	}
+
+	cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cleanupCancel()
+	if _, cleanupErr := collection.DeleteMany(cleanupCtx, bson.M{"_id": bson.M{"$in": ids}}); cleanupErr != nil {
+		return cleanupErr
+	}
+
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrRoomNightTaken
+	}
+	return err
+}
+
+func (*roomNightRepository) ReleaseDates(roomID, bookingID primitive.ObjectID, dates []time.Time) error {
+	if len(dates) == 0 {
+		return nil
+	}
+
+	collection := config.GetMongoCollection("room_night")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	values := bson.A{}
+	for _, d := range dates {
+		values = append(values, primitive.NewDateTimeFromTime(d))
+	}
+
+	_, err := collection.DeleteMany(ctx, bson.M{
+		"room_id":    roomID,
+		"booking_id": bookingID,
+		"date":       bson.M{"$in": values},
+	})
+	return err
+}
+
+func (*roomNightRepository) EnsureIndexes() error {
+	collection := config.GetMongoCollection("room_night")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "room_id", Value: 1}, {Key: "date", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "booking_id", Value: 1}},
+		},
+	})
+	return err
+}
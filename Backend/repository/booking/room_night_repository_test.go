@@ -0,0 +1,121 @@
+package booking
+
+import (
+	"astro-backend/config"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// connectTestDB menyambung ke MongoDB dari MONGO_TEST_URI memakai database
+// sementara yang dihapus setelah test. Test dilewati kalau MONGO_TEST_URI
+// tidak diisi, karena unique index hanya bisa dibuktikan di MongoDB asli.
+func connectTestDB(t *testing.T) {
+	t.Helper()
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI tidak diisi")
+	}
+
+	t.Setenv("MONGO_URI", uri)
+	t.Setenv("DB_NAME", fmt.Sprintf("astro_test_%d", time.Now().UnixNano()))
+	config.ConnectDB()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = config.GetMongoDB().Drop(ctx)
+		config.CloseDB()
+	})
+}
+
+// TestReserveConcurrentOverlap menembakkan banyak Reserve bersamaan untuk
+// satu kamar dengan rentang yang saling tumpang tindih. Tepat satu yang
+// boleh berhasil dan tidak boleh ada dokumen (room_id, date) ganda.
+func TestReserveConcurrentOverlap(t *testing.T) {
+	connectTestDB(t)
+
+	repo := NewRoomNightRepository()
+	if err := repo.EnsureIndexes(); err != nil {
+		t.Fatal(err)
+	}
+
+	const workers = 50
+	roomID := primitive.NewObjectID()
+	base := time.Date(2030, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	var (
+		wg      sync.WaitGroup
+		start   = make(chan struct{})
+		mu      sync.Mutex
+		winners []primitive.ObjectID
+		errs    []error
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bookingID := primitive.NewObjectID()
+			// semua rentang memuat malam base+3
+			checkIn := base.AddDate(0, 0, i%4)
+			checkOut := base.AddDate(0, 0, 4+i%3)
+
+			<-start
+			err := repo.Reserve(roomID, bookingID, checkIn, checkOut)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			winners = append(winners, bookingID)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	if len(winners) != 1 {
+		t.Fatalf("%d Reserve berhasil, want tepat 1", len(winners))
+	}
+	for _, err := range errs {
+		if !errors.Is(err, ErrRoomNightTaken) {
+			t.Errorf("error %v, want ErrRoomNightTaken", err)
+		}
+	}
+
+	collection := config.GetMongoCollection("room_night")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := collection.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"room_id": roomID}},
+		bson.M{"$group": bson.M{"_id": "$date", "count": bson.M{"$sum": 1}}},
+		bson.M{"$match": bson.M{"count": bson.M{"$gt": 1}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var duplicates []bson.M
+	if err := cursor.All(ctx, &duplicates); err != nil {
+		t.Fatal(err)
+	}
+	if len(duplicates) > 0 {
+		t.Errorf("malam ganda: %v", duplicates)
+	}
+
+	// malam dari Reserve yang gagal sudah di-rollback
+	others, err := collection.CountDocuments(ctx, bson.M{"room_id": roomID, "booking_id": bson.M{"$ne": winners[0]}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if others != 0 {
+		t.Errorf("%d malam milik Reserve yang gagal masih tersimpan", others)
+	}
+}
@@ -0,0 +1,137 @@
+package listing
+
+import (
+	"astro-backend/utils"
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrInvalidCursor = errors.New("cursor tidak valid")
+
+// cursorKey adalah isi cursor: nilai field urutan dan _id dokumen terakhir
+// di halaman sebelumnya. Dikodekan sebagai bson lalu base64 supaya tipe
+// nilainya (angka, string, tanggal) tetap utuh.
+type cursorKey struct {
+	Value bson.RawValue      `bson:"v"`
+	ID    primitive.ObjectID `bson:"id"`
+}
+
+// Find menjalankan satu halaman list di collection: filter, urut berdasarkan
+// q.SortBy lalu _id, lalu page/limit atau cursor. stages (misalnya $lookup)
+// hanya dijalankan untuk dokumen di halaman itu. Total dihitung dari filter
+// tanpa cursor.
+func Find[T any](ctx context.Context, collection *mongo.Collection, filter bson.M, q utils.ListQuery, stages mongo.Pipeline) (utils.ListPage[T], error) {
+	match := bson.M{}
+	for k, v := range filter {
+		match[k] = v
+	}
+	if q.Cursor != "" {
+		after, err := afterCursor(q)
+		if err != nil {
+			return utils.ListPage[T]{}, err
+		}
+		match = bson.M{"$and": bson.A{match, after}}
+	}
+
+	sort := bson.D{{Key: q.SortBy, Value: q.SortOrder}}
+	if q.SortBy != "_id" {
+		// _id sebagai pengurut kedua supaya urutan antar halaman stabil
+		sort = append(sort, bson.E{Key: "_id", Value: q.SortOrder})
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$sort", Value: sort}},
+	}
+	if skip := q.Skip(); skip > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$skip", Value: skip}})
+	}
+	// satu dokumen lebih untuk tahu apakah masih ada halaman berikutnya
+	pipeline = append(pipeline, bson.D{{Key: "$limit", Value: q.Limit + 1}})
+	pipeline = append(pipeline, stages...)
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return utils.ListPage[T]{}, err
+	}
+
+	var raws []bson.Raw
+	if err := cursor.All(ctx, &raws); err != nil {
+		return utils.ListPage[T]{}, err
+	}
+
+	var next string
+	if len(raws) > q.Limit {
+		raws = raws[:q.Limit]
+		if next, err = encodeCursor(raws[len(raws)-1], q.SortBy); err != nil {
+			return utils.ListPage[T]{}, err
+		}
+	}
+
+	data := make([]T, 0, len(raws))
+	for _, raw := range raws {
+		var item T
+		if err := bson.Unmarshal(raw, &item); err != nil {
+			return utils.ListPage[T]{}, err
+		}
+		data = append(data, item)
+	}
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return utils.ListPage[T]{}, err
+	}
+
+	return utils.NewListPage(q, data, total, next), nil
+}
+
+// afterCursor membuat kondisi "sesudah dokumen cursor" sesuai arah urutan.
+func afterCursor(q utils.ListQuery) (bson.M, error) {
+	b, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var key cursorKey
+	if err := bson.Unmarshal(b, &key); err != nil || key.ID.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+
+	op := "$gt"
+	if q.SortOrder < 0 {
+		op = "$lt"
+	}
+
+	if q.SortBy == "_id" {
+		return bson.M{"_id": bson.M{op: key.ID}}, nil
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{q.SortBy: bson.M{op: key.Value}},
+		bson.M{q.SortBy: key.Value, "_id": bson.M{op: key.ID}},
+	}}, nil
+}
+
+func encodeCursor(last bson.Raw, sortBy string) (string, error) {
+	id, ok := last.Lookup("_id").ObjectIDOK()
+	if !ok {
+		return "", errors.New("dokumen tanpa _id tidak bisa dipakai sebagai cursor")
+	}
+
+	// field bertingkat (mis. "room.number") dicari per bagian path
+	value, err := last.LookupErr(strings.Split(sortBy, ".")...)
+	if err != nil {
+		// field tidak ada di dokumen ini; disamakan dengan null
+		value = bson.RawValue{Type: bson.TypeNull}
+	}
+
+	b, err := bson.Marshal(cursorKey{Value: value, ID: id})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
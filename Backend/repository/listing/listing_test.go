@@ -0,0 +1,210 @@
+package listing
+
+import (
+	"astro-backend/config"
+	"astro-backend/utils"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// afterValue mengambil nilai pembanding dari kondisi hasil afterCursor.
+func afterValue(t *testing.T, cond bson.M, sortBy, op string) (bson.RawValue, primitive.ObjectID) {
+	t.Helper()
+	or, ok := cond["$or"].(bson.A)
+	if !ok || len(or) != 2 {
+		t.Fatalf("kondisi cursor %v, want $or dua cabang", cond)
+	}
+	value := or[0].(bson.M)[sortBy].(bson.M)[op].(bson.RawValue)
+	tie := or[1].(bson.M)
+	if !tie[sortBy].(bson.RawValue).Equal(value) {
+		t.Fatalf("cabang kedua membandingkan %v, want %v", tie[sortBy], value)
+	}
+	return value, tie["_id"].(bson.M)[op].(primitive.ObjectID)
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	id := primitive.NewObjectID()
+	createdAt := primitive.NewDateTimeFromTime(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name   string
+		sortBy string
+		doc    bson.D
+		want   any
+	}{
+		{"tanggal", "created_at", bson.D{{Key: "_id", Value: id}, {Key: "created_at", Value: createdAt}}, createdAt},
+		{"string", "Email", bson.D{{Key: "_id", Value: id}, {Key: "Email", Value: "budi@example.com"}}, "budi@example.com"},
+		{"angka", "price_per_night", bson.D{{Key: "_id", Value: id}, {Key: "price_per_night", Value: 750000.5}}, 750000.5},
+		{"field bertingkat", "room.number", bson.D{{Key: "_id", Value: id}, {Key: "room", Value: bson.D{{Key: "number", Value: int32(101)}}}}, int32(101)},
+		{"field tidak ada jadi null", "checked_in_at", bson.D{{Key: "_id", Value: id}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := bson.Marshal(tt.doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cursor, err := encodeCursor(raw, tt.sortBy)
+			if err != nil {
+				t.Fatalf("encodeCursor: %v", err)
+			}
+
+			for _, order := range []struct {
+				sortOrder int
+				op        string
+			}{{1, "$gt"}, {-1, "$lt"}} {
+				cond, err := afterCursor(utils.ListQuery{Cursor: cursor, SortBy: tt.sortBy, SortOrder: order.sortOrder})
+				if err != nil {
+					t.Fatalf("afterCursor: %v", err)
+				}
+
+				value, gotID := afterValue(t, cond, tt.sortBy, order.op)
+				if gotID != id {
+					t.Fatalf("_id %s, want %s", gotID.Hex(), id.Hex())
+				}
+
+				var got struct{ V any }
+				wrapped, _ := bson.Marshal(bson.D{{Key: "v", Value: value}})
+				if err := bson.Unmarshal(wrapped, &got); err != nil {
+					t.Fatal(err)
+				}
+				if got.V != tt.want {
+					t.Fatalf("nilai cursor %#v, want %#v", got.V, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCursorSortByID(t *testing.T) {
+	id := primitive.NewObjectID()
+	raw, _ := bson.Marshal(bson.D{{Key: "_id", Value: id}})
+	cursor, err := encodeCursor(raw, "_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cond, err := afterCursor(utils.ListQuery{Cursor: cursor, SortBy: "_id", SortOrder: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cond["_id"].(bson.M)["$lt"]; got != id {
+		t.Fatalf("kondisi %v, want _id $lt %s", cond, id.Hex())
+	}
+}
+
+func TestEncodeCursorWithoutID(t *testing.T) {
+	raw, _ := bson.Marshal(bson.D{{Key: "created_at", Value: "x"}})
+	if _, err := encodeCursor(raw, "created_at"); err == nil {
+		t.Fatal("dokumen tanpa _id harus ditolak")
+	}
+}
+
+func TestAfterCursorInvalid(t *testing.T) {
+	zeroID, _ := bson.Marshal(cursorKey{Value: bson.RawValue{Type: bson.TypeNull}})
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"bukan base64", "bukan cursor!"},
+		{"base64 standar dengan padding", base64.StdEncoding.EncodeToString([]byte("abc"))},
+		{"bukan bson", base64.RawURLEncoding.EncodeToString([]byte("bukan bson"))},
+		{"tanpa _id", base64.RawURLEncoding.EncodeToString(zeroID)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := afterCursor(utils.ListQuery{Cursor: tt.cursor, SortBy: "created_at", SortOrder: -1})
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("err %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+// TestFindCursorPages menelusuri semua halaman lewat cursor di MongoDB asli.
+// Nilai urutan sengaja banyak yang sama supaya _id sebagai pengurut kedua ikut
+// diuji. Dilewati kalau MONGO_TEST_URI tidak diisi.
+func TestFindCursorPages(t *testing.T) {
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI tidak diisi")
+	}
+	t.Setenv("MONGO_URI", uri)
+	t.Setenv("DB_NAME", fmt.Sprintf("astro_test_%d", time.Now().UnixNano()))
+	config.ConnectDB()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = config.GetMongoDB().Drop(ctx)
+		config.CloseDB()
+	})
+
+	type doc struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Score int                `bson:"score"`
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := config.GetMongoCollection("listing_test")
+	const total = 23
+	docs := make([]any, total)
+	for i := range docs {
+		docs[i] = doc{ID: primitive.NewObjectID(), Score: i % 4}
+	}
+	if _, err := collection.InsertMany(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, sortOrder := range []int{1, -1} {
+		q := utils.ListQuery{Limit: 5, SortBy: "score", SortOrder: sortOrder}
+		seen := map[primitive.ObjectID]bool{}
+		last := -1
+		if sortOrder < 0 {
+			last = 4
+		}
+
+		for pages := 0; ; pages++ {
+			if pages > total {
+				t.Fatal("cursor tidak pernah habis")
+			}
+			page, err := Find[doc](ctx, collection, bson.M{}, q, nil)
+			if err != nil {
+				t.Fatalf("Find: %v", err)
+			}
+			if page.Total != total {
+				t.Fatalf("total %d, want %d", page.Total, total)
+			}
+			for _, d := range page.Data {
+				if seen[d.ID] {
+					t.Fatalf("dokumen %s muncul dua kali", d.ID.Hex())
+				}
+				seen[d.ID] = true
+				if (sortOrder > 0 && d.Score < last) || (sortOrder < 0 && d.Score > last) {
+					t.Fatalf("urutan salah: %d setelah %d", d.Score, last)
+				}
+				last = d.Score
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			q.Cursor = page.NextCursor
+		}
+
+		if len(seen) != total {
+			t.Fatalf("sort_order %d: %d dokumen terbaca, want %d", sortOrder, len(seen), total)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package payment
+
+import (
+	"astro-backend/config"
+	"astro-backend/constants"
+	"astro-backend/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrPaymentStateChanged dikembalikan Transition kalau status pembayaran
+// sudah berubah (mis. webhook dan request staff datang bersamaan) atau
+// event webhook yang sama sudah pernah diproses.
+var ErrPaymentStateChanged = errors.New("Status pembayaran sudah berubah")
+
+// PaymentFilter membatasi hasil GetAll. Field kosong tidak dipakai.
+type PaymentFilter struct {
+	UserID      *primitive.ObjectID
+	PayableType string
+	PayableID   *primitive.ObjectID
+	Status      string
+}
+
+type PaymentRepository interface {
+	Create(payment models.Payment) error
+	GetByID(id string) (models.Payment, error)
+	GetAll(filter PaymentFilter) ([]models.Payment, error)
+	FindByProviderRef(provider, ref string) (models.Payment, error)
+	// Transition menyimpan status dan nominal baru kalau status saat ini
+	// masih salah satu dari from, lalu menambah event ke riwayat.
+	Transition(payment models.Payment, from []string, event models.PaymentEvent) error
+	// ReserveRefund menambah refunded_amount secara atomik selama totalnya
+	// tidak melebihi captured_amount, lalu mengembalikan data terbaru.
+	ReserveRefund(id primitive.ObjectID, amount float64) (models.Payment, error)
+	// ReleaseRefund membatalkan ReserveRefund kalau provider menolak refund.
+	ReleaseRefund(id primitive.ObjectID, amount float64) error
+	AddEvent(id primitive.ObjectID, event models.PaymentEvent) error
+	EnsureIndexes() error
+}
+
+type paymentRepository struct{}
+
+func NewPaymentRepository() PaymentRepository {
+	return &paymentRepository{}
+}
+
+func (*paymentRepository) Create(payment models.Payment) error {
+	collection := config.GetMongoCollection("payment")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, payment)
+	return err
+}
+
+func (*paymentRepository) GetByID(id string) (models.Payment, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.Payment{}, errors.New("ID tidak valid")
+	}
+
+	collection := config.GetMongoCollection("payment")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var payment models.Payment
+	if err := collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&payment); err != nil {
+		return payment, errors.New("Pembayaran tidak ditemukan")
+	}
+
+	return payment, nil
+}
+
+func (*paymentRepository) GetAll(filter PaymentFilter) ([]models.Payment, error) {
+	collection := config.GetMongoCollection("payment")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := bson.M{}
+	if filter.UserID != nil {
+		query["user_id"] = *filter.UserID
+	}
+	if filter.PayableType != "" {
+		query["payable_type"] = filter.PayableType
+	}
+	if filter.PayableID != nil {
+		query["payable_id"] = *filter.PayableID
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	cursor, err := collection.Find(ctx, query, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	payments := []models.Payment{}
+	if err := cursor.All(ctx, &payments); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+func (*paymentRepository) FindByProviderRef(provider, ref string) (models.Payment, error) {
+	collection := config.GetMongoCollection("payment")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var payment models.Payment
+	if err := collection.FindOne(ctx, bson.M{"provider": provider, "provider_ref": ref}).Decode(&payment); err != nil {
+		return payment, errors.New("Pembayaran tidak ditemukan")
+	}
+
+	return payment, nil
+}
+
+func (*paymentRepository) Transition(payment models.Payment, from []string, event models.PaymentEvent) error {
+	collection := config.GetMongoCollection("payment")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": payment.Id, "status": bson.M{"$in": from}}
+	if event.EventID != "" {
+		filter["events.event_id"] = bson.M{"$ne": event.EventID}
+	}
+
+	result, err := collection.UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{
+			"status":          payment.Status,
+			"provider_ref":    payment.ProviderRef,
+			"captured_amount": payment.CapturedAmount,
+			"refunded_amount": payment.RefundedAmount,
+			"failure_reason":  payment.FailureReason,
+			"updated_at":      payment.UpdatedAt,
+		},
+		"$push": bson.M{"events": event},
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrPaymentStateChanged
+	}
+
+	return nil
+}
+
+// refundEpsilon menoleransi selisih pembulatan float saat membandingkan
+// nominal rupiah yang sudah dibulatkan dua desimal.
+const refundEpsilon = 0.005
+
+// refundStatus menghitung status dari refunded_amount yang baru.
+func refundStatus(refunded bson.M) bson.M {
+	return bson.M{"$switch": bson.M{
+		"branches": bson.A{
+			bson.M{"case": bson.M{"$lte": bson.A{refunded, refundEpsilon}}, "then": constants.PaymentStatusCaptured},
+			bson.M{"case": bson.M{"$gte": bson.A{refunded, bson.M{"$subtract": bson.A{"$captured_amount", refundEpsilon}}}}, "then": constants.PaymentStatusRefunded},
+		},
+		"default": constants.PaymentStatusPartiallyRefunded,
+	}}
+}
+
+func (*paymentRepository) ReserveRefund(id primitive.ObjectID, amount float64) (models.Payment, error) {
+	collection := config.GetMongoCollection("payment")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	refunded := bson.M{"$add": bson.A{"$refunded_amount", amount}}
+	filter := bson.M{
+		"_id":    id,
+		"status": bson.M{"$in": bson.A{constants.PaymentStatusCaptured, constants.PaymentStatusPartiallyRefunded}},
+		"$expr":  bson.M{"$lte": bson.A{refunded, bson.M{"$add": bson.A{"$captured_amount", refundEpsilon}}}},
+	}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"status":          refundStatus(refunded),
+			"refunded_amount": refunded,
+			"updated_at":      primitive.NewDateTimeFromTime(time.Now()),
+		}}},
+	}
+
+	var payment models.Payment
+	err := collection.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&payment)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return payment, ErrPaymentStateChanged
+	}
+	return payment, err
+}
+
+func (*paymentRepository) ReleaseRefund(id primitive.ObjectID, amount float64) error {
+	collection := config.GetMongoCollection("payment")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	refunded := bson.M{"$subtract": bson.A{"$refunded_amount", amount}}
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id}, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"status":          refundStatus(refunded),
+			"refunded_amount": refunded,
+			"updated_at":      primitive.NewDateTimeFromTime(time.Now()),
+		}}},
+	})
+	return err
+}
+
+func (*paymentRepository) AddEvent(id primitive.ObjectID, event models.PaymentEvent) error {
+	collection := config.GetMongoCollection("payment")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$push": bson.M{"events": event}})
+	return err
+}
+
+func (*paymentRepository) EnsureIndexes() error {
+	collection := config.GetMongoCollection("payment")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "provider", Value: 1}, {Key: "provider_ref", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "payable_type", Value: 1}, {Key: "payable_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	})
+	return err
+}
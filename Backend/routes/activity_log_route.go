@@ -0,0 +1,27 @@
+package routes
+
+import (
+	handler_activity_log "astro-backend/handler/activityLog"
+	repository_auth "astro-backend/repository/auth"
+	"astro-backend/service/activityLog"
+	service_auth "astro-backend/service/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// ActivityLogRoutes memasang API activity log (router mux) ke gin. Endpoint
+// ini dipakai integrasi dan diautentikasi dengan API key, bukan JWT.
+func ActivityLogRoutes(r *gin.Engine, logSvc activityLog.ActivityLogService) {
+	apiKeyRepo := repository_auth.NewAPIKeyRepository()
+	if err := apiKeyRepo.EnsureIndexes(); err != nil {
+		log.Fatal().Err(err).Msg("gagal membuat index api_key")
+	}
+
+	m := mux.NewRouter()
+	handler_activity_log.NewActivityLogHandler(logSvc).RegisterRoutes(m, service_auth.NewAPIKeyService(apiKeyRepo))
+
+	r.Any("/api/admin/activity-logs", gin.WrapH(m))
+	r.Any("/api/admin/activity-logs/*path", gin.WrapH(m))
+}
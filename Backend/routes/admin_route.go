@@ -17,20 +17,35 @@ import (
 	repository_admin_roomType "astro-backend/repository/admin"
 	service_admin_roomType "astro-backend/service/admin"
 
+	"astro-backend/constants"
+	"astro-backend/middleware"
+	"astro-backend/service/activityLog"
+	"astro-backend/service/media"
+	repository_auth "astro-backend/repository/auth"
+	service_auth "astro-backend/service/auth"
+
 	"github.com/gin-gonic/gin"
 )
 
-func AdminRoutes(r *gin.Engine) {
+// AdminRoutes mengembalikan image processor supaya main bisa menghentikannya
+// (menyelesaikan antrian foto) saat server shutdown.
+func AdminRoutes(r *gin.Engine, logSvc activityLog.ActivityLogService) media.ImageProcessor {
 
 	
 	// ------User--------
 	userRepo := repository_admin_user.NewUserRepository()
-	userService := service_admin_user.NewUserService(userRepo)
+	sessionRepo := repository_auth.NewSessionRepository()
+	refreshRepo := repository_auth.NewRefreshTokenRepository()
+	userService := service_admin_user.NewUserService(userRepo, sessionRepo, refreshRepo)
 	userHandler := handler_admin_user.NewUserHandler(userService)
 	// -------Room---------
 	RoomRepo := repository_admin_room.NewRoomRepository()
-	RoomService := service_admin_room.NewRoomService(RoomRepo)
+	// upload foto diproses di worker pool supaya request tidak tertahan
+	ImageProcessor := media.NewImageProcessor(RoomRepo)
+	ImageProcessor.Start()
+	RoomService := service_admin_room.NewRoomService(RoomRepo, ImageProcessor)
 	RoomHandler := handler_admin_room.NewRoomHandler(RoomService)
+	RoomImageHandler := handler_admin_room.NewRoomImageHandler(service_admin_room.NewRoomImageService(RoomRepo, ImageProcessor))
 	// --------Facility--------
 	FacilityRepo := repository_admin_facility.NewFacilityRepository()
 	FacilityService := service_admin_facility.NewFacilityService(FacilityRepo)
@@ -40,28 +55,67 @@ func AdminRoutes(r *gin.Engine) {
 	RoomTypeService := service_admin_roomType.NewRoomTypeService(RoomTypeRepo)
 	RoomTypeHandler := handler_admin_roomType.NewRoomTypeHandler(RoomTypeService)
 
-	admin := r.Group("/admin")
+	// -------Rate Plan---------
+	RatePlanHandler := handler_admin_room.NewRatePlanHandler(service_admin_room.NewRatePlanService(repository_admin_room.NewRatePlanRepository()))
+
+	// -------Promo Code---------
+	PromoCodeHandler := handler_admin_room.NewPromoCodeHandler(service_admin_room.NewPromoCodeService(repository_admin_room.NewPromoCodeRepository()))
+
+	// -------Auth---------
+	AuthService := service_auth.NewAuthService(userRepo, refreshRepo, sessionRepo, repository_auth.NewSecuritySettingRepository(), logSvc)
+
+	// -------API Key---------
+	apiKeyHandler := handler_admin_user.NewAPIKeyHandler(service_auth.NewAPIKeyService(repository_auth.NewAPIKeyRepository()))
+
+	admin := r.Group("/admin", middleware.AuthMiddleware(AuthService))
+	can := func(permission string) gin.HandlerFunc {
+		return middleware.Authorize(permission, logSvc)
+	}
 	{
 		//  -----User-----
-		admin.GET("/user", userHandler.GetAllUsers)
-		admin.POST("/create-user", userHandler.CreateUser)
-		admin.POST("/edit-user/:id", userHandler.UpdateUser)
-		admin.DELETE("/delete-user/:id", userHandler.DeleteUser)
+		admin.GET("/user", can(constants.PermUserRead), userHandler.GetAllUsers)
+		admin.POST("/create-user", can(constants.PermUserWrite), userHandler.CreateUser)
+		admin.POST("/edit-user/:id", can(constants.PermUserWrite), userHandler.UpdateUser)
+		admin.DELETE("/delete-user/:id", can(constants.PermUserWrite), userHandler.DeleteUser)
 		// -------Room-------
-		admin.GET("/room", RoomHandler.GetAll)
-		admin.POST("/create-room", RoomHandler.CreateRoom)
+		admin.GET("/room", can(constants.PermRoomRead), RoomHandler.GetAll)
+		admin.POST("/create-room", can(constants.PermRoomWrite), RoomHandler.CreateRoom)
 		// admin.GET("/room-by-id/:id", RoomHandler.get)
-		admin.POST("/edit-room/:id", RoomHandler.Update)
-		admin.DELETE("/delete-room/:id", RoomHandler.Delete)
+		admin.POST("/edit-room/:id", can(constants.PermRoomWrite), RoomHandler.Update)
+		admin.DELETE("/delete-room/:id", can(constants.PermRoomWrite), RoomHandler.Delete)
+		// -------Room Image-------
+		admin.POST("/room/:id/images", can(constants.PermRoomWrite), RoomImageHandler.AddImages)
+		admin.POST("/room/:id/images/reorder", can(constants.PermRoomWrite), RoomImageHandler.ReorderImages)
+		admin.POST("/room/:id/images/:imageId", can(constants.PermRoomWrite), RoomImageHandler.UpdateImage)
+		admin.POST("/room/:id/images/:imageId/primary", can(constants.PermRoomWrite), RoomImageHandler.SetPrimaryImage)
+		admin.DELETE("/room/:id/images/:imageId", can(constants.PermRoomWrite), RoomImageHandler.DeleteImage)
+		// -------Rate Plan-------
+		admin.GET("/rate-plan", can(constants.PermRoomRead), RatePlanHandler.GetAllRatePlans)
+		admin.GET("/rate-plan/:id", can(constants.PermRoomRead), RatePlanHandler.GetRatePlan)
+		admin.POST("/create-rate-plan", can(constants.PermRoomWrite), RatePlanHandler.CreateRatePlan)
+		admin.POST("/edit-rate-plan/:id", can(constants.PermRoomWrite), RatePlanHandler.UpdateRatePlan)
+		admin.DELETE("/delete-rate-plan/:id", can(constants.PermRoomWrite), RatePlanHandler.DeleteRatePlan)
 		// -------Facility-------
-		admin.GET("/facility", FacilityHandler.GetAllFacilities)
-		admin.POST("/create-facility", FacilityHandler.CreateFacility)
-		admin.POST("/edit-facility/:id", FacilityHandler.UpdateFacility)
-		admin.DELETE("/delete-facility/:id", FacilityHandler.DeleteFacility)
+		admin.GET("/facility", can(constants.PermFacilityRead), FacilityHandler.GetAllFacilities)
+		admin.POST("/create-facility", can(constants.PermFacilityWrite), FacilityHandler.CreateFacility)
+		admin.POST("/edit-facility/:id", can(constants.PermFacilityWrite), FacilityHandler.UpdateFacility)
+		admin.DELETE("/delete-facility/:id", can(constants.PermFacilityWrite), FacilityHandler.DeleteFacility)
 		// -------Room Type-------
-		admin.GET("/room-type", RoomTypeHandler.GetAllRoomTypes)
-		admin.POST("/create-room-type", RoomTypeHandler.CreateRoomType)
-		admin.POST("/edit-room-type/:id", RoomTypeHandler.UpdateRoomType)
-		admin.DELETE("/delete-room-type/:id", RoomTypeHandler.DeleteRoomType)
+		admin.GET("/room-type", can(constants.PermRoomTypeRead), RoomTypeHandler.GetAllRoomTypes)
+		admin.POST("/create-room-type", can(constants.PermRoomTypeWrite), RoomTypeHandler.CreateRoomType)
+		admin.POST("/edit-room-type/:id", can(constants.PermRoomTypeWrite), RoomTypeHandler.UpdateRoomType)
+		admin.DELETE("/delete-room-type/:id", can(constants.PermRoomTypeWrite), RoomTypeHandler.DeleteRoomType)
+		// -------Promo Code-------
+		admin.GET("/promo-code", can(constants.PermPromoRead), PromoCodeHandler.GetAllPromoCodes)
+		admin.GET("/promo-code/:id", can(constants.PermPromoRead), PromoCodeHandler.GetPromoCode)
+		admin.POST("/create-promo-code", can(constants.PermPromoWrite), PromoCodeHandler.CreatePromoCode)
+		admin.POST("/edit-promo-code/:id", can(constants.PermPromoWrite), PromoCodeHandler.UpdatePromoCode)
+		admin.DELETE("/delete-promo-code/:id", can(constants.PermPromoWrite), PromoCodeHandler.DeletePromoCode)
+		// -------API Key-------
+		admin.GET("/api-keys", can(constants.PermSecurityAdmin), apiKeyHandler.GetAllAPIKeys)
+		admin.POST("/api-keys", can(constants.PermSecurityAdmin), apiKeyHandler.CreateAPIKey)
+		admin.POST("/api-keys/:id/rotate", can(constants.PermSecurityAdmin), apiKeyHandler.RotateAPIKey)
+		admin.DELETE("/api-keys/:id", can(constants.PermSecurityAdmin), apiKeyHandler.RevokeAPIKey)
 	}
+	return ImageProcessor
 }
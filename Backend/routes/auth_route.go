@@ -1,22 +1,86 @@
 package routes
 
 import (
+	"astro-backend/constants"
     // handler_admin "astro-backend/handler/admin"
 	// Repository_admin "astro-backend/repository/admin"
 	// service_admin "astro-backend/service/admin"
+	"astro-backend/mailer"
+	"astro-backend/middleware"
 	handler_auth "astro-backend/handler/auth"
 	service_auth "astro-backend/service/auth"
 	Repository_admin "astro-backend/repository/admin"
+	repository_auth "astro-backend/repository/auth"
+	"astro-backend/service/activityLog"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
 )
 
-func AuthRoutes(r *gin.Engine) {
+func AuthRoutes(r *gin.Engine, logSvc activityLog.ActivityLogService) {
 	userRepo := Repository_admin.NewUserRepository()
-	authService := service_auth.NewAuthService(userRepo)
+	if err := userRepo.EnsureIndexes(); err != nil {
+		log.Fatal().Err(err).Msg("gagal membuat index user")
+	}
+	refreshRepo := repository_auth.NewRefreshTokenRepository()
+	sessionRepo := repository_auth.NewSessionRepository()
+	settingRepo := repository_auth.NewSecuritySettingRepository()
+	authService := service_auth.NewAuthService(userRepo, refreshRepo, sessionRepo, settingRepo, logSvc)
 	authHandler := handler_auth.NewAuthHandler(authService)
 
+	twoFactorService := service_auth.NewTwoFactorService(userRepo, settingRepo, logSvc)
+	twoFactorHandler := handler_auth.NewTwoFactorHandler(twoFactorService, authService)
+
+	sessionService := service_auth.NewSessionService(userRepo, sessionRepo, refreshRepo, logSvc)
+	sessionHandler := handler_auth.NewSessionHandler(sessionService)
+
+	userTokenRepo := repository_auth.NewUserTokenRepository()
+	mailSender := mailer.NewSenderFromEnv()
+
+	registerService := service_auth.NewRegisterService(userRepo, userTokenRepo, mailSender)
+	registerHandler := handler_auth.NewRegisterHandler(registerService)
+
+	passwordResetService := service_auth.NewPasswordResetService(userRepo, userTokenRepo, refreshRepo, sessionRepo, mailSender, logSvc)
+	passwordResetHandler := handler_auth.NewPasswordResetHandler(passwordResetService)
+
+	oidcService := service_auth.NewOIDCService(userRepo, repository_auth.NewOIDCStateRepository(), sessionRepo, refreshRepo, authService, logSvc)
+	oidcHandler := handler_auth.NewOIDCHandler(oidcService)
+
 	r.GET("/login", handler_auth.IndexAuth)
 	r.POST("/login/do-login", authHandler.Login)
+	r.POST("/login/refresh", authHandler.Refresh)
+	r.POST("/login/2fa", authHandler.VerifyTwoFactor)
+	r.GET("/login/oidc", oidcHandler.Login)
+	r.GET("/login/oidc/callback", oidcHandler.Callback)
+	r.POST("/logout", middleware.AuthMiddleware(authService), sessionHandler.Logout)
+
+	r.POST("/register", registerHandler.Register)
+	r.GET("/register/verify", registerHandler.VerifyEmail)
+	r.POST("/register/verify", registerHandler.VerifyEmail)
+	r.POST("/register/resend-verification", registerHandler.ResendVerification)
+
+	r.POST("/password/forgot", passwordResetHandler.RequestReset)
+	r.GET("/password/reset", passwordResetHandler.ShowReset)
+	r.POST("/password/reset", passwordResetHandler.ConfirmReset)
+
+	// -------Account (user yang sedang login)-------
+	// setup & enable 2FA juga menerima setup_token dari login untuk role yang wajib 2FA
+	allowSetupToken := middleware.AuthMiddleware(authService, service_auth.TokenTypeAccess, service_auth.TokenTypeMFASetup)
+	account := r.Group("/account")
+	{
+		account.POST("/2fa/setup", allowSetupToken, twoFactorHandler.Setup)
+		account.POST("/2fa/enable", allowSetupToken, twoFactorHandler.Enable)
+		account.POST("/2fa/disable", middleware.AuthMiddleware(authService), twoFactorHandler.Disable)
+		account.GET("/sessions", middleware.AuthMiddleware(authService), sessionHandler.ListSessions)
+		account.DELETE("/sessions/:id", middleware.AuthMiddleware(authService), sessionHandler.RevokeSession)
+	}
 
-}
\ No newline at end of file
+	// -------Security settings (admin)-------
+	security := r.Group("/admin/security", middleware.AuthMiddleware(authService), middleware.Authorize(constants.PermSecurityAdmin, logSvc))
+	{
+		security.GET("/2fa-roles", twoFactorHandler.GetRequiredRoles)
+		security.PUT("/2fa-roles", twoFactorHandler.SetRequiredRoles)
+		security.GET("/users/:id/sessions", sessionHandler.ListUserSessions)
+		security.POST("/users/:id/force-logout", sessionHandler.ForceLogout)
+	}
+}
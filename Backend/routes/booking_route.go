@@ -0,0 +1,101 @@
+package routes
+
+import (
+	handler_booking "astro-backend/handler/booking"
+	repository_admin "astro-backend/repository/admin"
+	repository_auth "astro-backend/repository/auth"
+	repository_booking "astro-backend/repository/booking"
+	"astro-backend/service/activityLog"
+	service_auth "astro-backend/service/auth"
+	service_booking "astro-backend/service/booking"
+	service_pricing "astro-backend/service/pricing"
+
+	"astro-backend/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// BookingRoutes mengembalikan hold service supaya main memakai instance yang
+// sama untuk scheduler.HoldExpiryJob.
+func BookingRoutes(r *gin.Engine, logSvc activityLog.ActivityLogService) service_booking.HoldService {
+	userRepo := repository_admin.NewUserRepository()
+	roomRepo := repository_admin.NewRoomRepository()
+
+	authService := service_auth.NewAuthService(userRepo, repository_auth.NewRefreshTokenRepository(), repository_auth.NewSessionRepository(), repository_auth.NewSecuritySettingRepository(), logSvc)
+
+	roomNightRepo := repository_booking.NewRoomNightRepository()
+	// unique index (room_id, date) adalah satu-satunya pengaman double
+	// booking, jadi server tidak boleh jalan tanpanya
+	if err := roomNightRepo.EnsureIndexes(); err != nil {
+		log.Fatal().Err(err).Msg("gagal membuat index room_night")
+	}
+
+	pricingService := service_pricing.NewPricingService(repository_admin.NewRatePlanRepository(), roomRepo)
+	priceHandler := handler_booking.NewPriceHandler(pricingService)
+
+	quoteRepo := repository_booking.NewQuoteRepository()
+	if err := quoteRepo.EnsureIndexes(); err != nil {
+		log.Fatal().Err(err).Msg("gagal membuat index quote")
+	}
+	quoteService := service_booking.NewQuoteService(quoteRepo, roomRepo, pricingService)
+	quoteHandler := handler_booking.NewQuoteHandler(quoteService)
+
+	cancellationService := service_booking.NewCancellationService(roomRepo, repository_admin.NewRoomTypeRepository())
+
+	promoRepo := repository_admin.NewPromoCodeRepository()
+	// batas pemakaian per user di ReserveUsage hanya atomik karena unique
+	// index (promo_id, user_id); tanpa index batas itu bisa dilewati
+	if err := promoRepo.EnsureIndexes(); err != nil {
+		log.Fatal().Err(err).Msg("gagal membuat index promo_code")
+	}
+
+	bookingService := service_booking.NewBookingService(repository_booking.NewBookingRepository(), roomNightRepo, roomRepo, promoRepo, quoteService, cancellationService, logSvc)
+	bookingHandler := handler_booking.NewBookingHandler(bookingService)
+
+	promoService := service_booking.NewPromoService(promoRepo, repository_booking.NewBookingRepository(), roomRepo, bookingService, quoteService)
+	promoHandler := handler_booking.NewPromoHandler(promoService)
+
+	holdRepo := repository_booking.NewRoomHoldRepository()
+	if err := holdRepo.EnsureIndexes(); err != nil {
+		log.Fatal().Err(err).Msg("gagal membuat index room_hold")
+	}
+	holdService := service_booking.NewHoldService(holdRepo, repository_booking.NewBookingRepository(), roomNightRepo, roomRepo, quoteService)
+	holdHandler := handler_booking.NewHoldHandler(holdService)
+
+	// pencarian kamar kosong bisa diakses tanpa login
+	r.GET("/api/rooms/availability", bookingHandler.SearchAvailability)
+	// harga per malam hasil rate plan, juga publik
+	r.GET("/api/rooms/:id/price", priceHandler.GetRoomPrice)
+
+	quotes := r.Group("/api/quotes", middleware.AuthMiddleware(authService))
+	{
+		quotes.POST("", quoteHandler.CreateQuote)
+		quotes.GET("/:id", quoteHandler.GetQuote)
+	}
+
+	bookings := r.Group("/api/bookings", middleware.AuthMiddleware(authService))
+	{
+		bookings.POST("", bookingHandler.CreateBooking)
+		bookings.GET("", bookingHandler.GetAllBookings)
+		bookings.GET("/:id", bookingHandler.GetBooking)
+		bookings.POST("/:id/cancel", bookingHandler.CancelBooking)
+		bookings.POST("/:id/modify", bookingHandler.ModifyBooking)
+	}
+
+	promos := r.Group("/api/promos", middleware.AuthMiddleware(authService))
+	{
+		promos.POST("/validate", promoHandler.ValidatePromo)
+		promos.POST("/redeem", promoHandler.RedeemPromo)
+	}
+
+	// hold kamar selama checkout; dilepas otomatis oleh scheduler.HoldExpiryJob
+	holds := r.Group("/api/holds", middleware.AuthMiddleware(authService))
+	{
+		holds.POST("", holdHandler.CreateHold)
+		holds.POST("/:id/confirm", holdHandler.ConfirmHold)
+		holds.DELETE("/:id", holdHandler.ReleaseHold)
+	}
+
+	return holdService
+}
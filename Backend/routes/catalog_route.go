@@ -0,0 +1,26 @@
+package routes
+
+import (
+	handler_catalog "astro-backend/handler/catalog"
+	repository_admin "astro-backend/repository/admin"
+	service_catalog "astro-backend/service/catalog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CatalogRoutes memasang katalog kamar publik (tanpa login). Data kamar
+// dikirim lewat DTO publik, bukan model admin.
+func CatalogRoutes(r *gin.Engine) {
+	catalogService := service_catalog.NewCatalogService(
+		repository_admin.NewRoomRepository(),
+		repository_admin.NewRoomTypeRepository(),
+		repository_admin.NewFacilityRepository(),
+	)
+	catalogHandler := handler_catalog.NewCatalogHandler(catalogService)
+
+	// /api/rooms/availability dan /api/rooms/:id/price ada di BookingRoutes
+	r.GET("/api/rooms", catalogHandler.ListRooms)
+	r.GET("/api/rooms/:id", catalogHandler.GetRoom)
+	r.GET("/api/room-types", catalogHandler.ListRoomTypes)
+	r.GET("/api/facilities", catalogHandler.ListFacilities)
+}
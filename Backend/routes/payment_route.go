@@ -0,0 +1,53 @@
+package routes
+
+import (
+	"astro-backend/constants"
+	handler_payment "astro-backend/handler/payment"
+	"astro-backend/middleware"
+	repository_admin "astro-backend/repository/admin"
+	repository_auth "astro-backend/repository/auth"
+	repository_booking "astro-backend/repository/booking"
+	repository_payment "astro-backend/repository/payment"
+	"astro-backend/service/activityLog"
+	service_auth "astro-backend/service/auth"
+	service_payment "astro-backend/service/payment"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+func PaymentRoutes(r *gin.Engine, logSvc activityLog.ActivityLogService) {
+	userRepo := repository_admin.NewUserRepository()
+	authService := service_auth.NewAuthService(userRepo, repository_auth.NewRefreshTokenRepository(), repository_auth.NewSessionRepository(), repository_auth.NewSecuritySettingRepository(), logSvc)
+
+	paymentRepo := repository_payment.NewPaymentRepository()
+	if err := paymentRepo.EnsureIndexes(); err != nil {
+		log.Fatal().Err(err).Msg("gagal membuat index payment")
+	}
+
+	// fake provider menyimpan transaksi di memori, jadi hanya dibuat sekali
+	paymentService := service_payment.NewPaymentService(
+		paymentRepo,
+		repository_booking.NewBookingRepository(),
+		repository_admin.NewRoomRepository(),
+		service_payment.NewFakeProvider(),
+	)
+	paymentHandler := handler_payment.NewPaymentHandler(paymentService)
+
+	// webhook provider tidak memakai login, diverifikasi lewat signature HMAC
+	r.POST("/api/payments/webhook/:provider", paymentHandler.Webhook)
+
+	can := func(permission string) gin.HandlerFunc {
+		return middleware.Authorize(permission, logSvc)
+	}
+
+	payments := r.Group("/api/payments", middleware.AuthMiddleware(authService))
+	{
+		payments.POST("", paymentHandler.CreatePayment)
+		payments.GET("", paymentHandler.GetAllPayments)
+		payments.GET("/:id", paymentHandler.GetPayment)
+		payments.POST("/:id/refresh", paymentHandler.RefreshPayment)
+		payments.POST("/:id/capture", can(constants.PermPaymentWrite), paymentHandler.CapturePayment)
+		payments.POST("/:id/refund", can(constants.PermPaymentWrite), paymentHandler.RefundPayment)
+	}
+}
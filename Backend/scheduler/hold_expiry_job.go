@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"astro-backend/constants"
+	"astro-backend/models"
+	"astro-backend/service/activityLog"
+	"astro-backend/service/booking"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HoldExpiryJob melepas hold kamar yang sudah kedaluwarsa secara berkala
+// dan mencatat setiap pelepasan ke activity log.
+type HoldExpiryJob struct {
+	Holds     booking.HoldService
+	LogSvc    activityLog.ActivityLogService
+	Interval  time.Duration
+	BatchSize int64
+	stop      chan struct{}
+}
+
+// NewHoldExpiryJob reads HOLD_EXPIRY_INTERVAL and HOLD_EXPIRY_BATCH_SIZE from env.
+func NewHoldExpiryJob(holds booking.HoldService, logSvc activityLog.ActivityLogService) *HoldExpiryJob {
+	return &HoldExpiryJob{
+		Holds:     holds,
+		LogSvc:    logSvc,
+		Interval:  parseDurationEnv("HOLD_EXPIRY_INTERVAL", time.Minute),
+		BatchSize: parseInt64Env("HOLD_EXPIRY_BATCH_SIZE", 500),
+		stop:      make(chan struct{}),
+	}
+}
+
+func (j *HoldExpiryJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+	log.Info().Dur("interval", j.Interval).Msg("hold expiry job started")
+	j.runOnce()
+	for {
+		select {
+		case <-ticker.C:
+			j.runOnce()
+		case <-j.stop:
+			log.Info().Msg("hold expiry job stopped")
+			return
+		case <-ctx.Done():
+			log.Info().Msg("hold expiry job context cancelled")
+			return
+		}
+	}
+}
+
+func (j *HoldExpiryJob) Stop() { close(j.stop) }
+
+func (j *HoldExpiryJob) runOnce() {
+	released, err := j.Holds.ReleaseExpired(j.BatchSize)
+	if err != nil {
+		log.Error().Err(err).Msg("release expired holds failed")
+		return
+	}
+
+	for _, hold := range released {
+		userID := hold.UserID
+		entry := models.ActivityLog{
+			UserID:     &userID,
+			ActionType: constants.ActHoldExpired,
+			Endpoint:   "hold_expiry_job",
+			Method:     "SYSTEM",
+			Resource:   "room_holds",
+			ResourceID: hold.Id.Hex(),
+			Message:    "hold kamar kedaluwarsa dan dilepas",
+			Metadata: primitive.M{
+				"room_id":    hold.RoomID.Hex(),
+				"check_in":   hold.CheckIn.Time().UTC().Format("2006-01-02"),
+				"check_out":  hold.CheckOut.Time().UTC().Format("2006-01-02"),
+				"expires_at": hold.ExpiresAt.Time(),
+			},
+			Status: constants.StatusSuccess,
+		}
+		if j.LogSvc != nil {
+			if err := j.LogSvc.Log(context.Background(), entry); err != nil {
+				log.Error().Err(err).Str("hold_id", hold.Id.Hex()).Msg("activity log failed")
+			}
+		}
+	}
+
+	if len(released) > 0 {
+		log.Info().Int("released", len(released)).Msg("expired holds released")
+	}
+}
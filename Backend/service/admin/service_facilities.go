@@ -3,15 +3,17 @@ package admin
 import (
 	"astro-backend/models"
 	"astro-backend/repository/admin"
+	"astro-backend/utils"
 	"errors"
+	"strings"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type FacilityService interface {
-	GetAll() ([]models.Facility, error)
+	List(filter admin.FacilityListFilter, q utils.ListQuery) (utils.ListPage[models.Facility], error)
 	Create(facility models.Facility) (models.Facility, error)
-	Update(id string, facility models.Facility) error
+	Update(id string, input UpdateFacilityInput) error
 	Delete(id string) error
 }
 
@@ -19,6 +21,12 @@ type FacilityService interface {
 // 	panic("unimplemented")
 // }
 
+// UpdateFacilityInput adalah perubahan sebagian pada fasilitas; field nil
+// tidak diubah.
+type UpdateFacilityInput struct {
+	Name *string `json:"name"`
+}
+
 type facilityService struct {
 	repo admin.FacilityRepository
 }
@@ -28,16 +36,24 @@ func NewFacilityService(repo admin.FacilityRepository) FacilityService {
 }
 
 // -------  main method ------------------
-func (s *facilityService) GetAll() ([]models.Facility, error) {
-	return s.repo.GetAll()
+func (s *facilityService) List(filter admin.FacilityListFilter, q utils.ListQuery) (utils.ListPage[models.Facility], error) {
+	return s.repo.List(filter, q)
 }
 func (s *facilityService) Create(facility models.Facility) (models.Facility, error) {
 	id := primitive.NewObjectID()
 	facility.ID = id
 	return s.repo.Create(facility)
 }
-func (s *facilityService) Update(id string, facility models.Facility) error {
-	return s.repo.Update(id, facility)
+func (s *facilityService) Update(id string, input UpdateFacilityInput) error {
+	var patch admin.FacilityPatch
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return errors.New("name tidak boleh kosong")
+		}
+		patch.Name = &name
+	}
+	return s.repo.Update(id, patch)
 }
 func (s *facilityService) Delete(id string) error {
 
@@ -0,0 +1,110 @@
+package admin
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	"astro-backend/repository/admin"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PromoCodeService interface {
+	GetAll() ([]models.PromoCode, error)
+	GetByID(id string) (models.PromoCode, error)
+	Create(promo models.PromoCode) (models.PromoCode, error)
+	Update(id string, promo models.PromoCode) error
+	Delete(id string) error
+}
+
+type promoCodeService struct {
+	repo admin.PromoCodeRepository
+}
+
+func NewPromoCodeService(repo admin.PromoCodeRepository) PromoCodeService {
+	return &promoCodeService{repo}
+}
+
+func (s *promoCodeService) GetAll() ([]models.PromoCode, error) {
+	return s.repo.GetAll()
+}
+
+func (s *promoCodeService) GetByID(id string) (models.PromoCode, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *promoCodeService) Create(promo models.PromoCode) (models.PromoCode, error) {
+	if err := validatePromoCode(&promo); err != nil {
+		return models.PromoCode{}, err
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	promo.ID = primitive.NewObjectID()
+	promo.UsedCount = 0
+	promo.CreatedAt = now
+	promo.UpdatedAt = now
+
+	if err := s.repo.Create(promo); err != nil {
+		return models.PromoCode{}, err
+	}
+	return promo, nil
+}
+
+func (s *promoCodeService) Update(id string, promo models.PromoCode) error {
+	if err := validatePromoCode(&promo); err != nil {
+		return err
+	}
+
+	promo.UpdatedAt = primitive.NewDateTimeFromTime(time.Now())
+	return s.repo.Update(id, promo)
+}
+
+func (s *promoCodeService) Delete(id string) error {
+	if id == "" {
+		return errors.New("ID tidak boleh kosong")
+	}
+	return s.repo.Delete(id)
+}
+
+// NormalizePromoCode dipakai saat menyimpan dan mencari kode supaya tidak
+// peka huruf besar/kecil.
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func validatePromoCode(promo *models.PromoCode) error {
+	promo.Code = NormalizePromoCode(promo.Code)
+	if promo.Code == "" {
+		return errors.New("Kode promo wajib diisi")
+	}
+	if strings.ContainsAny(promo.Code, " \t") {
+		return errors.New("Kode promo tidak boleh mengandung spasi")
+	}
+
+	switch promo.DiscountType {
+	case constants.DiscountTypePercent:
+		if promo.Value <= 0 || promo.Value > 100 {
+			return errors.New("Diskon persen harus di antara 0 dan 100")
+		}
+	case constants.DiscountTypeFixed:
+		if promo.Value <= 0 {
+			return errors.New("Nilai diskon harus lebih dari 0")
+		}
+	default:
+		return errors.New("discount_type harus PERCENT atau FIXED")
+	}
+
+	if promo.MaxDiscount < 0 {
+		return errors.New("max_discount tidak boleh negatif")
+	}
+	if promo.MinNights < 0 || promo.UsageLimit < 0 || promo.PerUserLimit < 0 {
+		return errors.New("min_nights, usage_limit dan per_user_limit tidak boleh negatif")
+	}
+	if promo.ValidFrom != nil && promo.ValidUntil != nil && promo.ValidUntil.Time().Before(promo.ValidFrom.Time()) {
+		return errors.New("valid_until tidak boleh sebelum valid_from")
+	}
+
+	return nil
+}
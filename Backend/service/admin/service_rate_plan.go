@@ -0,0 +1,134 @@
+package admin
+
+import (
+	"astro-backend/models"
+	"astro-backend/repository/admin"
+	"astro-backend/utils"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var validDays = map[string]bool{
+	"monday": true, "tuesday": true, "wednesday": true, "thursday": true,
+	"friday": true, "saturday": true, "sunday": true,
+	"weekday": true, "weekend": true,
+}
+
+type RatePlanService interface {
+	GetAll() ([]models.RatePlan, error)
+	GetByID(id string) (models.RatePlan, error)
+	Create(plan models.RatePlan) (models.RatePlan, error)
+	Update(id string, plan models.RatePlan) error
+	Delete(id string) error
+}
+
+type ratePlanService struct {
+	repo admin.RatePlanRepository
+}
+
+func NewRatePlanService(repo admin.RatePlanRepository) RatePlanService {
+	return &ratePlanService{repo}
+}
+
+func (s *ratePlanService) GetAll() ([]models.RatePlan, error) {
+	return s.repo.GetAll()
+}
+
+func (s *ratePlanService) GetByID(id string) (models.RatePlan, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *ratePlanService) Create(plan models.RatePlan) (models.RatePlan, error) {
+	if err := validateRatePlan(&plan); err != nil {
+		return models.RatePlan{}, err
+	}
+
+	plan.ID = primitive.NewObjectID()
+	plan.CreatedAt = primitive.NewDateTimeFromTime(time.Now())
+	plan.UpdatedAt = plan.CreatedAt
+
+	if err := s.repo.Create(plan); err != nil {
+		return models.RatePlan{}, err
+	}
+	return plan, nil
+}
+
+func (s *ratePlanService) Update(id string, plan models.RatePlan) error {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := validateRatePlan(&plan); err != nil {
+		return err
+	}
+
+	plan.CreatedAt = existing.CreatedAt
+	plan.UpdatedAt = primitive.NewDateTimeFromTime(time.Now())
+	return s.repo.Update(id, plan)
+}
+
+func (s *ratePlanService) Delete(id string) error {
+	if id == "" {
+		return errors.New("ID tidak boleh kosong")
+	}
+	return s.repo.Delete(id)
+}
+
+func validateRatePlan(plan *models.RatePlan) error {
+	plan.Name = strings.TrimSpace(plan.Name)
+	if plan.Name == "" {
+		return errors.New("Nama rate plan wajib diisi")
+	}
+	if (plan.RoomID == nil) == (plan.RoomTypeID == nil) {
+		return errors.New("Isi salah satu: room_id atau room_type_id")
+	}
+	if plan.BaseRate < 0 {
+		return errors.New("base_rate tidak boleh negatif")
+	}
+	if err := validateDayRates(plan.DayRates); err != nil {
+		return err
+	}
+
+	for i := range plan.Seasons {
+		season := &plan.Seasons[i]
+		season.Name = strings.TrimSpace(season.Name)
+		if season.Name == "" {
+			return errors.New("Nama season wajib diisi")
+		}
+		start, err := utils.ParseDate(season.StartDate)
+		if err != nil {
+			return errors.New("start_date season " + season.Name + ": " + err.Error())
+		}
+		end, err := utils.ParseDate(season.EndDate)
+		if err != nil {
+			return errors.New("end_date season " + season.Name + ": " + err.Error())
+		}
+		if end.Before(start) {
+			return errors.New("end_date season " + season.Name + " sebelum start_date")
+		}
+		if season.Rate < 0 {
+			return errors.New("rate season " + season.Name + " tidak boleh negatif")
+		}
+		if err := validateDayRates(season.DayRates); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateDayRates(rates map[string]float64) error {
+	for day, rate := range rates {
+		if !validDays[day] {
+			return errors.New("nama hari tidak dikenal: " + day + " (pakai monday..sunday, weekday atau weekend)")
+		}
+		if rate < 0 {
+			return errors.New("harga hari " + day + " tidak boleh negatif")
+		}
+	}
+	return nil
+}
@@ -2,40 +2,59 @@ package admin
 
 import (
 	"astro-backend/models"
-	"fmt"
+	"astro-backend/service/media"
 	"time"
 	"errors"
-	"os"
 	"strings"
 
 	
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"astro-backend/repository/admin"
+	"astro-backend/utils"
 )
 
 type RoomService interface {
-	CreateRoom(name, description, roomNumber string, price float64, typeID string, capacity int, bedType, category string, facIDs, images []string) error
-	UpdateRoom(id string, name, description, roomNumber string, price float64, typeID string, capacity int, bedType, category string, facIDs, images []string) error
+	CreateRoom(name, description, roomNumber string, price float64, typeID string, capacity int, bedType, category string, facIDs []string, images []NewRoomImage) error
+	UpdateRoom(id string, input UpdateRoomInput) error
 	DeleteRoom(id string) error
-	GetAll() ([]models.Room, error)
+	List(filter admin.RoomListFilter, q utils.ListQuery) (utils.ListPage[models.Room], error)
 	GetByID(id string) (models.Room, error)
 }
 
+var ErrRoomNotFound = errors.New("room not found")
+
+// UpdateRoomInput adalah perubahan sebagian pada kamar. Field nil berarti
+// tidak diubah; Images ditambahkan ke galeri, foto lama tetap ada.
+type UpdateRoomInput struct {
+	Name          *string
+	Description   *string
+	RoomNumber    *string
+	PricePerNight *float64
+	RoomTypeID    *string
+	Capacity      *int
+	BedType       *string
+	Category      *string
+	FacilityIDs   *[]string
+	Availability  *bool
+	Images        []NewRoomImage
+}
 
 type roomService struct {
-	repo admin.RoomRepository
+	repo      admin.RoomRepository
+	processor media.ImageProcessor
 }
 
-func NewRoomService(repo admin.RoomRepository) RoomService {
-	return &roomService{repo}
+func NewRoomService(repo admin.RoomRepository, processor media.ImageProcessor) RoomService {
+	return &roomService{repo, processor}
 }
 
-func (s *roomService) CreateRoom(name, description, roomNumber string, price float64, typeID string, capacity int, bedType, category string, facID, images []string) error {
+func (s *roomService) CreateRoom(name, description, roomNumber string, price float64, typeID string, capacity int, bedType, category string, facID []string, images []NewRoomImage) error {
 
     typeIDObj, err := primitive.ObjectIDFromHex(typeID)
 
 	if err != nil {
+		discardNewRoomImages(images)
 		return errors.New("invalid room type ID")
 	}
 
@@ -43,6 +62,7 @@ func (s *roomService) CreateRoom(name, description, roomNumber string, price flo
 	for _, id := range facID {
 		oid, err := primitive.ObjectIDFromHex(id)
 		if err != nil {
+			discardNewRoomImages(images)
 			return errors.New("invalid facility ID: " + id)
 		}
 		facObjIDs = append(facObjIDs, oid)
@@ -58,59 +78,94 @@ func (s *roomService) CreateRoom(name, description, roomNumber string, price flo
 		FacilitiesID:  facObjIDs,
 		Bed_type:      bedType,
 		Category:      category,
-		Images:        images,
+		Images:        newRoomImages(images, true),
 		Capacity:      capacity,
 		Availability:  true,
 		CreatedAt:     primitive.NewDateTimeFromTime(time.Now()),
 		UpdatedAt:     primitive.NewDateTimeFromTime(time.Now()),
 	}
-	
-	return s.repo.Create(room)
+
+	if err := s.repo.Create(room); err != nil {
+		discardNewRoomImages(images)
+		return err
+	}
+
+	// foto diproses di belakang; kamar sudah tersimpan dengan status PROCESSING
+	queueRoomImages(s.repo, s.processor, room.Id, room.Images, images)
+	return nil
 }
 
-func (s *roomService) UpdateRoom(id string, name, description, roomNumber string, price float64,
-	typeID string, capacity int, bedType, category string, facIDs, images []string) error {
+// UpdateRoom hanya mengubah field yang diisi di input; setiap field
+// divalidasi sendiri-sendiri. Upload baru hanya dipakai kalau perubahan
+// field berhasil.
+func (s *roomService) UpdateRoom(id string, input UpdateRoomInput) error {
+	if err := s.updateFields(id, input); err != nil {
+		discardNewRoomImages(input.Images)
+		return err
+	}
 
-	room, err := s.repo.GetByID(id)
-	if err != nil {
-		return errors.New("room not found")
+	if len(input.Images) > 0 {
+		if _, err := addRoomImages(s.repo, s.processor, id, input.Images); err != nil {
+			return err
+		}
 	}
 
-	// convert RoomTypeID
-	typeIDObj, err := primitive.ObjectIDFromHex(typeID)
-	if err != nil {
-		return errors.New("invalid room type ID")
+	return nil
+}
+
+func (s *roomService) updateFields(id string, input UpdateRoomInput) error {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return ErrRoomNotFound
 	}
 
-	// convert FacilitiesID
-	facObjIDs := []primitive.ObjectID{}
-	for _, fid := range facIDs {
-		oid, err := primitive.ObjectIDFromHex(fid)
+	patch := admin.RoomPatch{
+		Description:  input.Description,
+		RoomNumber:   input.RoomNumber,
+		BedType:      input.BedType,
+		Category:     input.Category,
+		Availability: input.Availability,
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return errors.New("name tidak boleh kosong")
+		}
+		patch.Name = &name
+	}
+	if input.PricePerNight != nil {
+		if *input.PricePerNight <= 0 {
+			return errors.New("price_per_night harus lebih dari 0")
+		}
+		patch.PricePerNight = input.PricePerNight
+	}
+	if input.Capacity != nil {
+		if *input.Capacity < 1 {
+			return errors.New("capacity minimal 1")
+		}
+		patch.Capacity = input.Capacity
+	}
+	if input.RoomTypeID != nil {
+		typeIDObj, err := primitive.ObjectIDFromHex(*input.RoomTypeID)
 		if err != nil {
-			return errors.New("invalid facility ID: " + fid)
+			return errors.New("invalid room type ID")
 		}
-		facObjIDs = append(facObjIDs, oid)
+		patch.RoomTypeID = &typeIDObj
 	}
-
-	room.Name = name
-	room.Description = description
-	room.RoomNumber = roomNumber
-	room.PricePerNight = price
-	room.RoomTypeID = typeIDObj
-	room.Capacity = capacity
-	room.Bed_type = bedType
-	room.Category = category
-	room.FacilitiesID = facObjIDs
-	if len(images) > 0 {
-		room.Images = images
+	if input.FacilityIDs != nil {
+		facObjIDs := []primitive.ObjectID{}
+		for _, fid := range *input.FacilityIDs {
+			oid, err := primitive.ObjectIDFromHex(fid)
+			if err != nil {
+				return errors.New("invalid facility ID: " + fid)
+			}
+			facObjIDs = append(facObjIDs, oid)
+		}
+		patch.FacilitiesID = &facObjIDs
 	}
-	room.UpdatedAt = primitive.NewDateTimeFromTime(time.Now())
-
-	return s.repo.Update(id, room)
+	return s.repo.Update(id, patch)
 }
 
-
-
 func (s *roomService) DeleteRoom(id string) error {
     // 1. Ambil data room agar tau daftar file fotonya
     room, err := s.repo.GetByID(id)
@@ -124,26 +179,15 @@ func (s *roomService) DeleteRoom(id string) error {
     }
 
     // 3. Hapus file foto satu per satu
-    for _, imageURL := range room.Images {
-        if imageURL == "" {
-            continue
-        }
-
-        // 3a. Convert URL → file path fisik
-        // contoh: "/uploads/rooms/a.png" → "uploads/rooms/a.png"
-        filePath := strings.TrimPrefix(imageURL, "/")
-
-        // 3b. Hapus file
-        if err := os.Remove(filePath); err != nil {
-            fmt.Println("⚠️ Gagal hapus file:", filePath, err)
-        }
+    for _, image := range room.Images {
+        removeRoomImageFile(image)
     }
 
     return nil
 }
 
-func (s *roomService) GetAll() ([]models.Room, error) {
-	return s.repo.GetAll()
+func (s *roomService) List(filter admin.RoomListFilter, q utils.ListQuery) (utils.ListPage[models.Room], error) {
+	return s.repo.List(filter, q)
 }
 
 func (s *roomService) GetByID(id string) (models.Room, error) {
@@ -2,19 +2,33 @@ package admin
 
 import(
 
+	"astro-backend/constants"
 	"astro-backend/models"
 	"astro-backend/repository/admin"
+	"astro-backend/utils"
+	"errors"
+	"strings"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type RoomTypeService interface {
-	GetAll() ([]models.RoomType, error)
+	List(filter admin.RoomTypeListFilter, q utils.ListQuery) (utils.ListPage[models.RoomType], error)
 	Create(roomType models.RoomType) (models.RoomType, error)
-	Update(id string, roomType models.RoomType) error
+	Update(id string, input UpdateRoomTypeInput) error
 	Delete(id string) error
 }
 
+// UpdateRoomTypeInput adalah perubahan sebagian pada room type; field nil
+// tidak diubah. Kebijakan pembatalan dihapus lewat
+// remove_cancellation_policy.
+type UpdateRoomTypeInput struct {
+	Name                     *string                    `json:"name"`
+	Description              *string                    `json:"description"`
+	CancellationPolicy       *models.CancellationPolicy `json:"cancellation_policy"`
+	RemoveCancellationPolicy bool                       `json:"remove_cancellation_policy"`
+}
+
 type roomTypeService struct {
 	repo admin.RoomTypeRepository
 }
@@ -23,17 +37,59 @@ func NewRoomTypeService(repo admin.RoomTypeRepository) RoomTypeService {
 	return &roomTypeService{repo}
 }	
 
-func (s *roomTypeService) GetAll() ([]models.RoomType, error) {
-	return s.repo.GetAll()
+func (s *roomTypeService) List(filter admin.RoomTypeListFilter, q utils.ListQuery) (utils.ListPage[models.RoomType], error) {
+	return s.repo.List(filter, q)
 }
 func (s *roomTypeService) Create(roomType models.RoomType) (models.RoomType, error) {
+	if err := validateCancellationPolicy(roomType.CancellationPolicy); err != nil {
+		return models.RoomType{}, err
+	}
 	id := primitive.NewObjectID()
 	roomType.ID = id
 	return s.repo.Create(roomType)
 }
-func (s *roomTypeService) Update(id string, roomType models.RoomType) error {
-	return s.repo.Update(id, roomType)
+func (s *roomTypeService) Update(id string, input UpdateRoomTypeInput) error {
+	if input.CancellationPolicy != nil && input.RemoveCancellationPolicy {
+		return errors.New("cancellation_policy dan remove_cancellation_policy tidak bisa dipakai bersamaan")
+	}
+	if err := validateCancellationPolicy(input.CancellationPolicy); err != nil {
+		return err
+	}
+
+	patch := admin.RoomTypePatch{
+		Description:              input.Description,
+		CancellationPolicy:       input.CancellationPolicy,
+		RemoveCancellationPolicy: input.RemoveCancellationPolicy,
+	}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return errors.New("name tidak boleh kosong")
+		}
+		patch.Name = &name
+	}
+
+	return s.repo.Update(id, patch)
 }
 func (s *roomTypeService) Delete(id string) error {
 	return s.repo.Delete(id)
-}
\ No newline at end of file
+}
+
+func validateCancellationPolicy(policy *models.CancellationPolicy) error {
+	if policy == nil || policy.NonRefundable {
+		return nil
+	}
+	if policy.FreeUntilDays < 0 {
+		return errors.New("free_until_days tidak boleh negatif")
+	}
+	switch policy.PenaltyType {
+	case constants.PenaltyTypePercent:
+		if policy.PenaltyPercent <= 0 || policy.PenaltyPercent > 100 {
+			return errors.New("penalty_percent harus di antara 0 dan 100")
+		}
+	case constants.PenaltyTypeFirstNight:
+	default:
+		return errors.New("penalty_type harus PERCENT atau FIRST_NIGHT")
+	}
+	return nil
+}
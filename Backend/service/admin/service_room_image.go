@@ -0,0 +1,279 @@
+package admin
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	"astro-backend/repository/admin"
+	"astro-backend/service/media"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrImageNotFound = errors.New("Foto tidak ditemukan")
+
+// NewRoomImage adalah foto yang baru di-upload dan akan ditambahkan ke galeri.
+// Service yang menerimanya bertanggung jawab atas file staging Upload: file
+// diteruskan ke worker atau dihapus kalau request gagal.
+type NewRoomImage struct {
+	Upload  media.Upload
+	Alt     string
+	Caption string
+}
+
+// UpdateRoomImageInput adalah perubahan alt text / caption satu foto; field
+// nil tidak diubah.
+type UpdateRoomImageInput struct {
+	Alt     *string `json:"alt"`
+	Caption *string `json:"caption"`
+}
+
+type RoomImageService interface {
+	AddImages(roomID string, images []NewRoomImage) ([]models.RoomImage, error)
+	UpdateImage(roomID, imageID string, input UpdateRoomImageInput) ([]models.RoomImage, error)
+	DeleteImage(roomID, imageID string) ([]models.RoomImage, error)
+	ReorderImages(roomID string, imageIDs []string) ([]models.RoomImage, error)
+	SetPrimary(roomID, imageID string) ([]models.RoomImage, error)
+}
+
+type roomImageService struct {
+	repo      admin.RoomRepository
+	processor media.ImageProcessor
+}
+
+func NewRoomImageService(repo admin.RoomRepository, processor media.ImageProcessor) RoomImageService {
+	return &roomImageService{repo, processor}
+}
+
+func (s *roomImageService) AddImages(roomID string, images []NewRoomImage) ([]models.RoomImage, error) {
+	if len(images) == 0 {
+		return nil, errors.New("Minimal upload 1 gambar")
+	}
+	return addRoomImages(s.repo, s.processor, roomID, images)
+}
+
+func (s *roomImageService) UpdateImage(roomID, imageID string, input UpdateRoomImageInput) ([]models.RoomImage, error) {
+	room, idx, err := s.findImage(roomID, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Alt != nil {
+		room.Images[idx].Alt = strings.TrimSpace(*input.Alt)
+	}
+	if input.Caption != nil {
+		room.Images[idx].Caption = strings.TrimSpace(*input.Caption)
+	}
+
+	return s.save(room, room.Images)
+}
+
+// DeleteImage menghapus satu foto dari galeri beserta file-nya. Kalau yang
+// dihapus adalah cover, foto pertama yang tersisa jadi cover.
+func (s *roomImageService) DeleteImage(roomID, imageID string) ([]models.RoomImage, error) {
+	room, idx, err := s.findImage(roomID, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := room.Images[idx]
+	images := make([]models.RoomImage, 0, len(room.Images)-1)
+	images = append(images, room.Images[:idx]...)
+	images = append(images, room.Images[idx+1:]...)
+	if removed.Primary && len(images) > 0 {
+		images[0].Primary = true
+	}
+
+	saved, err := s.save(room, images)
+	if err != nil {
+		return nil, err
+	}
+
+	// file dihapus setelah database tersimpan supaya galeri tidak menunjuk
+	// file yang sudah hilang
+	removeRoomImageFile(removed)
+	return saved, nil
+}
+
+// ReorderImages menyusun ulang galeri. imageIDs harus berisi semua foto
+// kamar tepat satu kali.
+func (s *roomImageService) ReorderImages(roomID string, imageIDs []string) ([]models.RoomImage, error) {
+	room, err := s.getRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if len(imageIDs) != len(room.Images) {
+		return nil, errors.New("image_ids harus berisi semua foto kamar")
+	}
+
+	byID := make(map[primitive.ObjectID]models.RoomImage, len(room.Images))
+	for _, img := range room.Images {
+		byID[img.ID] = img
+	}
+
+	images := make([]models.RoomImage, 0, len(imageIDs))
+	for _, id := range imageIDs {
+		oid, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, errors.New("ID foto tidak valid: " + id)
+		}
+		img, ok := byID[oid]
+		if !ok {
+			return nil, errors.New("image_ids harus berisi semua foto kamar tepat satu kali")
+		}
+		delete(byID, oid)
+		images = append(images, img)
+	}
+
+	return s.save(room, images)
+}
+
+func (s *roomImageService) SetPrimary(roomID, imageID string) ([]models.RoomImage, error) {
+	room, idx, err := s.findImage(roomID, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range room.Images {
+		room.Images[i].Primary = i == idx
+	}
+
+	return s.save(room, room.Images)
+}
+
+func (s *roomImageService) getRoom(roomID string) (models.Room, error) {
+	room, err := s.repo.GetByID(roomID)
+	if err != nil {
+		return models.Room{}, ErrRoomNotFound
+	}
+	return room, nil
+}
+
+func (s *roomImageService) findImage(roomID, imageID string) (models.Room, int, error) {
+	room, err := s.getRoom(roomID)
+	if err != nil {
+		return models.Room{}, 0, err
+	}
+
+	oid, err := primitive.ObjectIDFromHex(imageID)
+	if err != nil {
+		return models.Room{}, 0, ErrImageNotFound
+	}
+	for i, img := range room.Images {
+		if img.ID == oid {
+			return room, i, nil
+		}
+	}
+	return models.Room{}, 0, ErrImageNotFound
+}
+
+func (s *roomImageService) save(room models.Room, images []models.RoomImage) ([]models.RoomImage, error) {
+	saved, err := s.repo.UpdateImages(room.Id, images, room.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return saved.Images, nil
+}
+
+// addRoomImages menambahkan foto baru di akhir galeri dengan status
+// PROCESSING lalu mengantrikan pemrosesannya. Kalau galeri belum punya
+// cover, foto baru pertama jadi cover.
+func addRoomImages(repo admin.RoomRepository, processor media.ImageProcessor, roomID string, added []NewRoomImage) ([]models.RoomImage, error) {
+	room, err := repo.GetByID(roomID)
+	if err != nil {
+		discardNewRoomImages(added)
+		return nil, ErrRoomNotFound
+	}
+
+	hasPrimary := false
+	for _, img := range room.Images {
+		hasPrimary = hasPrimary || img.Primary
+	}
+
+	pending := newRoomImages(added, !hasPrimary)
+	images := append(append([]models.RoomImage{}, room.Images...), pending...)
+
+	saved, err := repo.UpdateImages(room.Id, images, room.UpdatedAt)
+	if err != nil {
+		discardNewRoomImages(added)
+		return nil, err
+	}
+
+	failed := queueRoomImages(repo, processor, room.Id, pending, added)
+	for i := range saved.Images {
+		if failed[saved.Images[i].ID] {
+			saved.Images[i].Status = constants.ImageStatusFailed
+		}
+	}
+	return saved.Images, nil
+}
+
+// newRoomImages membuat entri galeri untuk upload baru. Kalau primary true,
+// foto pertama jadi cover.
+func newRoomImages(added []NewRoomImage, primary bool) []models.RoomImage {
+	images := make([]models.RoomImage, 0, len(added))
+	for i, img := range added {
+		images = append(images, models.RoomImage{
+			ID:      primitive.NewObjectID(),
+			Alt:     strings.TrimSpace(img.Alt),
+			Caption: strings.TrimSpace(img.Caption),
+			Primary: primary && i == 0,
+			Status:  constants.ImageStatusProcessing,
+			Width:   img.Upload.Width,
+			Height:  img.Upload.Height,
+		})
+	}
+	return images
+}
+
+// queueRoomImages mengirim upload ke worker setelah galerinya tersimpan.
+// Foto yang tidak kebagian antrian ditandai FAILED supaya admin bisa
+// menghapus dan meng-upload ulang; ID-nya dikembalikan.
+func queueRoomImages(repo admin.RoomRepository, processor media.ImageProcessor, roomID primitive.ObjectID, images []models.RoomImage, added []NewRoomImage) map[primitive.ObjectID]bool {
+	failed := map[primitive.ObjectID]bool{}
+	for i, img := range images {
+		err := processor.Submit(media.ImageJob{RoomID: roomID, ImageID: img.ID, Upload: added[i].Upload})
+		if err == nil {
+			continue
+		}
+
+		log.Warn().Err(err).Str("room_id", roomID.Hex()).Str("image_id", img.ID.Hex()).Msg("gagal mengantrikan foto kamar")
+		media.Discard(added[i].Upload)
+		failed[img.ID] = true
+		if _, err := repo.SetImageResult(roomID, img.ID, models.RoomImage{Status: constants.ImageStatusFailed}); err != nil {
+			log.Error().Err(err).Str("image_id", img.ID.Hex()).Msg("gagal menandai foto kamar gagal diproses")
+		}
+	}
+	return failed
+}
+
+// discardNewRoomImages menghapus file staging upload yang tidak jadi
+// diproses.
+func discardNewRoomImages(added []NewRoomImage) {
+	for _, img := range added {
+		media.Discard(img.Upload)
+	}
+}
+
+// removeRoomImageFile menghapus file foto beserta variannya dari disk. URL
+// "/uploads/rooms/a.png" disimpan di "uploads/rooms/a.png". Gagal hapus
+// cukup dicatat.
+func removeRoomImageFile(img models.RoomImage) {
+	urls := []string{img.URL}
+	if img.Variants != nil {
+		urls = append(urls, img.Variants.Thumbnail, img.Variants.Medium, img.Variants.Large)
+	}
+
+	for _, url := range urls {
+		if url == "" {
+			continue
+		}
+		path := strings.TrimPrefix(url, "/")
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Warn().Err(err).Str("path", path).Msg("gagal hapus file foto kamar")
+		}
+	}
+}
@@ -1,46 +1,96 @@
 package admin
 
 import (
+	"astro-backend/constants"
 	"astro-backend/models"
 	"astro-backend/repository/admin"
+	"astro-backend/repository/auth"
+	"astro-backend/utils"
 	"errors"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"golang.org/x/crypto/bcrypt"
 )
 	
 type UserService interface {
-	CreateUser(user models.User) error
+	CreateUser(input CreateUserInput) error
 	DeleteUser(id string) error
-	UpdateUser(user models.User, id string) error
-	GetAllUsers() ([]models.User, error)
+	UpdateUser(id string, input UpdateUserInput) error
+	List(filter admin.UserListFilter, q utils.ListQuery) (utils.ListPage[models.User], error)
 }
 
-type userService struct {
-	repo admin.UserRepository
+// CreateUserInput adalah data user baru yang dibuat admin. Hanya field ini
+// yang bisa diisi lewat request; status verifikasi, 2FA dan lainnya diatur
+// service. Key JSON sama dengan models.User.
+type CreateUserInput struct {
+	Name     string `json:"Name"`
+	Email    string `json:"Email"`
+	NoTlp    string `json:"NoTlp"`
+	Password string `json:"Password"`
+	Role     string `json:"Role"`
+}
+
+// UpdateUserInput adalah perubahan sebagian pada user; field nil tidak
+// diubah. Key JSON sama dengan models.User.
+type UpdateUserInput struct {
+	Name     *string `json:"Name"`
+	Email    *string `json:"Email"`
+	NoTlp    *string `json:"NoTlp"`
+	Role     *string `json:"Role"`
+	Password *string `json:"Password"`
 }
 
-func (s *userService) GetAllUsers() ([]models.User, error) {
-	return s.repo.GetAllUsers()
+type userService struct {
+	repo        admin.UserRepository
+	sessionRepo auth.SessionRepository
+	refreshRepo auth.RefreshTokenRepository
 }
 
-func NewUserService(repo admin.UserRepository) UserService {
-	return &userService{repo}
+func (s *userService) List(filter admin.UserListFilter, q utils.ListQuery) (utils.ListPage[models.User], error) {
+	return s.repo.List(filter, q)
 }
 
-func (s *userService) CreateUser(user models.User) error {
+func NewUserService(repo admin.UserRepository, sessionRepo auth.SessionRepository, refreshRepo auth.RefreshTokenRepository) UserService {
+	return &userService{repo, sessionRepo, refreshRepo}
+}
 
-	// Set ID + CreatedAt
-	user.Id = primitive.NewObjectID()
-	user.CreatedAt = primitive.NewDateTimeFromTime(time.Now())
+func (s *userService) CreateUser(input CreateUserInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return errors.New("Nama tidak boleh kosong")
+	}
+	email, err := utils.NormalizeEmail(input.Email)
+	if err != nil {
+		return err
+	}
+	if _, ok := constants.RolePermissions[input.Role]; !ok {
+		return errors.New("Role tidak dikenal")
+	}
 
-	// Hash Password
-	hashed, err := bcrypt.GenerateFromPassword([]byte(user.Password), 14)
+	// Validasi + hash password
+	if err := utils.ValidatePassword(input.Password, email, name); err != nil {
+		return err
+	}
+	hashed, err := utils.HashPassword(input.Password)
 	if err != nil {
 		return err
 	}
-	user.Password = string(hashed)
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	// user yang dibuat admin tidak perlu verifikasi email
+	verified := true
+	user := models.User{
+		Id:              primitive.NewObjectID(),
+		Name:            name,
+		Email:           email,
+		NoTlp:           strings.TrimSpace(input.NoTlp),
+		Password:        hashed,
+		Role:            input.Role,
+		CreatedAt:       now,
+		EmailVerified:   &verified,
+		EmailVerifiedAt: &now,
+	}
 
 	// Save to DB via repository
 	return s.repo.Create(user)
@@ -51,7 +101,71 @@ func (s *userService) DeleteUser(id string) error {
 	}
 	return s.repo.Delete(id)
 }
-func (s *userService) UpdateUser(user models.User, id string) error {
-	return s.repo.Update(id, user)
-}
+func (s *userService) UpdateUser(id string, input UpdateUserInput) error {
+	current, err := s.repo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	patch := admin.UserPatch{NoTlp: input.NoTlp}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return errors.New("Nama tidak boleh kosong")
+		}
+		patch.Name = &name
+		current.Name = name
+	}
+	if input.Email != nil {
+		email, err := utils.NormalizeEmail(*input.Email)
+		if err != nil {
+			return err
+		}
+		patch.Email = &email
+		current.Email = email
+	}
+	if input.Role != nil {
+		if _, ok := constants.RolePermissions[*input.Role]; !ok {
+			return errors.New("Role tidak dikenal")
+		}
+		patch.Role = input.Role
+	}
+
+	// password kosong berarti tidak diubah; kalau diisi, divalidasi terhadap
+	// nama dan email setelah perubahan
+	if input.Password != nil && *input.Password != "" {
+		if err := utils.ValidatePassword(*input.Password, current.Email, current.Name); err != nil {
+			return err
+		}
+		hashed, err := utils.HashPassword(*input.Password)
+		if err != nil {
+			return errors.New("gagal hash password")
+		}
+		patch.Password = &hashed
+	}
+
+	if err := s.repo.Update(id, patch); err != nil {
+		return err
+	}
 
+	// sama seperti reset password: sesi lama tidak boleh tetap memakai
+	// kredensial atau hak akses sebelum perubahan
+	reason := ""
+	switch {
+	case patch.Password != nil:
+		reason = "password diubah admin"
+	case patch.Role != nil && *patch.Role != current.Role:
+		reason = "role diubah admin"
+	}
+	if reason != "" {
+		if _, err := s.sessionRepo.RevokeAllByUser(current.Id, reason); err != nil {
+			return err
+		}
+		if err := s.refreshRepo.RevokeAllByUser(current.Id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package admin
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	"astro-backend/repository/admin"
+	"astro-backend/repository/auth"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type stubUserRepo struct {
+	admin.UserRepository
+	user    models.User
+	patch   admin.UserPatch
+	created []models.User
+}
+
+func (r *stubUserRepo) Create(user models.User) error {
+	r.created = append(r.created, user)
+	return nil
+}
+
+func (r *stubUserRepo) FindByID(string) (models.User, error) {
+	return r.user, nil
+}
+
+func (r *stubUserRepo) Update(_ string, patch admin.UserPatch) error {
+	r.patch = patch
+	return nil
+}
+
+type stubSessionRepo struct {
+	auth.SessionRepository
+	revoked []primitive.ObjectID
+}
+
+func (r *stubSessionRepo) RevokeAllByUser(userID primitive.ObjectID, _ string) (int64, error) {
+	r.revoked = append(r.revoked, userID)
+	return 1, nil
+}
+
+type stubRefreshRepo struct {
+	auth.RefreshTokenRepository
+	revoked []primitive.ObjectID
+}
+
+func (r *stubRefreshRepo) RevokeAllByUser(userID primitive.ObjectID) error {
+	r.revoked = append(r.revoked, userID)
+	return nil
+}
+
+func TestUpdateUserRevokesSessions(t *testing.T) {
+	t.Setenv("BCRYPT_COST", "4")
+
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		name   string
+		input  UpdateUserInput
+		revoke bool
+	}{
+		{"ganti password", UpdateUserInput{Password: str("Kamar-Baru#2030")}, true},
+		{"ganti role", UpdateUserInput{Role: str(constants.RoleAdmin)}, true},
+		{"role sama", UpdateUserInput{Role: str(constants.RoleGuest)}, false},
+		{"password kosong", UpdateUserInput{Password: str("")}, false},
+		{"ganti nama", UpdateUserInput{Name: str("Budi Baru")}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := models.User{Id: primitive.NewObjectID(), Name: "Budi", Email: "budi@example.com", Role: constants.RoleGuest}
+			sessions := &stubSessionRepo{}
+			refresh := &stubRefreshRepo{}
+			svc := NewUserService(&stubUserRepo{user: user}, sessions, refresh)
+
+			if err := svc.UpdateUser(user.Id.Hex(), tt.input); err != nil {
+				t.Fatalf("UpdateUser: %v", err)
+			}
+
+			revoked := len(sessions.revoked) == 1 && len(refresh.revoked) == 1
+			if tt.revoke != revoked {
+				t.Fatalf("sesi dicabut = %v (session %d, refresh %d), mau %v", revoked, len(sessions.revoked), len(refresh.revoked), tt.revoke)
+			}
+			if revoked && (sessions.revoked[0] != user.Id || refresh.revoked[0] != user.Id) {
+				t.Fatal("sesi user lain ikut dicabut")
+			}
+			if !tt.revoke && (len(sessions.revoked) != 0 || len(refresh.revoked) != 0) {
+				t.Fatal("sesi tidak boleh dicabut")
+			}
+		})
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	t.Setenv("BCRYPT_COST", "4")
+
+	valid := CreateUserInput{Name: " Budi ", Email: " Budi@Example.COM ", NoTlp: "0812", Password: "Kamar-Baru#2030", Role: constants.RoleAdmin}
+	with := func(fn func(*CreateUserInput)) CreateUserInput {
+		input := valid
+		fn(&input)
+		return input
+	}
+
+	tests := []struct {
+		name    string
+		input   CreateUserInput
+		wantErr bool
+	}{
+		{"valid", valid, false},
+		{"role kosong", with(func(i *CreateUserInput) { i.Role = "" }), true},
+		{"role tidak dikenal", with(func(i *CreateUserInput) { i.Role = "SuperAdmin" }), true},
+		{"email dengan display name", with(func(i *CreateUserInput) { i.Email = "Budi <budi@example.com>" }), true},
+		{"email tidak valid", with(func(i *CreateUserInput) { i.Email = "budi" }), true},
+		{"nama kosong", with(func(i *CreateUserInput) { i.Name = "  " }), true},
+		{"password lemah", with(func(i *CreateUserInput) { i.Password = "123" }), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &stubUserRepo{}
+			svc := NewUserService(repo, &stubSessionRepo{}, &stubRefreshRepo{})
+
+			err := svc.CreateUser(tt.input)
+			if tt.wantErr {
+				if err == nil || len(repo.created) != 0 {
+					t.Fatalf("err %v, %d user tersimpan; want error tanpa user", err, len(repo.created))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateUser: %v", err)
+			}
+
+			user := repo.created[0]
+			if user.Email != "budi@example.com" || user.Name != "Budi" || user.Role != constants.RoleAdmin {
+				t.Fatalf("user tersimpan %q / %q / %q", user.Name, user.Email, user.Role)
+			}
+			if user.Password == tt.input.Password {
+				t.Fatal("password disimpan tanpa hash")
+			}
+			if user.EmailVerified == nil || !*user.EmailVerified || user.TwoFactorEnabled {
+				t.Fatal("status akun harus diatur service")
+			}
+		})
+	}
+}
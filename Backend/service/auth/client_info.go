@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	"astro-backend/service/activityLog"
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ClientInfo berisi data request yang dibutuhkan service auth untuk activity log.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+	Device    string
+	Endpoint  string
+	Method    string
+}
+
+// logSecurity mencatat kejadian auth sebagai log SECURITY.
+func logSecurity(logSvc activityLog.ActivityLogService, client ClientInfo, entry models.ActivityLog) {
+	entry.Category = constants.CategorySecurity
+	logAuthEvent(logSvc, client, entry)
+}
+
+// logAuthEvent mencatat kejadian auth. Kalau Category kosong, kategori
+// ditentukan oleh activityLog service (mis. login gagal -> SECURITY).
+// Kalau logSvc nil (service dibuat tanpa activity log) pemanggilan diabaikan.
+func logAuthEvent(logSvc activityLog.ActivityLogService, client ClientInfo, entry models.ActivityLog) {
+	if logSvc == nil {
+		return
+	}
+
+	entry.IPAddress = client.IP
+	entry.UserAgent = client.UserAgent
+	entry.Endpoint = client.Endpoint
+	entry.Method = client.Method
+	if entry.Resource == "" {
+		entry.Resource = "users"
+	}
+
+	if err := logSvc.Log(context.Background(), entry); err != nil {
+		log.Error().Err(err).Str("action", entry.ActionType).Msg("activity log failed")
+	}
+}
+
+func userRef(user models.User) *primitive.ObjectID {
+	if user.Id.IsZero() {
+		return nil
+	}
+	id := user.Id
+	return &id
+}
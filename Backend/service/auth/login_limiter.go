@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// ipLimiter menghitung login gagal per IP dalam jendela waktu tetap.
+// Disimpan di memori, jadi hitungan hilang saat server restart.
+type ipLimiter struct {
+	mu          sync.Mutex
+	maxAttempts int
+	window      time.Duration
+	entries     map[string]*ipAttempts
+}
+
+type ipAttempts struct {
+	count       int
+	windowStart time.Time
+}
+
+func newIPLimiter(maxAttempts int, window time.Duration) *ipLimiter {
+	return &ipLimiter{
+		maxAttempts: maxAttempts,
+		window:      window,
+		entries:     map[string]*ipAttempts{},
+	}
+}
+
+// Allow mengembalikan false dan sisa waktu blokir kalau IP sudah melewati batas.
+func (l *ipLimiter) Allow(ip string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.entries[ip]
+	if !ok {
+		return true, 0
+	}
+
+	elapsed := time.Since(e.windowStart)
+	if elapsed >= l.window {
+		delete(l.entries, ip)
+		return true, 0
+	}
+
+	if e.count >= l.maxAttempts {
+		return false, l.window - elapsed
+	}
+	return true, 0
+}
+
+// Fail menambah hitungan gagal untuk IP.
+func (l *ipLimiter) Fail(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	e, ok := l.entries[ip]
+	if !ok || now.Sub(e.windowStart) >= l.window {
+		l.entries[ip] = &ipAttempts{count: 1, windowStart: now}
+		l.cleanup(now)
+		return
+	}
+	e.count++
+}
+
+// cleanup membuang entry yang jendelanya sudah lewat supaya map tidak terus membesar.
+func (l *ipLimiter) cleanup(now time.Time) {
+	for ip, e := range l.entries {
+		if now.Sub(e.windowStart) >= l.window {
+			delete(l.entries, ip)
+		}
+	}
+}
+
+// lockoutDuration menghitung lama akun dikunci: mulai dari base setelah
+// threshold tercapai, lalu berlipat dua untuk tiap kegagalan berikutnya.
+func lockoutDuration(failedAttempts, threshold int, base, max time.Duration) time.Duration {
+	if failedAttempts < threshold {
+		return 0
+	}
+
+	d := base
+	for i := threshold; i < failedAttempts && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIPLimiter(t *testing.T) {
+	l := newIPLimiter(3, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("10.0.0.1"); !ok {
+			t.Fatalf("percobaan ke-%d diblokir sebelum batas", i+1)
+		}
+		l.Fail("10.0.0.1")
+	}
+
+	ok, retryAfter := l.Allow("10.0.0.1")
+	if ok {
+		t.Fatal("IP harus diblokir setelah 3 kali gagal")
+	}
+	if retryAfter <= 0 || retryAfter > time.Minute {
+		t.Fatalf("retry after %v, want di antara 0 dan 1 menit", retryAfter)
+	}
+
+	if ok, _ := l.Allow("10.0.0.2"); !ok {
+		t.Fatal("IP lain ikut diblokir")
+	}
+
+	// jendela lewat: IP boleh mencoba lagi dan hitungan mulai dari awal
+	l.entries["10.0.0.1"].windowStart = time.Now().Add(-time.Minute)
+	if ok, _ := l.Allow("10.0.0.1"); !ok {
+		t.Fatal("IP masih diblokir setelah jendela lewat")
+	}
+	l.Fail("10.0.0.1")
+	if got := l.entries["10.0.0.1"].count; got != 1 {
+		t.Fatalf("hitungan setelah jendela baru %d, want 1", got)
+	}
+}
+
+func TestIPLimiterCleanup(t *testing.T) {
+	l := newIPLimiter(3, time.Minute)
+	l.Fail("10.0.0.1")
+	l.Fail("10.0.0.2")
+	l.entries["10.0.0.1"].windowStart = time.Now().Add(-2 * time.Minute)
+
+	// entry baru memicu pembersihan entry yang jendelanya sudah lewat
+	l.Fail("10.0.0.3")
+	if _, ok := l.entries["10.0.0.1"]; ok {
+		t.Fatal("entry kedaluwarsa tidak dibersihkan")
+	}
+	if len(l.entries) != 2 {
+		t.Fatalf("%d entry, want 2", len(l.entries))
+	}
+}
+
+func TestLockoutDuration(t *testing.T) {
+	base, max := time.Minute, time.Hour
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 0},
+		{4, 0},
+		{5, time.Minute},
+		{6, 2 * time.Minute},
+		{7, 4 * time.Minute},
+		{10, 32 * time.Minute},
+		{11, time.Hour},
+		{100, time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := lockoutDuration(tt.attempts, 5, base, max); got != tt.want {
+			t.Errorf("lockoutDuration(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	authRepo "astro-backend/repository/auth"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// apiKeyPrefix menandai key milik aplikasi ini supaya mudah dikenali kalau bocor.
+const apiKeyPrefix = "astro_"
+
+var ErrInvalidAPIKey = errors.New("API key tidak valid")
+
+type APIKeyService interface {
+	Create(name string, scopes []string, expiresAt *time.Time, createdBy *primitive.ObjectID) (models.APIKey, string, error)
+	GetAll() ([]models.APIKey, error)
+	Rotate(id string) (models.APIKey, string, error)
+	Revoke(id string) error
+	Authenticate(rawKey, scope string) (models.APIKey, error)
+}
+
+type apiKeyService struct {
+	repo authRepo.APIKeyRepository
+}
+
+func NewAPIKeyService(repo authRepo.APIKeyRepository) APIKeyService {
+	return &apiKeyService{repo}
+}
+
+// Create membuat API key baru. Key mentah dikembalikan sekali ini saja.
+func (s *apiKeyService) Create(name string, scopes []string, expiresAt *time.Time, createdBy *primitive.ObjectID) (models.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return models.APIKey{}, "", errors.New("Nama API key wajib diisi")
+	}
+	if len(scopes) == 0 {
+		return models.APIKey{}, "", errors.New("Minimal satu scope")
+	}
+	for _, scope := range scopes {
+		if !isKnownScope(scope) {
+			return models.APIKey{}, "", errors.New("scope tidak dikenal: " + scope)
+		}
+	}
+	if expiresAt != nil && expiresAt.Before(time.Now()) {
+		return models.APIKey{}, "", errors.New("expires_at harus di masa depan")
+	}
+
+	raw, err := newAPIKey()
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+
+	key := models.APIKey{
+		Id:        primitive.NewObjectID(),
+		Name:      name,
+		Prefix:    raw[:len(apiKeyPrefix)+8],
+		KeyHash:   hashToken(raw),
+		Scopes:    scopes,
+		CreatedBy: createdBy,
+		CreatedAt: primitive.NewDateTimeFromTime(time.Now()),
+	}
+	if expiresAt != nil {
+		exp := primitive.NewDateTimeFromTime(*expiresAt)
+		key.ExpiresAt = &exp
+	}
+
+	if err := s.repo.Create(key); err != nil {
+		return models.APIKey{}, "", err
+	}
+
+	return key, raw, nil
+}
+
+func (s *apiKeyService) GetAll() ([]models.APIKey, error) {
+	return s.repo.GetAll()
+}
+
+// Rotate mengganti key mentah tanpa mengubah nama, scope, dan masa berlaku.
+// Key lama langsung tidak berlaku.
+func (s *apiKeyService) Rotate(id string) (models.APIKey, string, error) {
+	key, err := s.repo.FindByID(id)
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+
+	raw, err := newAPIKey()
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+
+	key.Prefix = raw[:len(apiKeyPrefix)+8]
+	key.KeyHash = hashToken(raw)
+	if err := s.repo.UpdateKey(key.Id, key.Prefix, key.KeyHash); err != nil {
+		return models.APIKey{}, "", err
+	}
+
+	return key, raw, nil
+}
+
+func (s *apiKeyService) Revoke(id string) error {
+	key, err := s.repo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	return s.repo.Revoke(key.Id)
+}
+
+// Authenticate memvalidasi key mentah dan memastikan key punya scope yang diminta.
+func (s *apiKeyService) Authenticate(rawKey, scope string) (models.APIKey, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return models.APIKey{}, ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.FindByHash(hashToken(rawKey))
+	if err != nil {
+		return models.APIKey{}, ErrInvalidAPIKey
+	}
+	if key.RevokedAt != nil {
+		return models.APIKey{}, errors.New("API key sudah dicabut")
+	}
+	if key.ExpiresAt != nil && key.ExpiresAt.Time().Before(time.Now()) {
+		return models.APIKey{}, errors.New("API key sudah kedaluwarsa")
+	}
+
+	allowed := false
+	for _, sc := range key.Scopes {
+		if sc == scope {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return key, errors.New("API key tidak punya scope " + scope)
+	}
+
+	_ = s.repo.TouchLastUsed(key.Id)
+	return key, nil
+}
+
+func isKnownScope(scope string) bool {
+	for _, sc := range constants.APIKeyScopes {
+		if sc == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func newAPIKey() (string, error) {
+	raw, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + raw, nil
+}
@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	authRepo "astro-backend/repository/auth"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memAPIKeys meniru APIKeyRepository di memori.
+type memAPIKeys struct {
+	authRepo.APIKeyRepository
+	keys    []models.APIKey
+	touched []primitive.ObjectID
+}
+
+func (m *memAPIKeys) Create(key models.APIKey) error {
+	m.keys = append(m.keys, key)
+	return nil
+}
+
+func (m *memAPIKeys) FindByID(id string) (models.APIKey, error) {
+	for _, key := range m.keys {
+		if key.Id.Hex() == id {
+			return key, nil
+		}
+	}
+	return models.APIKey{}, errors.New("API key tidak ditemukan")
+}
+
+func (m *memAPIKeys) FindByHash(hash string) (models.APIKey, error) {
+	for _, key := range m.keys {
+		if key.KeyHash == hash {
+			return key, nil
+		}
+	}
+	return models.APIKey{}, errors.New("API key tidak ditemukan")
+}
+
+func (m *memAPIKeys) UpdateKey(id primitive.ObjectID, prefix, hash string) error {
+	for i := range m.keys {
+		if m.keys[i].Id == id {
+			m.keys[i].Prefix, m.keys[i].KeyHash = prefix, hash
+		}
+	}
+	return nil
+}
+
+func (m *memAPIKeys) Revoke(id primitive.ObjectID) error {
+	now := primitive.NewDateTimeFromTime(time.Now())
+	for i := range m.keys {
+		if m.keys[i].Id == id {
+			m.keys[i].RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *memAPIKeys) TouchLastUsed(id primitive.ObjectID) error {
+	m.touched = append(m.touched, id)
+	return nil
+}
+
+func TestAPIKeyAuthenticateScopes(t *testing.T) {
+	granted, other := constants.ScopeActivityLogsRead, constants.ScopeActivityLogsExport
+
+	repo := &memAPIKeys{}
+	svc := NewAPIKeyService(repo)
+	key, raw, err := svc.Create("Channel manager", []string{granted}, nil, nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !strings.HasPrefix(raw, apiKeyPrefix) || !strings.HasPrefix(raw, key.Prefix) {
+		t.Fatalf("key %q, prefix %q", raw, key.Prefix)
+	}
+	if key.KeyHash == raw || repo.keys[0].KeyHash != hashToken(raw) {
+		t.Fatal("key harus disimpan dalam bentuk hash")
+	}
+
+	tests := []struct {
+		name    string
+		raw     string
+		scope   string
+		wantErr bool
+	}{
+		{"scope dimiliki", raw, granted, false},
+		{"scope lain", raw, other, true},
+		{"scope kosong", raw, "", true},
+		{"scope tidak dikenal", raw, "admin:*", true},
+		{"tanpa prefix", strings.TrimPrefix(raw, apiKeyPrefix), granted, true},
+		{"key tidak terdaftar", apiKeyPrefix + "tidak-ada", granted, true},
+		{"kosong", "", granted, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			touched := len(repo.touched)
+			_, err := svc.Authenticate(tt.raw, tt.scope)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err %v, wantErr %v", err, tt.wantErr)
+			}
+			// last_used_at hanya diperbarui untuk request yang diizinkan
+			if used := len(repo.touched) > touched; used == tt.wantErr {
+				t.Fatalf("last_used_at diperbarui = %v", used)
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuthenticateLifecycle(t *testing.T) {
+	scope := constants.ScopeActivityLogsRead
+	repo := &memAPIKeys{}
+	svc := NewAPIKeyService(repo)
+
+	key, raw, err := svc.Create("Laporan", []string{scope}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, rotated, err := svc.Rotate(key.Id.Hex())
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if _, err := svc.Authenticate(raw, scope); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("key lama setelah rotate: err %v, want ErrInvalidAPIKey", err)
+	}
+	if _, err := svc.Authenticate(rotated, scope); err != nil {
+		t.Fatalf("key baru setelah rotate: %v", err)
+	}
+
+	if err := svc.Revoke(key.Id.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Authenticate(rotated, scope); err == nil {
+		t.Fatal("key yang dicabut diterima")
+	}
+
+	_, expiring, err := svc.Create("Sementara", []string{scope}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	past := primitive.NewDateTimeFromTime(time.Now().Add(-time.Minute))
+	repo.keys[len(repo.keys)-1].ExpiresAt = &past
+	if _, err := svc.Authenticate(expiring, scope); err == nil {
+		t.Fatal("key yang kedaluwarsa diterima")
+	}
+}
+
+func TestAPIKeyCreateValidation(t *testing.T) {
+	scope := constants.ScopeActivityLogsRead
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		keyName   string
+		scopes    []string
+		expiresAt *time.Time
+	}{
+		{"nama kosong", "  ", []string{scope}, nil},
+		{"tanpa scope", "Integrasi", nil, nil},
+		{"scope tidak dikenal", "Integrasi", []string{scope, "root"}, nil},
+		{"kedaluwarsa di masa lalu", "Integrasi", []string{scope}, &past},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memAPIKeys{}
+			if _, _, err := NewAPIKeyService(repo).Create(tt.keyName, tt.scopes, tt.expiresAt, nil); err == nil {
+				t.Fatal("Create harus gagal")
+			}
+			if len(repo.keys) != 0 {
+				t.Fatal("key tidak valid tersimpan")
+			}
+		})
+	}
+}
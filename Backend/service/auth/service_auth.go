@@ -1,35 +1,416 @@
 package auth
 
 import (
+	"astro-backend/constants"
 	"astro-backend/models"
 	adminRepo "astro-backend/repository/admin" // alias agar tidak tabrakan
+	authRepo "astro-backend/repository/auth"
+	"astro-backend/service/activityLog"
+	"astro-backend/utils"
 	"errors"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	// ErrInvalidCredentials sengaja dipakai untuk email tidak ditemukan,
+	// password salah dan akun terkunci supaya akun tidak bisa ditebak.
+	ErrInvalidCredentials = errors.New("Email atau password salah")
+	ErrTooManyAttempts    = errors.New("Terlalu banyak percobaan login, coba lagi nanti")
+	ErrEmailNotVerified   = errors.New("Email belum diverifikasi")
+
+	ErrInvalidTwoFactorCode = errors.New("Kode 2FA salah")
+)
+
+// dummyHash dipakai saat email tidak ditemukan atau akun terkunci supaya
+// waktu respons sama dengan saat password salah. Dibuat saat pertama dipakai
+// karena BCRYPT_COST baru terbaca setelah .env dimuat.
+var (
+	dummyHash     []byte
+	dummyHashOnce sync.Once
+)
+
+// compareDummyPassword menjalankan bcrypt dengan cost yang sama seperti hash
+// user asli, lalu membuang hasilnya.
+func compareDummyPassword(password string) {
+	dummyHashOnce.Do(func() {
+		hashed, err := utils.HashPassword("astro-dummy-password")
+		if err != nil {
+			log.Error().Err(err).Msg("gagal membuat dummy hash")
+			return
+		}
+		dummyHash = []byte(hashed)
+	})
+	_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
+
+// LoginResult adalah hasil login tahap pertama. Kalau TwoFactorRequired atau
+// TwoFactorSetupRequired bernilai true, Tokens kosong dan client harus
+// melanjutkan memakai ChallengeToken.
+type LoginResult struct {
+	User                   models.User
+	Tokens                 TokenPair
+	TwoFactorRequired      bool
+	TwoFactorSetupRequired bool
+	ChallengeToken         string
+}
+
 type AuthService interface {
-	Login(email, password string) (models.User, error)
+	Login(email, password string, client ClientInfo) (LoginResult, error)
+	VerifyTwoFactor(challengeToken, code, recoveryCode string, client ClientInfo) (models.User, TokenPair, error)
+	CompleteLogin(user models.User, client ClientInfo) (TokenPair, error)
+	Refresh(refreshToken string, client ClientInfo) (TokenPair, error)
+	Authenticate(token string, allowedTypes ...string) (models.User, *Claims, error)
+}
+
+type loginPolicy struct {
+	lockoutThreshold int
+	lockoutBase      time.Duration
+	lockoutMax       time.Duration
 }
 
 type authService struct {
-	repo adminRepo.UserRepository
+	repo        adminRepo.UserRepository
+	refreshRepo authRepo.RefreshTokenRepository
+	sessionRepo authRepo.SessionRepository
+	settingRepo authRepo.SecuritySettingRepository
+	logSvc      activityLog.ActivityLogService
+	ipLimiter   *ipLimiter
+	policy      loginPolicy
 }
 
-func NewAuthService(repo adminRepo.UserRepository) AuthService {
-	return &authService{repo}
+func NewAuthService(repo adminRepo.UserRepository, refreshRepo authRepo.RefreshTokenRepository, sessionRepo authRepo.SessionRepository, settingRepo authRepo.SecuritySettingRepository, logSvc activityLog.ActivityLogService) AuthService {
+	return &authService{
+		repo:        repo,
+		refreshRepo: refreshRepo,
+		sessionRepo: sessionRepo,
+		settingRepo: settingRepo,
+		logSvc:      logSvc,
+		ipLimiter: newIPLimiter(
+			parseIntEnv("LOGIN_IP_MAX_ATTEMPTS", 20),
+			parseDurationEnv("LOGIN_IP_WINDOW", 15*time.Minute),
+		),
+		policy: loginPolicy{
+			lockoutThreshold: parseIntEnv("LOGIN_LOCKOUT_THRESHOLD", 5),
+			lockoutBase:      parseDurationEnv("LOGIN_LOCKOUT_BASE", time.Minute),
+			lockoutMax:       parseDurationEnv("LOGIN_LOCKOUT_MAX", time.Hour),
+		},
+	}
 }
 
-func (s *authService) Login(Email, password string) (models.User, error) {
+func (s *authService) Login(Email, password string, client ClientInfo) (LoginResult, error) {
+	// email disimpan lowercase sejak registrasi
+	Email = strings.ToLower(strings.TrimSpace(Email))
+
+	if ok, retryAfter := s.ipLimiter.Allow(client.IP); !ok {
+		s.logLoginFailed(models.User{}, Email, client, "IP diblokir sementara", primitive.M{
+			"suspicious":  true,
+			"retry_after": int64(retryAfter.Seconds()),
+		})
+		return LoginResult{}, ErrTooManyAttempts
+	}
+
 	user, err := s.repo.FindByEmail(Email)
 	if err != nil {
-		return user, errors.New("Email tidak ditemukan")
+		compareDummyPassword(password)
+		s.ipLimiter.Fail(client.IP)
+		s.logLoginFailed(models.User{}, Email, client, "email tidak terdaftar", nil)
+		return LoginResult{}, ErrInvalidCredentials
+	}
+
+	if isLocked(user) {
+		compareDummyPassword(password)
+		s.ipLimiter.Fail(client.IP)
+		s.logLoginFailed(user, Email, client, "akun terkunci", primitive.M{"locked_until": user.LockedUntil.Time()})
+		return LoginResult{}, ErrInvalidCredentials
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		return user, errors.New("Password salah")
+		s.ipLimiter.Fail(client.IP)
+		s.registerFailedAttempt(user, Email, client, "password salah")
+		return LoginResult{}, ErrInvalidCredentials
+	}
+
+	// upgrade hash lama kalau cost bcrypt sudah diganti; gagal di sini tidak
+	// membatalkan login
+	if utils.PasswordNeedsRehash(user.Password) {
+		if hashed, err := utils.HashPassword(password); err == nil {
+			if err := s.repo.RehashPassword(user.Id, user.Password, hashed); err != nil {
+				log.Warn().Err(err).Str("user_id", user.Id.Hex()).Msg("gagal rehash password")
+			}
+		}
+	}
+
+	if !isEmailVerified(user) {
+		return LoginResult{}, ErrEmailNotVerified
+	}
+
+	// password benar, lanjut ke langkah kedua kalau 2FA aktif / diwajibkan
+	if user.TwoFactorEnabled {
+		challenge, err := issueChallengeToken(user, TokenTypeMFA)
+		if err != nil {
+			return LoginResult{}, err
+		}
+		return LoginResult{User: user, TwoFactorRequired: true, ChallengeToken: challenge}, nil
+	}
+
+	if roleRequiresTwoFactor(s.settingRepo, user.Role) {
+		challenge, err := issueChallengeToken(user, TokenTypeMFASetup)
+		if err != nil {
+			return LoginResult{}, err
+		}
+		return LoginResult{User: user, TwoFactorSetupRequired: true, ChallengeToken: challenge}, nil
+	}
+
+	tokens, err := s.CompleteLogin(user, client)
+	if err != nil {
+		return LoginResult{}, err
+	}
+
+	return LoginResult{User: user, Tokens: tokens}, nil
+}
+
+// VerifyTwoFactor adalah langkah kedua login untuk user dengan 2FA aktif.
+// Kode salah dihitung sebagai login gagal, sama seperti password salah.
+func (s *authService) VerifyTwoFactor(challengeToken, code, recoveryCode string, client ClientInfo) (models.User, TokenPair, error) {
+	if ok, _ := s.ipLimiter.Allow(client.IP); !ok {
+		return models.User{}, TokenPair{}, ErrTooManyAttempts
+	}
+
+	claims, err := parseToken(challengeToken, TokenTypeMFA)
+	if err != nil {
+		return models.User{}, TokenPair{}, err
+	}
+
+	user, err := s.repo.FindByID(claims.Subject)
+	if err != nil || !user.TwoFactorEnabled {
+		return models.User{}, TokenPair{}, ErrInvalidCredentials
 	}
 
-	return user, nil
+	if isLocked(user) {
+		s.logLoginFailed(user, user.Email, client, "akun terkunci", nil)
+		return models.User{}, TokenPair{}, ErrInvalidCredentials
+	}
+
+	ok, err := verifySecondFactor(s.repo, user, code, recoveryCode)
+	if err != nil {
+		return models.User{}, TokenPair{}, err
+	}
+	if !ok {
+		s.ipLimiter.Fail(client.IP)
+		s.registerFailedAttempt(user, user.Email, client, "kode 2FA salah")
+		return models.User{}, TokenPair{}, ErrInvalidTwoFactorCode
+	}
+
+	tokens, err := s.CompleteLogin(user, client)
+	if err != nil {
+		return models.User{}, TokenPair{}, err
+	}
+
+	return user, tokens, nil
+}
+
+// CompleteLogin menerbitkan pasangan token untuk user yang sudah lolos semua
+// langkah autentikasi, lalu mencatat login berhasil.
+func (s *authService) CompleteLogin(user models.User, client ClientInfo) (TokenPair, error) {
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		if err := s.repo.ResetFailedLogin(user.Id); err != nil {
+			return TokenPair{}, err
+		}
+	}
+
+	now := time.Now()
+	session := models.Session{
+		Id:         primitive.NewObjectID(),
+		UserID:     user.Id,
+		Device:     client.Device,
+		IPAddress:  client.IP,
+		UserAgent:  client.UserAgent,
+		CreatedAt:  primitive.NewDateTimeFromTime(now),
+		LastSeenAt: primitive.NewDateTimeFromTime(now),
+		ExpiresAt:  primitive.NewDateTimeFromTime(now.Add(loadTokenConfig().refreshTTL)),
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return TokenPair{}, err
+	}
+
+	tokens, err := issueTokenPair(s.refreshRepo, user, session.Id, primitive.NewObjectID())
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	logAuthEvent(s.logSvc, client, models.ActivityLog{
+		UserID:     userRef(user),
+		UserEmail:  user.Email,
+		SessionID:  session.Id.Hex(),
+		ActionType: constants.ActLogin,
+		ResourceID: user.Id.Hex(),
+		Message:    "login berhasil",
+		Status:     constants.StatusSuccess,
+	})
+
+	return tokens, nil
+}
+
+// registerFailedAttempt menambah hitungan gagal dan mengunci akun secara
+// progresif setelah threshold tercapai.
+func (s *authService) registerFailedAttempt(user models.User, email string, client ClientInfo, reason string) {
+	attempts, err := s.repo.IncrementFailedLogin(user.Id)
+	if err != nil {
+		s.logLoginFailed(user, email, client, reason, nil)
+		return
+	}
+
+	metadata := primitive.M{"failed_attempts": attempts}
+	if d := lockoutDuration(attempts, s.policy.lockoutThreshold, s.policy.lockoutBase, s.policy.lockoutMax); d > 0 {
+		until := time.Now().Add(d)
+		if err := s.repo.LockUntil(user.Id, until); err == nil {
+			metadata["locked_until"] = until
+		}
+	}
+
+	s.logLoginFailed(user, email, client, reason, metadata)
+}
+
+// logLoginFailed mencatat login gagal dengan ActLogin + StatusFailed, yang
+// oleh categorize dimasukkan ke kategori SECURITY.
+func (s *authService) logLoginFailed(user models.User, email string, client ClientInfo, reason string, metadata primitive.M) {
+	logAuthEvent(s.logSvc, client, models.ActivityLog{
+		UserID:     userRef(user),
+		UserEmail:  email,
+		ActionType: constants.ActLogin,
+		Message:    "login gagal: " + reason,
+		Metadata:   metadata,
+		Status:     constants.StatusFailed,
+	})
+}
+
+// Refresh menukar refresh token yang masih berlaku dengan pasangan token baru.
+// Refresh token lama langsung dicabut; kalau token yang sudah dicabut dipakai
+// lagi, sesi tersebut ikut dicabut karena token kemungkinan bocor.
+func (s *authService) Refresh(refreshToken string, client ClientInfo) (TokenPair, error) {
+	claims, err := parseToken(refreshToken, TokenTypeRefresh)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	stored, err := s.refreshRepo.FindByHash(hashToken(claims.ID))
+	if err != nil {
+		return TokenPair{}, errors.New("refresh token tidak valid")
+	}
+
+	if stored.RevokedAt != nil {
+		_ = s.refreshRepo.RevokeAllByUser(stored.UserID)
+		if !stored.SessionID.IsZero() {
+			_ = s.sessionRepo.Revoke(stored.SessionID, "refresh token dipakai ulang")
+		}
+		return TokenPair{}, errors.New("refresh token sudah digunakan")
+	}
+	if stored.ExpiresAt.Time().Before(time.Now()) {
+		return TokenPair{}, errors.New("refresh token sudah kedaluwarsa")
+	}
+
+	session, err := s.activeSession(stored.SessionID)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	user, err := s.repo.FindByID(stored.UserID.Hex())
+	if err != nil {
+		return TokenPair{}, errors.New("user tidak ditemukan")
+	}
+	if issuedBeforePasswordChange(user, claims) {
+		_ = s.refreshRepo.Revoke(stored.Id, nil)
+		return TokenPair{}, errors.New("refresh token tidak berlaku karena password sudah diganti")
+	}
+
+	newID := primitive.NewObjectID()
+	if err := s.refreshRepo.Revoke(stored.Id, &newID); err != nil {
+		return TokenPair{}, err
+	}
+
+	expiresAt := time.Now().Add(loadTokenConfig().refreshTTL)
+	if err := s.sessionRepo.Touch(session.Id, client.IP, &expiresAt); err != nil {
+		return TokenPair{}, err
+	}
+
+	return issueTokenPair(s.refreshRepo, user, session.Id, newID)
+}
+
+// Authenticate memvalidasi token dan memastikan user-nya masih ada. Secara
+// default hanya access token yang diterima; allowedTypes dipakai untuk route
+// khusus seperti pendaftaran 2FA yang juga menerima token mfa_setup.
+// Access token juga harus milik sesi yang belum dicabut.
+func (s *authService) Authenticate(token string, allowedTypes ...string) (models.User, *Claims, error) {
+	if len(allowedTypes) == 0 {
+		allowedTypes = []string{TokenTypeAccess}
+	}
+
+	claims, err := parseToken(token, allowedTypes...)
+	if err != nil {
+		return models.User{}, nil, err
+	}
+
+	if claims.Type == TokenTypeAccess {
+		sessionID, err := primitive.ObjectIDFromHex(claims.SessionID)
+		if err != nil {
+			return models.User{}, nil, errors.New("token tidak berlaku, silakan login ulang")
+		}
+
+		session, err := s.activeSession(sessionID)
+		if err != nil {
+			return models.User{}, nil, err
+		}
+
+		// last_seen_at cukup diperbarui paling sering sekali per menit
+		if time.Since(session.LastSeenAt.Time()) > time.Minute {
+			_ = s.sessionRepo.Touch(session.Id, "", nil)
+		}
+	}
+
+	user, err := s.repo.FindByID(claims.Subject)
+	if err != nil {
+		return models.User{}, nil, errors.New("user tidak ditemukan")
+	}
+
+	if issuedBeforePasswordChange(user, claims) {
+		return models.User{}, nil, errors.New("token tidak berlaku, silakan login ulang")
+	}
+
+	return user, claims, nil
+}
+
+func (s *authService) activeSession(id primitive.ObjectID) (models.Session, error) {
+	session, err := s.sessionRepo.FindByID(id)
+	if err != nil {
+		return session, errors.New("sesi tidak ditemukan, silakan login ulang")
+	}
+	if session.RevokedAt != nil {
+		return session, errors.New("sesi sudah berakhir, silakan login ulang")
+	}
+	if session.ExpiresAt.Time().Before(time.Now()) {
+		return session, errors.New("sesi sudah kedaluwarsa, silakan login ulang")
+	}
+	return session, nil
+}
+
+// issuedBeforePasswordChange bernilai true kalau token diterbitkan sebelum
+// password terakhir diganti. iat JWT hanya presisi detik, jadi waktu
+// perubahan password dibulatkan ke bawah.
+func issuedBeforePasswordChange(user models.User, claims *Claims) bool {
+	if user.PasswordChangedAt == nil || claims.IssuedAt == nil {
+		return false
+	}
+	changedAt := user.PasswordChangedAt.Time().Truncate(time.Second)
+	return claims.IssuedAt.Time.Before(changedAt)
+}
+
+func isLocked(user models.User) bool {
+	return user.LockedUntil != nil && user.LockedUntil.Time().After(time.Now())
 }
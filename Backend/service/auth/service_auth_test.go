@@ -0,0 +1,538 @@
+package auth
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	authRepo "astro-backend/repository/auth"
+	"astro-backend/utils"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memSessions meniru SessionRepository di memori.
+type memSessions struct {
+	authRepo.SessionRepository
+	mu       sync.Mutex
+	sessions map[primitive.ObjectID]models.Session
+}
+
+func (m *memSessions) Create(session models.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.Id] = session
+	return nil
+}
+
+func (m *memSessions) FindByID(id primitive.ObjectID) (models.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[id]
+	if !ok {
+		return models.Session{}, errors.New("sesi tidak ditemukan")
+	}
+	return session, nil
+}
+
+func (m *memSessions) Touch(id primitive.ObjectID, ip string, expiresAt *time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session := m.sessions[id]
+	session.LastSeenAt = primitive.NewDateTimeFromTime(time.Now())
+	if expiresAt != nil {
+		session.ExpiresAt = primitive.NewDateTimeFromTime(*expiresAt)
+	}
+	m.sessions[id] = session
+	return nil
+}
+
+func (m *memSessions) Revoke(id primitive.ObjectID, reason string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.revoke(id, reason)
+	return nil
+}
+
+func (m *memSessions) RevokeAllByUser(userID primitive.ObjectID, reason string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for id, session := range m.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			m.revoke(id, reason)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *memSessions) revoke(id primitive.ObjectID, reason string) {
+	session := m.sessions[id]
+	now := primitive.NewDateTimeFromTime(time.Now())
+	session.RevokedAt, session.RevokedReason = &now, reason
+	m.sessions[id] = session
+}
+
+// memRefreshTokens meniru RefreshTokenRepository di memori, termasuk Revoke
+// yang hanya berhasil sekali per token.
+type memRefreshTokens struct {
+	authRepo.RefreshTokenRepository
+	mu     sync.Mutex
+	tokens map[string]models.RefreshToken
+}
+
+func (m *memRefreshTokens) Create(token models.RefreshToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (m *memRefreshTokens) FindByHash(hash string) (models.RefreshToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	token, ok := m.tokens[hash]
+	if !ok {
+		return models.RefreshToken{}, errors.New("refresh token tidak ditemukan")
+	}
+	return token, nil
+}
+
+func (m *memRefreshTokens) Revoke(id primitive.ObjectID, replacedBy *primitive.ObjectID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for hash, token := range m.tokens {
+		if token.Id == id && token.RevokedAt == nil {
+			now := primitive.NewDateTimeFromTime(time.Now())
+			token.RevokedAt, token.ReplacedBy = &now, replacedBy
+			m.tokens[hash] = token
+			return nil
+		}
+	}
+	return errors.New("refresh token sudah tidak berlaku")
+}
+
+func (m *memRefreshTokens) RevokeAllByUser(userID primitive.ObjectID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for hash, token := range m.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			now := primitive.NewDateTimeFromTime(time.Now())
+			token.RevokedAt = &now
+			m.tokens[hash] = token
+		}
+	}
+	return nil
+}
+
+// stored mengembalikan data refresh token yang tersimpan untuk token mentah.
+func (m *memRefreshTokens) stored(t *testing.T, raw string) models.RefreshToken {
+	t.Helper()
+	claims, err := parseToken(raw, TokenTypeRefresh)
+	if err != nil {
+		t.Fatalf("parse refresh token: %v", err)
+	}
+	token, err := m.FindByHash(hashToken(claims.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func (m *memUsers) IncrementFailedLogin(id primitive.ObjectID) (int, error) {
+	attempts := 0
+	m.update(id, func(u *models.User) {
+		u.FailedLoginAttempts++
+		attempts = u.FailedLoginAttempts
+	})
+	return attempts, nil
+}
+
+func (m *memUsers) LockUntil(id primitive.ObjectID, until time.Time) error {
+	lockedUntil := primitive.NewDateTimeFromTime(until)
+	m.update(id, func(u *models.User) { u.LockedUntil = &lockedUntil })
+	return nil
+}
+
+func (m *memUsers) ResetFailedLogin(id primitive.ObjectID) error {
+	m.update(id, func(u *models.User) { u.FailedLoginAttempts, u.LockedUntil = 0, nil })
+	return nil
+}
+
+type authFixture struct {
+	svc      *authService
+	users    *memUsers
+	sessions *memSessions
+	refresh  *memRefreshTokens
+	user     models.User
+}
+
+func newAuthFixture(t *testing.T) *authFixture {
+	t.Helper()
+
+	user := models.User{Id: primitive.NewObjectID(), Name: "Budi", Email: "budi@example.com", Role: constants.RoleGuest}
+	f := &authFixture{
+		users:    &memUsers{users: []models.User{user}},
+		sessions: &memSessions{sessions: map[primitive.ObjectID]models.Session{}},
+		refresh:  &memRefreshTokens{tokens: map[string]models.RefreshToken{}},
+		user:     user,
+	}
+	f.svc = NewAuthService(f.users, f.refresh, f.sessions, nil, nil).(*authService)
+	return f
+}
+
+func (f *authFixture) login(t *testing.T) TokenPair {
+	t.Helper()
+	tokens, err := f.svc.CompleteLogin(f.user, ClientInfo{IP: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+	return tokens
+}
+
+// changePassword menandai password diganti setelah semua token di atas
+// diterbitkan. iat JWT presisi detik, jadi waktunya dibuat di detik berikutnya.
+func (f *authFixture) changePassword() {
+	changedAt := primitive.NewDateTimeFromTime(time.Now().Add(time.Second))
+	f.users.update(f.user.Id, func(u *models.User) { u.PasswordChangedAt = &changedAt })
+}
+
+func TestAuthenticate(t *testing.T) {
+	f := newAuthFixture(t)
+	tokens := f.login(t)
+
+	user, claims, err := f.svc.Authenticate(tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if user.Id != f.user.Id || claims.Type != TokenTypeAccess || claims.SessionID == "" {
+		t.Fatalf("user %s, claims %+v", user.Id.Hex(), claims)
+	}
+
+	challenge, err := issueChallengeToken(f.user, TokenTypeMFASetup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		allowed []string
+		wantErr bool
+	}{
+		{"access token", tokens.AccessToken, nil, false},
+		{"refresh token bukan access", tokens.RefreshToken, nil, true},
+		{"token mfa_setup tanpa izin", challenge, nil, true},
+		{"token mfa_setup di route pendaftaran 2FA", challenge, []string{TokenTypeAccess, TokenTypeMFASetup}, false},
+		{"token rusak", tokens.AccessToken + "x", nil, true},
+		{"bukan jwt", "abc", nil, true},
+		{"kosong", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := f.svc.Authenticate(tt.token, tt.allowed...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthenticateRejectsForeignSignature(t *testing.T) {
+	f := newAuthFixture(t)
+	tokens := f.login(t)
+
+	claims, err := parseToken(tokens.AccessToken, TokenTypeAccess)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret-lain-yang-cukup-panjang-32b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := f.svc.Authenticate(forged); err == nil {
+		t.Fatal("token dengan secret lain diterima")
+	}
+
+	none, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := f.svc.Authenticate(none); err == nil {
+		t.Fatal("token alg none diterima")
+	}
+}
+
+func TestAuthenticateSessionAndUser(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(f *authFixture, claims *Claims)
+	}{
+		{"sesi dicabut", func(f *authFixture, claims *Claims) {
+			id, _ := primitive.ObjectIDFromHex(claims.SessionID)
+			f.sessions.Revoke(id, "logout")
+		}},
+		{"sesi kedaluwarsa", func(f *authFixture, claims *Claims) {
+			id, _ := primitive.ObjectIDFromHex(claims.SessionID)
+			past := time.Now().Add(-time.Minute)
+			f.sessions.Touch(id, "", &past)
+		}},
+		{"sesi tidak ada", func(f *authFixture, claims *Claims) {
+			f.sessions.sessions = map[primitive.ObjectID]models.Session{}
+		}},
+		{"user dihapus", func(f *authFixture, _ *Claims) {
+			f.users.users = nil
+		}},
+		{"password diganti", func(f *authFixture, _ *Claims) {
+			f.changePassword()
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAuthFixture(t)
+			tokens := f.login(t)
+			_, claims, err := f.svc.Authenticate(tokens.AccessToken)
+			if err != nil {
+				t.Fatalf("Authenticate sebelum diubah: %v", err)
+			}
+
+			tt.mutate(f, claims)
+
+			if _, _, err := f.svc.Authenticate(tokens.AccessToken); err == nil {
+				t.Fatal("access token masih diterima")
+			}
+		})
+	}
+}
+
+func TestRefreshRotates(t *testing.T) {
+	f := newAuthFixture(t)
+	first := f.login(t)
+
+	second, err := f.svc.Refresh(first.RefreshToken, ClientInfo{IP: "10.0.0.2"})
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.AccessToken == first.AccessToken {
+		t.Fatal("refresh harus menerbitkan pasangan token baru")
+	}
+
+	old, next := f.refresh.stored(t, first.RefreshToken), f.refresh.stored(t, second.RefreshToken)
+	if old.RevokedAt == nil || old.ReplacedBy == nil || *old.ReplacedBy != next.Id {
+		t.Fatalf("token lama tidak dicabut / tidak menunjuk penggantinya: %+v", old)
+	}
+	if next.RevokedAt != nil || next.SessionID != old.SessionID {
+		t.Fatalf("token baru harus aktif di sesi yang sama: %+v", next)
+	}
+
+	// access token baru tetap milik sesi yang sama
+	_, claims, err := f.svc.Authenticate(second.AccessToken)
+	if err != nil {
+		t.Fatalf("Authenticate token baru: %v", err)
+	}
+	if claims.SessionID != old.SessionID.Hex() {
+		t.Fatalf("sid %s, want %s", claims.SessionID, old.SessionID.Hex())
+	}
+
+	if _, err := f.svc.Refresh(first.AccessToken, ClientInfo{}); err == nil {
+		t.Fatal("access token diterima sebagai refresh token")
+	}
+}
+
+// TestRefreshReuseRevokesSession memakai refresh token yang sudah dirotasi.
+// Token itu kemungkinan bocor, jadi semua refresh token user dan sesinya
+// dicabut, termasuk token pengganti yang dipegang client sah.
+func TestRefreshReuseRevokesSession(t *testing.T) {
+	f := newAuthFixture(t)
+	first := f.login(t)
+	other := f.login(t)
+
+	second, err := f.svc.Refresh(first.RefreshToken, ClientInfo{})
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	if _, err := f.svc.Refresh(first.RefreshToken, ClientInfo{}); err == nil {
+		t.Fatal("refresh token yang sudah dirotasi diterima lagi")
+	}
+
+	if _, err := f.svc.Refresh(second.RefreshToken, ClientInfo{}); err == nil {
+		t.Fatal("token pengganti masih bisa dipakai setelah reuse terdeteksi")
+	}
+	if _, _, err := f.svc.Authenticate(second.AccessToken); err == nil {
+		t.Fatal("access token sesi yang bocor masih diterima")
+	}
+
+	// sesi lain tidak dicabut, tapi refresh token-nya ikut dicabut
+	if _, _, err := f.svc.Authenticate(other.AccessToken); err != nil {
+		t.Fatalf("access token sesi lain: %v", err)
+	}
+	if stored := f.refresh.stored(t, other.RefreshToken); stored.RevokedAt == nil {
+		t.Fatal("refresh token sesi lain harus dicabut")
+	}
+}
+
+func TestRefreshConcurrentRotation(t *testing.T) {
+	f := newAuthFixture(t)
+	tokens := f.login(t)
+
+	const workers = 10
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		success int
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := f.svc.Refresh(tokens.RefreshToken, ClientInfo{}); err == nil {
+				mu.Lock()
+				success++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if success != 1 {
+		t.Fatalf("%d refresh berhasil, want tepat 1", success)
+	}
+}
+
+func TestRefreshAfterPasswordChange(t *testing.T) {
+	f := newAuthFixture(t)
+	tokens := f.login(t)
+	f.changePassword()
+
+	if _, err := f.svc.Refresh(tokens.RefreshToken, ClientInfo{}); err == nil {
+		t.Fatal("refresh token lama diterima setelah password diganti")
+	}
+	if stored := f.refresh.stored(t, tokens.RefreshToken); stored.RevokedAt == nil {
+		t.Fatal("refresh token lama harus dicabut")
+	}
+}
+
+func TestIssuedBeforePasswordChange(t *testing.T) {
+	base := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *primitive.DateTime {
+		v := primitive.NewDateTimeFromTime(base.Add(d))
+		return &v
+	}
+
+	tests := []struct {
+		name      string
+		changedAt *primitive.DateTime
+		issuedAt  time.Time
+		want      bool
+	}{
+		{"password tidak pernah diganti", nil, base, false},
+		{"token sebelum ganti password", at(2 * time.Second), base, true},
+		{"token setelah ganti password", at(0), base.Add(time.Second), false},
+		{"detik yang sama dianggap setelah", at(700 * time.Millisecond), base, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := models.User{PasswordChangedAt: tt.changedAt}
+			claims := &Claims{RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(tt.issuedAt)}}
+			if got := issuedBeforePasswordChange(user, claims); got != tt.want {
+				t.Fatalf("issuedBeforePasswordChange = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if issuedBeforePasswordChange(models.User{PasswordChangedAt: at(time.Hour)}, &Claims{}) {
+		t.Fatal("token tanpa iat tidak boleh dianggap sebelum ganti password")
+	}
+}
+
+// withPassword memberi user fixture password yang bisa dipakai Login.
+func (f *authFixture) withPassword(t *testing.T, password string) {
+	t.Helper()
+	hashed, err := utils.HashPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.users.update(f.user.Id, func(u *models.User) { u.Password = hashed })
+}
+
+func TestLoginLockout(t *testing.T) {
+	t.Setenv("BCRYPT_COST", "4")
+	t.Setenv("LOGIN_LOCKOUT_THRESHOLD", "3")
+	t.Setenv("LOGIN_LOCKOUT_BASE", "1m")
+	f := newAuthFixture(t)
+	f.withPassword(t, "Kamar-Lama#2030")
+	client := ClientInfo{IP: "10.0.0.1"}
+
+	for i := 0; i < 3; i++ {
+		if _, err := f.svc.Login(f.user.Email, "salah", client); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("percobaan %d: err %v, want ErrInvalidCredentials", i+1, err)
+		}
+	}
+
+	locked, _ := f.users.FindByID(f.user.Id.Hex())
+	if locked.FailedLoginAttempts != 3 || !isLocked(locked) {
+		t.Fatalf("%d kali gagal, terkunci %v; want 3 dan terkunci", locked.FailedLoginAttempts, isLocked(locked))
+	}
+	if until := time.Until(locked.LockedUntil.Time()); until <= 0 || until > time.Minute {
+		t.Fatalf("dikunci %v, want paling lama 1 menit", until)
+	}
+
+	// password benar tetap ditolak dengan pesan yang sama selama terkunci
+	if _, err := f.svc.Login(f.user.Email, "Kamar-Lama#2030", client); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("login saat terkunci: err %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := f.svc.Login("tidak-ada@example.com", "Kamar-Lama#2030", client); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("email tidak terdaftar: err %v, want ErrInvalidCredentials", err)
+	}
+
+	// setelah masa kunci lewat, login berhasil dan hitungan direset
+	f.users.LockUntil(f.user.Id, time.Now().Add(-time.Second))
+	result, err := f.svc.Login(" Budi@Example.com ", "Kamar-Lama#2030", client)
+	if err != nil {
+		t.Fatalf("login setelah masa kunci: %v", err)
+	}
+	if result.Tokens.AccessToken == "" {
+		t.Fatal("login berhasil tanpa token")
+	}
+	reset, _ := f.users.FindByID(f.user.Id.Hex())
+	if reset.FailedLoginAttempts != 0 || reset.LockedUntil != nil {
+		t.Fatalf("hitungan gagal %d, locked_until %v; want direset", reset.FailedLoginAttempts, reset.LockedUntil)
+	}
+}
+
+func TestLoginIPThrottle(t *testing.T) {
+	t.Setenv("BCRYPT_COST", "4")
+	t.Setenv("LOGIN_IP_MAX_ATTEMPTS", "2")
+	f := newAuthFixture(t)
+	f.withPassword(t, "Kamar-Lama#2030")
+	attacker := ClientInfo{IP: "10.0.0.66"}
+
+	// percobaan ke email berbeda tetap dihitung per IP
+	f.svc.Login("a@example.com", "salah", attacker)
+	f.svc.Login("b@example.com", "salah", attacker)
+
+	if _, err := f.svc.Login(f.user.Email, "Kamar-Lama#2030", attacker); !errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("IP yang diblokir: err %v, want ErrTooManyAttempts", err)
+	}
+	if _, _, err := f.svc.VerifyTwoFactor("token", "123456", "", attacker); !errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("2FA dari IP yang diblokir: err %v, want ErrTooManyAttempts", err)
+	}
+
+	if _, err := f.svc.Login(f.user.Email, "Kamar-Lama#2030", ClientInfo{IP: "10.0.0.1"}); err != nil {
+		t.Fatalf("IP lain: %v", err)
+	}
+
+	// akun tidak ikut terkunci karena percobaan ke email lain
+	user, _ := f.users.FindByID(f.user.Id.Hex())
+	if user.FailedLoginAttempts != 0 {
+		t.Fatalf("hitungan gagal akun %d, want 0", user.FailedLoginAttempts)
+	}
+}
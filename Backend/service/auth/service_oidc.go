@@ -0,0 +1,458 @@
+package auth
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	adminRepo "astro-backend/repository/admin"
+	authRepo "astro-backend/repository/auth"
+	"astro-backend/service/activityLog"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/oauth2"
+)
+
+// Konfigurasi SSO dibaca dari env:
+//
+//	OIDC_ISSUER          URL issuer IdP (kosong = SSO nonaktif)
+//	OIDC_CLIENT_ID       client id aplikasi di IdP
+//	OIDC_CLIENT_SECRET   client secret (boleh kosong untuk public client)
+//	OIDC_REDIRECT_URL    URL callback, mis. http://localhost:8080/login/oidc/callback
+//	OIDC_SCOPES          scope tambahan dipisah spasi (default "email profile")
+//	OIDC_ROLE_CLAIM      nama klaim role, boleh bertingkat (default "roles", mis. "realm_access.roles")
+//	OIDC_ROLE_MAP        pemetaan nilai klaim ke role, mis. "hotel-admin=Admin,frontdesk=Resepsionis"
+//	OIDC_DEFAULT_ROLE    role kalau tidak ada klaim yang cocok (kosong = login ditolak)
+//	OIDC_AUTO_CREATE     buat user baru saat login SSO pertama (default false)
+//	OIDC_SYNC_ROLE       samakan role user lama dengan klaim IdP setiap login (default false)
+//	OIDC_STATE_TTL       umur state login (default 10m)
+
+var (
+	ErrOIDCDisabled  = errors.New("Login SSO tidak diaktifkan")
+	ErrOIDCNoAccount = errors.New("Akun SSO belum terdaftar, hubungi admin")
+	ErrOIDCNoRole    = errors.New("Akun SSO tidak punya role yang diizinkan")
+	ErrOIDCState     = errors.New("state login SSO tidak valid atau sudah kedaluwarsa")
+)
+
+// OIDCLogin adalah hasil AuthURL. StateCookie wajib disimpan di browser
+// yang memulai login (cookie HttpOnly, SameSite=Lax) dan dikirim kembali
+// ke Callback, supaya callback milik orang lain tidak bisa dipakai untuk
+// memasukkan korban ke akun penyerang (login CSRF).
+type OIDCLogin struct {
+	URL         string
+	StateCookie string
+	MaxAge      time.Duration
+}
+
+type OIDCService interface {
+	Enabled() bool
+	AuthURL() (OIDCLogin, error)
+	Callback(code, state, stateCookie string, client ClientInfo) (models.User, TokenPair, error)
+}
+
+type oidcConfig struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+	roleClaim    string
+	roleMap      [][2]string // urutan = prioritas
+	defaultRole  string
+	autoCreate   bool
+	syncRole     bool
+	stateTTL     time.Duration
+}
+
+type oidcService struct {
+	cfg         oidcConfig
+	repo        adminRepo.UserRepository
+	stateRepo   authRepo.OIDCStateRepository
+	sessionRepo authRepo.SessionRepository
+	refreshRepo authRepo.RefreshTokenRepository
+	auth        AuthService
+	logSvc      activityLog.ActivityLogService
+
+	// provider di-discover saat pertama dipakai supaya server tetap bisa
+	// start walaupun IdP sedang tidak bisa dihubungi
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+func NewOIDCService(repo adminRepo.UserRepository, stateRepo authRepo.OIDCStateRepository, sessionRepo authRepo.SessionRepository, refreshRepo authRepo.RefreshTokenRepository, auth AuthService, logSvc activityLog.ActivityLogService) OIDCService {
+	return &oidcService{
+		cfg:         loadOIDCConfig(),
+		repo:        repo,
+		stateRepo:   stateRepo,
+		sessionRepo: sessionRepo,
+		refreshRepo: refreshRepo,
+		auth:        auth,
+		logSvc:      logSvc,
+	}
+}
+
+func loadOIDCConfig() oidcConfig {
+	cfg := oidcConfig{
+		issuer:       strings.TrimRight(os.Getenv("OIDC_ISSUER"), "/"),
+		clientID:     os.Getenv("OIDC_CLIENT_ID"),
+		clientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		redirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		scopes:       []string{oidc.ScopeOpenID},
+		roleClaim:    os.Getenv("OIDC_ROLE_CLAIM"),
+		defaultRole:  os.Getenv("OIDC_DEFAULT_ROLE"),
+		autoCreate:   os.Getenv("OIDC_AUTO_CREATE") == "true",
+		syncRole:     os.Getenv("OIDC_SYNC_ROLE") == "true",
+		stateTTL:     parseDurationEnv("OIDC_STATE_TTL", 10*time.Minute),
+	}
+
+	scopes := os.Getenv("OIDC_SCOPES")
+	if scopes == "" {
+		scopes = "email profile"
+	}
+	for _, sc := range strings.Fields(scopes) {
+		if sc != oidc.ScopeOpenID {
+			cfg.scopes = append(cfg.scopes, sc)
+		}
+	}
+
+	if cfg.roleClaim == "" {
+		cfg.roleClaim = "roles"
+	}
+
+	for _, pair := range strings.Split(os.Getenv("OIDC_ROLE_MAP"), ",") {
+		claim, role, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		cfg.roleMap = append(cfg.roleMap, [2]string{strings.TrimSpace(claim), strings.TrimSpace(role)})
+	}
+
+	return cfg
+}
+
+func (s *oidcService) Enabled() bool {
+	return s.cfg.issuer != "" && s.cfg.clientID != "" && s.cfg.redirectURL != ""
+}
+
+func (s *oidcService) getProvider(ctx context.Context) (*oidc.Provider, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.provider != nil {
+		return s.provider, nil
+	}
+
+	provider, err := oidc.NewProvider(ctx, s.cfg.issuer)
+	if err != nil {
+		return nil, errors.New("gagal menghubungi IdP: " + err.Error())
+	}
+	s.provider = provider
+	return provider, nil
+}
+
+func (s *oidcService) oauth2Config(provider *oidc.Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     s.cfg.clientID,
+		ClientSecret: s.cfg.clientSecret,
+		RedirectURL:  s.cfg.redirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       s.cfg.scopes,
+	}
+}
+
+// AuthURL menyiapkan state, nonce dan PKCE verifier lalu mengembalikan URL
+// authorize IdP tempat user harus diarahkan beserta nilai cookie state.
+func (s *oidcService) AuthURL() (OIDCLogin, error) {
+	if !s.Enabled() {
+		return OIDCLogin{}, ErrOIDCDisabled
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	provider, err := s.getProvider(ctx)
+	if err != nil {
+		return OIDCLogin{}, err
+	}
+
+	state, err := newOpaqueToken()
+	if err != nil {
+		return OIDCLogin{}, err
+	}
+	nonce, err := newOpaqueToken()
+	if err != nil {
+		return OIDCLogin{}, err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	now := time.Now()
+	err = s.stateRepo.Create(models.OIDCLoginState{
+		Id:           primitive.NewObjectID(),
+		StateHash:    hashToken(state),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    primitive.NewDateTimeFromTime(now.Add(s.cfg.stateTTL)),
+		CreatedAt:    primitive.NewDateTimeFromTime(now),
+	})
+	if err != nil {
+		return OIDCLogin{}, err
+	}
+
+	return OIDCLogin{
+		URL: s.oauth2Config(provider).AuthCodeURL(state,
+			oidc.Nonce(nonce),
+			oauth2.S256ChallengeOption(verifier),
+		),
+		StateCookie: stateCookieValue(state),
+		MaxAge:      s.cfg.stateTTL,
+	}, nil
+}
+
+// stateCookieValue adalah HMAC state dengan secret JWT. Cookie hanya berisi
+// tanda tangan, jadi tidak bisa dibuat untuk state milik orang lain.
+func stateCookieValue(state string) string {
+	mac := hmac.New(sha256.New, loadTokenConfig().secret)
+	mac.Write([]byte("oidc-state:" + state))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type oidcClaims struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified *bool  `json:"email_verified"`
+	Name          string `json:"name"`
+	PhoneNumber   string `json:"phone_number"`
+}
+
+// Callback menukar authorization code, memverifikasi ID token lalu
+// menerbitkan token aplikasi. MFA diserahkan ke IdP, jadi kebijakan 2FA
+// lokal tidak diterapkan untuk login SSO.
+func (s *oidcService) Callback(code, state, stateCookie string, client ClientInfo) (models.User, TokenPair, error) {
+	if !s.Enabled() {
+		return models.User{}, TokenPair{}, ErrOIDCDisabled
+	}
+	if code == "" || state == "" {
+		return models.User{}, TokenPair{}, errors.New("code dan state wajib diisi")
+	}
+
+	// state harus berasal dari browser yang sama dengan yang memulai login
+	if !hmac.Equal([]byte(stateCookie), []byte(stateCookieValue(state))) {
+		s.logFailed(models.User{}, "", client, "cookie state tidak cocok")
+		return models.User{}, TokenPair{}, ErrOIDCState
+	}
+
+	loginState, err := s.stateRepo.Consume(hashToken(state))
+	if err != nil {
+		s.logFailed(models.User{}, "", client, "state tidak valid")
+		return models.User{}, TokenPair{}, ErrOIDCState
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	provider, err := s.getProvider(ctx)
+	if err != nil {
+		return models.User{}, TokenPair{}, err
+	}
+
+	token, err := s.oauth2Config(provider).Exchange(ctx, code, oauth2.VerifierOption(loginState.CodeVerifier))
+	if err != nil {
+		s.logFailed(models.User{}, "", client, "gagal menukar authorization code")
+		return models.User{}, TokenPair{}, errors.New("gagal menukar authorization code")
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return models.User{}, TokenPair{}, errors.New("IdP tidak mengirim id_token")
+	}
+
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: s.cfg.clientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		s.logFailed(models.User{}, "", client, "id_token tidak valid")
+		return models.User{}, TokenPair{}, errors.New("id_token tidak valid")
+	}
+	if idToken.Nonce != loginState.Nonce {
+		s.logFailed(models.User{}, "", client, "nonce tidak cocok")
+		return models.User{}, TokenPair{}, errors.New("id_token tidak valid")
+	}
+
+	var claims oidcClaims
+	var rawClaims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return models.User{}, TokenPair{}, err
+	}
+	if err := idToken.Claims(&rawClaims); err != nil {
+		return models.User{}, TokenPair{}, err
+	}
+	claims.Email = strings.ToLower(strings.TrimSpace(claims.Email))
+
+	role := s.mapRole(rawClaims)
+	if role == "" {
+		s.logFailed(models.User{}, claims.Email, client, "tidak ada role yang cocok")
+		return models.User{}, TokenPair{}, ErrOIDCNoRole
+	}
+
+	user, err := s.findOrCreateUser(idToken.Issuer, claims, role)
+	if err != nil {
+		s.logFailed(models.User{}, claims.Email, client, err.Error())
+		return models.User{}, TokenPair{}, err
+	}
+
+	tokens, err := s.auth.CompleteLogin(user, client)
+	if err != nil {
+		return models.User{}, TokenPair{}, err
+	}
+
+	return user, tokens, nil
+}
+
+// findOrCreateUser mencari user berdasarkan issuer + subject, lalu email
+// (hanya kalau IdP menyatakan email sudah terverifikasi), dan membuat user
+// baru kalau OIDC_AUTO_CREATE aktif. Role user yang sudah ada hanya diubah
+// kalau OIDC_SYNC_ROLE aktif.
+func (s *oidcService) findOrCreateUser(issuer string, claims oidcClaims, role string) (models.User, error) {
+	user, err := s.repo.FindByOIDCSubject(issuer, claims.Subject)
+	if err == nil {
+		return s.syncRole(user, role)
+	}
+
+	emailVerified := claims.EmailVerified != nil && *claims.EmailVerified
+	if claims.Email == "" || !emailVerified {
+		return models.User{}, errors.New("IdP tidak mengirim email yang terverifikasi")
+	}
+
+	user, err = s.repo.FindByEmail(claims.Email)
+	if err == nil {
+		if user.OIDCSubject != "" {
+			// email sama tapi sudah tertaut ke akun IdP lain
+			return models.User{}, ErrOIDCNoAccount
+		}
+		if err := s.repo.LinkOIDC(user.Id, issuer, claims.Subject); err != nil {
+			return models.User{}, err
+		}
+		user.OIDCIssuer = issuer
+		user.OIDCSubject = claims.Subject
+		return s.syncRole(user, role)
+	}
+
+	if !s.cfg.autoCreate {
+		return models.User{}, ErrOIDCNoAccount
+	}
+
+	name := claims.Name
+	if name == "" {
+		name = claims.Email
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	verified := true
+	user = models.User{
+		Id:              primitive.NewObjectID(),
+		Name:            name,
+		Email:           claims.Email,
+		NoTlp:           claims.PhoneNumber,
+		Role:            role,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		EmailVerified:   &verified,
+		EmailVerifiedAt: &now,
+		OIDCIssuer:      issuer,
+		OIDCSubject:     claims.Subject,
+	}
+	if err := s.repo.Create(user); err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// syncRole menyamakan role user dengan klaim IdP kalau OIDC_SYNC_ROLE aktif.
+// Seperti perubahan role oleh admin, semua sesi lama dicabut.
+func (s *oidcService) syncRole(user models.User, role string) (models.User, error) {
+	if !s.cfg.syncRole || user.Role == role {
+		return user, nil
+	}
+
+	if err := s.repo.Update(user.Id.Hex(), adminRepo.UserPatch{Role: &role}); err != nil {
+		return models.User{}, err
+	}
+	if _, err := s.sessionRepo.RevokeAllByUser(user.Id, "role diubah IdP"); err != nil {
+		return models.User{}, err
+	}
+	if err := s.refreshRepo.RevokeAllByUser(user.Id); err != nil {
+		return models.User{}, err
+	}
+
+	user.Role = role
+	return user, nil
+}
+
+// mapRole mencocokkan nilai klaim role dengan OIDC_ROLE_MAP. Kalau tidak ada
+// yang cocok dipakai OIDC_DEFAULT_ROLE.
+func (s *oidcService) mapRole(claims map[string]any) string {
+	values := claimValues(claims, s.cfg.roleClaim)
+
+	for _, m := range s.cfg.roleMap {
+		for _, v := range values {
+			if v == m[0] && isKnownRole(m[1]) {
+				return m[1]
+			}
+		}
+	}
+
+	if isKnownRole(s.cfg.defaultRole) {
+		return s.cfg.defaultRole
+	}
+	return ""
+}
+
+// claimValues membaca klaim bertingkat (mis. "realm_access.roles") yang
+// berisi string atau array string.
+func claimValues(claims map[string]any, path string) []string {
+	var cur any = claims
+	for _, key := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+		cur = m[key]
+	}
+
+	switch v := cur.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func isKnownRole(role string) bool {
+	_, ok := constants.RolePermissions[role]
+	return ok && role != ""
+}
+
+func (s *oidcService) logFailed(user models.User, email string, client ClientInfo, reason string) {
+	logAuthEvent(s.logSvc, client, models.ActivityLog{
+		UserID:     userRef(user),
+		UserEmail:  email,
+		ActionType: constants.ActLogin,
+		Message:    "login SSO gagal: " + reason,
+		Metadata:   primitive.M{"method": "oidc"},
+		Status:     constants.StatusFailed,
+	})
+}
@@ -0,0 +1,489 @@
+package auth
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	adminRepo "astro-backend/repository/admin"
+	authRepo "astro-backend/repository/auth"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memUsers meniru UserRepository di memori untuk method yang dipakai
+// service auth.
+type memUsers struct {
+	adminRepo.UserRepository
+	mu    sync.Mutex
+	users []models.User
+}
+
+func (m *memUsers) find(match func(models.User) bool) (models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range m.users {
+		if match(u) {
+			return u, nil
+		}
+	}
+	return models.User{}, errors.New("User tidak ditemukan")
+}
+
+func (m *memUsers) update(id primitive.ObjectID, fn func(*models.User)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.users {
+		if m.users[i].Id == id {
+			fn(&m.users[i])
+		}
+	}
+}
+
+func (m *memUsers) FindByID(id string) (models.User, error) {
+	return m.find(func(u models.User) bool { return u.Id.Hex() == id })
+}
+
+func (m *memUsers) FindByEmail(email string) (models.User, error) {
+	return m.find(func(u models.User) bool { return strings.EqualFold(u.Email, email) })
+}
+
+func (m *memUsers) FindByOIDCSubject(issuer, subject string) (models.User, error) {
+	return m.find(func(u models.User) bool { return u.OIDCIssuer == issuer && u.OIDCSubject == subject })
+}
+
+func (m *memUsers) LinkOIDC(id primitive.ObjectID, issuer, subject string) error {
+	m.update(id, func(u *models.User) { u.OIDCIssuer, u.OIDCSubject = issuer, subject })
+	return nil
+}
+
+func (m *memUsers) Update(id string, patch adminRepo.UserPatch) error {
+	objID, _ := primitive.ObjectIDFromHex(id)
+	m.update(objID, func(u *models.User) {
+		if patch.Role != nil {
+			u.Role = *patch.Role
+		}
+	})
+	return nil
+}
+
+func (m *memUsers) Create(user models.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.users = append(m.users, user)
+	return nil
+}
+
+type stubSessions struct {
+	authRepo.SessionRepository
+	revokedUsers []primitive.ObjectID
+}
+
+func (s *stubSessions) RevokeAllByUser(userID primitive.ObjectID, _ string) (int64, error) {
+	s.revokedUsers = append(s.revokedUsers, userID)
+	return 1, nil
+}
+
+type stubRefreshTokens struct {
+	authRepo.RefreshTokenRepository
+	revokedUsers []primitive.ObjectID
+}
+
+func (s *stubRefreshTokens) RevokeAllByUser(userID primitive.ObjectID) error {
+	s.revokedUsers = append(s.revokedUsers, userID)
+	return nil
+}
+
+type memOIDCStates struct {
+	mu     sync.Mutex
+	states map[string]models.OIDCLoginState
+}
+
+func (m *memOIDCStates) Create(state models.OIDCLoginState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states[state.StateHash] = state
+	return nil
+}
+
+func (m *memOIDCStates) Consume(stateHash string) (models.OIDCLoginState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state, ok := m.states[stateHash]
+	if !ok || state.ExpiresAt.Time().Before(time.Now()) {
+		return models.OIDCLoginState{}, errors.New("state login SSO tidak valid atau sudah kedaluwarsa")
+	}
+	delete(m.states, stateHash)
+	return state, nil
+}
+
+func (*memOIDCStates) EnsureIndexes() error { return nil }
+
+type stubCompleteLogin struct {
+	AuthService
+}
+
+func (stubCompleteLogin) CompleteLogin(user models.User, _ ClientInfo) (TokenPair, error) {
+	return TokenPair{AccessToken: "access-" + user.Id.Hex(), TokenType: "Bearer"}, nil
+}
+
+// mockIdP adalah IdP OIDC minimal: discovery, JWKS dan token endpoint.
+// Token endpoint mewajibkan PKCE verifier yang cocok dengan challenge
+// dari URL authorize terakhir.
+type mockIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu          sync.Mutex
+	challenge   string
+	nonce       string
+	gotVerifier string
+	claims      map[string]any
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &mockIdP{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                                idp.server.URL,
+			"authorization_endpoint":                idp.server.URL + "/authorize",
+			"token_endpoint":                        idp.server.URL + "/token",
+			"jwks_uri":                              idp.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: &key.PublicKey, KeyID: "test", Algorithm: string(jose.RS256), Use: "sig"},
+		}})
+	})
+	mux.HandleFunc("/token", idp.token)
+
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	r.ParseForm()
+	verifier := r.PostForm.Get("code_verifier")
+	idp.gotVerifier = verifier
+	sum := sha256.Sum256([]byte(verifier))
+	if r.PostForm.Get("code") != "good-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != idp.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_grant"}`))
+		return
+	}
+
+	now := time.Now()
+	claims := map[string]any{
+		"iss":   idp.server.URL,
+		"aud":   "hotel-app",
+		"sub":   "idp-user-1",
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"nonce": idp.nonce,
+	}
+	for k, v := range idp.claims {
+		claims[k] = v
+	}
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: jose.JSONWebKey{Key: idp.key, KeyID: "test"}},
+		(&jose.SignerOptions{}).WithType("JWT"),
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	payload, _ := json.Marshal(claims)
+	signed, err := signer.Sign(payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	idToken, _ := signed.CompactSerialize()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"access_token": "idp-access",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+// begin memanggil AuthURL lalu mencatat nonce dan challenge seperti IdP
+// yang menerima redirect authorize.
+func (idp *mockIdP) begin(t *testing.T, svc OIDCService) (state, cookie string) {
+	t.Helper()
+
+	login, err := svc.AuthURL()
+	if err != nil {
+		t.Fatalf("AuthURL: %v", err)
+	}
+	u, err := url.Parse(login.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		t.Fatalf("URL authorize tanpa PKCE: %s", login.URL)
+	}
+
+	idp.mu.Lock()
+	idp.challenge = q.Get("code_challenge")
+	idp.nonce = q.Get("nonce")
+	idp.mu.Unlock()
+	return q.Get("state"), login.StateCookie
+}
+
+type oidcFixture struct {
+	idp      *mockIdP
+	svc      *oidcService
+	users    *memUsers
+	sessions *stubSessions
+	refresh  *stubRefreshTokens
+}
+
+func newOIDCFixture(t *testing.T, users ...models.User) *oidcFixture {
+	t.Helper()
+
+	idp := newMockIdP(t)
+	f := &oidcFixture{
+		idp:      idp,
+		users:    &memUsers{users: users},
+		sessions: &stubSessions{},
+		refresh:  &stubRefreshTokens{},
+	}
+	f.svc = &oidcService{
+		cfg: oidcConfig{
+			issuer:      idp.server.URL,
+			clientID:    "hotel-app",
+			redirectURL: "http://localhost:8080/login/oidc/callback",
+			scopes:      []string{"openid", "email", "profile"},
+			roleClaim:   "roles",
+			roleMap:     [][2]string{{"hotel-admin", constants.RoleAdmin}, {"guest", constants.RoleGuest}},
+			autoCreate:  true,
+			stateTTL:    time.Minute,
+		},
+		repo:        f.users,
+		stateRepo:   &memOIDCStates{states: map[string]models.OIDCLoginState{}},
+		sessionRepo: f.sessions,
+		refreshRepo: f.refresh,
+		auth:        stubCompleteLogin{},
+	}
+	return f
+}
+
+func verifiedClaims(extra map[string]any) map[string]any {
+	claims := map[string]any{"email": "tamu@example.com", "email_verified": true, "name": "Tamu", "roles": []string{"guest"}}
+	for k, v := range extra {
+		claims[k] = v
+	}
+	return claims
+}
+
+func TestOIDCCallbackCreatesUser(t *testing.T) {
+	f := newOIDCFixture(t)
+	f.idp.claims = verifiedClaims(map[string]any{"roles": []string{"other", "hotel-admin"}})
+
+	state, cookie := f.idp.begin(t, f.svc)
+	user, tokens, err := f.svc.Callback("good-code", state, cookie, ClientInfo{})
+	if err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+
+	if f.idp.gotVerifier == "" {
+		t.Fatal("PKCE code_verifier tidak dikirim ke token endpoint")
+	}
+	if user.Role != constants.RoleAdmin || user.OIDCSubject != "idp-user-1" || user.OIDCIssuer != f.idp.server.URL {
+		t.Fatalf("user = role %q subject %q issuer %q", user.Role, user.OIDCSubject, user.OIDCIssuer)
+	}
+	if user.Email != "tamu@example.com" || tokens.AccessToken != "access-"+user.Id.Hex() {
+		t.Fatalf("email %q, token %q", user.Email, tokens.AccessToken)
+	}
+	if len(f.users.users) != 1 {
+		t.Fatalf("user tersimpan = %d, mau 1", len(f.users.users))
+	}
+}
+
+func TestOIDCCallbackState(t *testing.T) {
+	f := newOIDCFixture(t)
+	f.idp.claims = verifiedClaims(nil)
+
+	_, otherCookie := f.idp.begin(t, f.svc)
+	state, cookie := f.idp.begin(t, f.svc)
+
+	tests := []struct {
+		name          string
+		state, cookie string
+	}{
+		{"tanpa cookie", state, ""},
+		{"cookie milik login lain", state, otherCookie},
+		{"cookie tidak ditandatangani", state, hashToken(state)},
+		{"state tidak dikenal", "state-palsu", stateCookieValue("state-palsu")},
+		{"state kosong", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := f.svc.Callback("good-code", tt.state, tt.cookie, ClientInfo{}); err == nil {
+				t.Fatal("callback harus ditolak")
+			}
+		})
+	}
+	if f.idp.gotVerifier != "" {
+		t.Fatal("callback yang ditolak tidak boleh sampai ke token endpoint")
+	}
+
+	// percobaan yang ditolak tidak menghabiskan state milik browser asli,
+	// tapi state hanya bisa dipakai sekali
+	if _, _, err := f.svc.Callback("good-code", state, cookie, ClientInfo{}); err != nil {
+		t.Fatalf("callback dengan cookie yang benar: %v", err)
+	}
+	if _, _, err := f.svc.Callback("good-code", state, cookie, ClientInfo{}); !errors.Is(err, ErrOIDCState) {
+		t.Fatalf("state dipakai ulang: err = %v, mau ErrOIDCState", err)
+	}
+}
+
+func TestOIDCCallbackNonceMismatch(t *testing.T) {
+	f := newOIDCFixture(t)
+	f.idp.claims = verifiedClaims(nil)
+
+	state, cookie := f.idp.begin(t, f.svc)
+	f.idp.nonce = "nonce-lain"
+
+	if _, _, err := f.svc.Callback("good-code", state, cookie, ClientInfo{}); err == nil || err.Error() != "id_token tidak valid" {
+		t.Fatalf("err = %v, mau id_token tidak valid", err)
+	}
+	if len(f.users.users) != 0 {
+		t.Fatal("user tidak boleh dibuat")
+	}
+}
+
+func TestOIDCCallbackUnverifiedEmail(t *testing.T) {
+	existing := models.User{Id: primitive.NewObjectID(), Email: "tamu@example.com", Role: constants.RoleGuest}
+
+	tests := []struct {
+		name   string
+		claims map[string]any
+	}{
+		{"email_verified false", verifiedClaims(map[string]any{"email_verified": false})},
+		{"tanpa email_verified", map[string]any{"email": "tamu@example.com", "roles": "guest"}},
+		{"tanpa email", verifiedClaims(map[string]any{"email": ""})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newOIDCFixture(t, existing)
+			f.idp.claims = tt.claims
+
+			state, cookie := f.idp.begin(t, f.svc)
+			if _, _, err := f.svc.Callback("good-code", state, cookie, ClientInfo{}); err == nil {
+				t.Fatal("email yang tidak terverifikasi harus ditolak")
+			}
+			if u := f.users.users[0]; u.OIDCSubject != "" || len(f.users.users) != 1 {
+				t.Fatal("akun lokal tidak boleh ditautkan atau dibuat")
+			}
+		})
+	}
+}
+
+func TestOIDCLinkKeepsRole(t *testing.T) {
+	tests := []struct {
+		name       string
+		syncRole   bool
+		wantRole   string
+		wantRevoke bool
+	}{
+		{"sinkronisasi role mati", false, constants.RoleAdmin, false},
+		{"sinkronisasi role aktif", true, constants.RoleGuest, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			admin := models.User{Id: primitive.NewObjectID(), Email: "tamu@example.com", Role: constants.RoleAdmin}
+			f := newOIDCFixture(t, admin)
+			f.svc.cfg.syncRole = tt.syncRole
+			f.idp.claims = verifiedClaims(nil) // IdP memetakan ke Guest
+
+			// login pertama menautkan lewat email, login kedua lewat subject
+			for i := 0; i < 2; i++ {
+				state, cookie := f.idp.begin(t, f.svc)
+				user, _, err := f.svc.Callback("good-code", state, cookie, ClientInfo{})
+				if err != nil {
+					t.Fatalf("login %d: %v", i+1, err)
+				}
+				if user.Role != tt.wantRole {
+					t.Fatalf("login %d: role = %q, mau %q", i+1, user.Role, tt.wantRole)
+				}
+			}
+
+			stored := f.users.users[0]
+			if stored.Role != tt.wantRole || stored.OIDCSubject != "idp-user-1" {
+				t.Fatalf("tersimpan role %q subject %q", stored.Role, stored.OIDCSubject)
+			}
+			revoked := len(f.sessions.revokedUsers) > 0 && len(f.refresh.revokedUsers) > 0
+			if revoked != tt.wantRevoke {
+				t.Fatalf("sesi dicabut = %v, mau %v", revoked, tt.wantRevoke)
+			}
+			if tt.wantRevoke && (len(f.sessions.revokedUsers) != 1 || f.sessions.revokedUsers[0] != admin.Id) {
+				t.Fatalf("sesi dicabut %v, mau sekali untuk user %s", f.sessions.revokedUsers, admin.Id.Hex())
+			}
+		})
+	}
+}
+
+func TestOIDCMapRole(t *testing.T) {
+	tests := []struct {
+		name        string
+		claim       string
+		defaultRole string
+		claims      map[string]any
+		want        string
+	}{
+		{"array", "roles", "", map[string]any{"roles": []any{"guest"}}, constants.RoleGuest},
+		{"urutan map menentukan prioritas", "roles", "", map[string]any{"roles": []any{"guest", "hotel-admin"}}, constants.RoleAdmin},
+		{"string dipisah spasi", "roles", "", map[string]any{"roles": "x hotel-admin"}, constants.RoleAdmin},
+		{"klaim bertingkat", "realm_access.roles", "", map[string]any{"realm_access": map[string]any{"roles": []any{"hotel-admin"}}}, constants.RoleAdmin},
+		{"role hasil map tidak dikenal diabaikan", "roles", "", map[string]any{"roles": []any{"typo"}}, ""},
+		{"tidak cocok pakai default", "roles", constants.RoleGuest, map[string]any{"roles": []any{"lain"}}, constants.RoleGuest},
+		{"default tidak dikenal", "roles", "Superuser", map[string]any{}, ""},
+		{"klaim bukan string", "roles", "", map[string]any{"roles": 42.0}, ""},
+		{"path melewati nilai non-objek", "roles.nested", "", map[string]any{"roles": "guest"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &oidcService{cfg: oidcConfig{
+				roleClaim:   tt.claim,
+				defaultRole: tt.defaultRole,
+				roleMap:     [][2]string{{"hotel-admin", constants.RoleAdmin}, {"guest", constants.RoleGuest}, {"typo", "Admn"}},
+			}}
+			if got := svc.mapRole(tt.claims); got != tt.want {
+				t.Fatalf("mapRole = %q, mau %q", got, tt.want)
+			}
+		})
+	}
+}
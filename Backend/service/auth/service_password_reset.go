@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"astro-backend/constants"
+	"astro-backend/mailer"
+	"astro-backend/models"
+	adminRepo "astro-backend/repository/admin"
+	authRepo "astro-backend/repository/auth"
+	"astro-backend/service/activityLog"
+	"astro-backend/utils"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PasswordResetService interface {
+	RequestReset(email string, client ClientInfo) error
+	ConfirmReset(token, newPassword string, client ClientInfo) error
+}
+
+type passwordResetService struct {
+	repo        adminRepo.UserRepository
+	tokenRepo   authRepo.UserTokenRepository
+	refreshRepo authRepo.RefreshTokenRepository
+	sessionRepo authRepo.SessionRepository
+	mailer      mailer.Sender
+	logSvc      activityLog.ActivityLogService
+}
+
+func NewPasswordResetService(repo adminRepo.UserRepository, tokenRepo authRepo.UserTokenRepository, refreshRepo authRepo.RefreshTokenRepository, sessionRepo authRepo.SessionRepository, sender mailer.Sender, logSvc activityLog.ActivityLogService) PasswordResetService {
+	return &passwordResetService{repo, tokenRepo, refreshRepo, sessionRepo, sender, logSvc}
+}
+
+// PasswordResetLink mengarah ke halaman reset di frontend (PASSWORD_RESET_URL)
+// kalau diisi. Tanpa itu link mengarah ke GET /password/reset di backend.
+func PasswordResetLink(token string) string {
+	base := os.Getenv("PASSWORD_RESET_URL")
+	if base == "" {
+		base = appBaseURL() + "/password/reset"
+	}
+	sep := "?"
+	if strings.Contains(base, "?") {
+		sep = "&"
+	}
+	return base + sep + "token=" + url.QueryEscape(token)
+}
+
+// RequestReset mengirim link reset ke email. Hasilnya selalu sukses untuk
+// caller supaya tidak bisa dipakai menebak email yang terdaftar.
+func (s *passwordResetService) RequestReset(email string, client ClientInfo) error {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	user, err := s.repo.FindByEmail(email)
+	if err != nil {
+		logSecurity(s.logSvc, client, models.ActivityLog{
+			UserEmail:  email,
+			ActionType: constants.ActPasswordReset,
+			Message:    "reset password diminta untuk email yang tidak terdaftar",
+			Status:     constants.StatusFailed,
+		})
+		return nil
+	}
+
+	// hanya token terakhir yang berlaku
+	if err := s.tokenRepo.InvalidateByUser(user.Id, constants.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	raw, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	ttl := parseDurationEnv("PASSWORD_RESET_TTL", 30*time.Minute)
+
+	err = s.tokenRepo.Create(models.UserToken{
+		Id:        primitive.NewObjectID(),
+		UserID:    user.Id,
+		Purpose:   constants.TokenPurposePasswordReset,
+		TokenHash: hashToken(raw),
+		ExpiresAt: primitive.NewDateTimeFromTime(now.Add(ttl)),
+		CreatedAt: primitive.NewDateTimeFromTime(now),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := PasswordResetLink(raw)
+	err = s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset password akun Astro",
+		Body: fmt.Sprintf("Halo %s,\n\nKami menerima permintaan reset password untuk akun Anda.\n"+
+			"Gunakan link berikut untuk membuat password baru:\n%s\n\n"+
+			"Link berlaku selama %s dan hanya bisa dipakai sekali. Abaikan email ini jika Anda tidak memintanya.\n",
+			user.Name, link, ttl),
+	})
+
+	status, message := constants.StatusSuccess, "link reset password dikirim"
+	if err != nil {
+		status, message = constants.StatusFailed, "gagal mengirim email reset password"
+	}
+	logSecurity(s.logSvc, client, models.ActivityLog{
+		UserID:     userRef(user),
+		UserEmail:  user.Email,
+		ActionType: constants.ActPasswordReset,
+		ResourceID: user.Id.Hex(),
+		Message:    message,
+		Status:     status,
+	})
+	// kegagalan kirim email tidak dikembalikan; email yang tidak terdaftar
+	// juga selalu sukses, jadi respons tidak membedakan keduanya
+	if err != nil {
+		log.Error().Err(err).Str("email", user.Email).Msg("gagal kirim email reset password")
+	}
+
+	return nil
+}
+
+// ConfirmReset mengganti password memakai token reset. Token hanya bisa
+// dipakai sekali, dan semua sesi user dicabut setelah password berubah.
+func (s *passwordResetService) ConfirmReset(token, newPassword string, client ClientInfo) error {
+	if token == "" || newPassword == "" {
+		return errors.New("token dan password baru wajib diisi")
+	}
+	// token baru dipakai setelah password lolos policy lengkap (termasuk
+	// cek email dan nama), jadi password yang ditolak tidak menghanguskannya
+	found, err := s.tokenRepo.Find(hashToken(token), constants.TokenPurposePasswordReset)
+	if err != nil {
+		s.logInvalidToken(client)
+		return err
+	}
+
+	user, err := s.repo.FindByID(found.UserID.Hex())
+	if err != nil {
+		return err
+	}
+
+	if err := utils.ValidatePassword(newPassword, user.Email, user.Name); err != nil {
+		return err
+	}
+
+	// Consume tetap atomik: dari dua request bersamaan hanya satu yang lolos
+	if _, err := s.tokenRepo.Consume(found.TokenHash, constants.TokenPurposePasswordReset); err != nil {
+		s.logInvalidToken(client)
+		return err
+	}
+
+	hashed, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdatePassword(user.Id, hashed); err != nil {
+		return err
+	}
+
+	// semua sesi dan refresh token dicabut; access token lama juga ditolak lewat PasswordChangedAt
+	if _, err := s.sessionRepo.RevokeAllByUser(user.Id, "password direset"); err != nil {
+		return err
+	}
+	if err := s.refreshRepo.RevokeAllByUser(user.Id); err != nil {
+		return err
+	}
+
+	logSecurity(s.logSvc, client, models.ActivityLog{
+		UserID:     userRef(user),
+		UserEmail:  user.Email,
+		ActionType: constants.ActPasswordReset,
+		ResourceID: user.Id.Hex(),
+		Message:    "password berhasil direset, semua sesi dicabut",
+		Status:     constants.StatusSuccess,
+	})
+
+	return nil
+}
+
+func (s *passwordResetService) logInvalidToken(client ClientInfo) {
+	logSecurity(s.logSvc, client, models.ActivityLog{
+		ActionType: constants.ActPasswordReset,
+		Message:    "token reset password tidak valid",
+		Status:     constants.StatusFailed,
+	})
+}
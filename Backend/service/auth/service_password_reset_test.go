@@ -0,0 +1,26 @@
+package auth
+
+import "testing"
+
+func TestPasswordResetLink(t *testing.T) {
+	tests := []struct {
+		name     string
+		resetURL string
+		baseURL  string
+		want     string
+	}{
+		{"default ke backend", "", "", "http://localhost:8080/password/reset?token=abc"},
+		{"APP_BASE_URL", "", "https://api.astro.id/", "https://api.astro.id/password/reset?token=abc"},
+		{"halaman frontend", "https://astro.id/reset-password", "", "https://astro.id/reset-password?token=abc"},
+		{"frontend dengan query", "https://astro.id/akun?tab=reset", "", "https://astro.id/akun?tab=reset&token=abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PASSWORD_RESET_URL", tt.resetURL)
+			t.Setenv("APP_BASE_URL", tt.baseURL)
+			if got := PasswordResetLink("abc"); got != tt.want {
+				t.Fatalf("PasswordResetLink = %q, mau %q", got, tt.want)
+			}
+		})
+	}
+}
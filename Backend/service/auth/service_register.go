@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"astro-backend/constants"
+	"astro-backend/mailer"
+	"astro-backend/models"
+	adminRepo "astro-backend/repository/admin"
+	authRepo "astro-backend/repository/auth"
+	"astro-backend/utils"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RegisterService interface {
+	Register(name, email, noTlp, password string) (models.User, error)
+	VerifyEmail(token string) error
+	ResendVerification(email string) error
+}
+
+type registerService struct {
+	repo      adminRepo.UserRepository
+	tokenRepo authRepo.UserTokenRepository
+	mailer    mailer.Sender
+}
+
+func NewRegisterService(repo adminRepo.UserRepository, tokenRepo authRepo.UserTokenRepository, sender mailer.Sender) RegisterService {
+	return &registerService{repo, tokenRepo, sender}
+}
+
+// Register membuat akun guest baru. Role selalu Guest, apa pun yang dikirim
+// client, dan akun belum bisa login sebelum email diverifikasi.
+func (s *registerService) Register(name, email, noTlp, password string) (models.User, error) {
+	name = strings.TrimSpace(name)
+
+	if name == "" {
+		return models.User{}, errors.New("Nama wajib diisi")
+	}
+	email, err := utils.NormalizeEmail(email)
+	if err != nil {
+		return models.User{}, err
+	}
+	if err := utils.ValidatePassword(password, email, name); err != nil {
+		return models.User{}, err
+	}
+
+	if _, err := s.repo.FindByEmail(email); err == nil {
+		return models.User{}, adminRepo.ErrEmailExists
+	}
+
+	hashed, err := utils.HashPassword(password)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	verified := false
+	user := models.User{
+		Id:            primitive.NewObjectID(),
+		Name:          name,
+		Email:         email,
+		NoTlp:         noTlp,
+		Password:      hashed,
+		Role:          constants.RoleGuest,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		EmailVerified: &verified,
+	}
+
+	if err := s.repo.Create(user); err != nil {
+		return models.User{}, err
+	}
+
+	// akun tetap dibuat walau email gagal terkirim; user bisa minta kirim ulang
+	if err := s.sendVerification(user); err != nil {
+		log.Error().Err(err).Str("email", user.Email).Msg("gagal kirim email verifikasi")
+	}
+
+	user.Password = ""
+	return user, nil
+}
+
+func (s *registerService) VerifyEmail(token string) error {
+	if token == "" {
+		return errors.New("token wajib diisi")
+	}
+
+	consumed, err := s.tokenRepo.Consume(hashToken(token), constants.TokenPurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.MarkEmailVerified(consumed.UserID)
+}
+
+// ResendVerification tidak memberi tahu apakah email terdaftar atau tidak,
+// supaya endpoint ini tidak bisa dipakai untuk menebak akun.
+func (s *registerService) ResendVerification(email string) error {
+	user, err := s.repo.FindByEmail(strings.ToLower(strings.TrimSpace(email)))
+	if err != nil || isEmailVerified(user) {
+		return nil
+	}
+
+	if err := s.tokenRepo.InvalidateByUser(user.Id, constants.TokenPurposeEmailVerification); err != nil {
+		return err
+	}
+
+	return s.sendVerification(user)
+}
+
+func (s *registerService) sendVerification(user models.User) error {
+	raw, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	ttl := parseDurationEnv("EMAIL_VERIFICATION_TTL", 24*time.Hour)
+
+	err = s.tokenRepo.Create(models.UserToken{
+		Id:        primitive.NewObjectID(),
+		UserID:    user.Id,
+		Purpose:   constants.TokenPurposeEmailVerification,
+		TokenHash: hashToken(raw),
+		ExpiresAt: primitive.NewDateTimeFromTime(now.Add(ttl)),
+		CreatedAt: primitive.NewDateTimeFromTime(now),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/register/verify?token=%s", appBaseURL(), raw)
+	return s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verifikasi email akun Astro",
+		Body: fmt.Sprintf("Halo %s,\n\nKlik link berikut untuk memverifikasi email Anda:\n%s\n\nLink berlaku selama %s.\n",
+			user.Name, link, ttl),
+	})
+}
+
+// isEmailVerified menganggap akun tanpa field EmailVerified (akun lama atau
+// dibuat admin) sudah terverifikasi.
+func isEmailVerified(user models.User) bool {
+	return user.EmailVerified == nil || *user.EmailVerified
+}
+
+func newOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func appBaseURL() string {
+	if v := os.Getenv("APP_BASE_URL"); v != "" {
+		return strings.TrimRight(v, "/")
+	}
+	return "http://localhost:8080"
+}
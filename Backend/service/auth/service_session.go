@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	adminRepo "astro-backend/repository/admin"
+	authRepo "astro-backend/repository/auth"
+	"astro-backend/service/activityLog"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SessionService interface {
+	List(userID primitive.ObjectID) ([]models.Session, error)
+	Logout(user models.User, sessionID string, client ClientInfo) error
+	Revoke(user models.User, sessionID string, client ClientInfo) error
+	ForceLogout(userID string, client ClientInfo) (int64, error)
+}
+
+type sessionService struct {
+	repo        adminRepo.UserRepository
+	sessionRepo authRepo.SessionRepository
+	refreshRepo authRepo.RefreshTokenRepository
+	logSvc      activityLog.ActivityLogService
+}
+
+func NewSessionService(repo adminRepo.UserRepository, sessionRepo authRepo.SessionRepository, refreshRepo authRepo.RefreshTokenRepository, logSvc activityLog.ActivityLogService) SessionService {
+	return &sessionService{repo, sessionRepo, refreshRepo, logSvc}
+}
+
+func (s *sessionService) List(userID primitive.ObjectID) ([]models.Session, error) {
+	return s.sessionRepo.ListActiveByUser(userID)
+}
+
+// Logout mencabut sesi yang sedang dipakai.
+func (s *sessionService) Logout(user models.User, sessionID string, client ClientInfo) error {
+	if err := s.revokeOwned(user, sessionID, "logout"); err != nil {
+		return err
+	}
+
+	logAuthEvent(s.logSvc, client, models.ActivityLog{
+		UserID:     userRef(user),
+		UserEmail:  user.Email,
+		SessionID:  sessionID,
+		ActionType: constants.ActLogout,
+		ResourceID: user.Id.Hex(),
+		Message:    "logout",
+		Status:     constants.StatusSuccess,
+	})
+
+	return nil
+}
+
+// Revoke mencabut salah satu sesi milik user (mis. device yang hilang).
+func (s *sessionService) Revoke(user models.User, sessionID string, client ClientInfo) error {
+	if err := s.revokeOwned(user, sessionID, "dicabut oleh user"); err != nil {
+		return err
+	}
+
+	logSecurity(s.logSvc, client, models.ActivityLog{
+		UserID:     userRef(user),
+		UserEmail:  user.Email,
+		ActionType: constants.ActLogout,
+		Resource:   "sessions",
+		ResourceID: sessionID,
+		Message:    "sesi dicabut oleh user",
+		Status:     constants.StatusSuccess,
+	})
+
+	return nil
+}
+
+// ForceLogout dipakai admin untuk mencabut semua sesi milik user lain.
+func (s *sessionService) ForceLogout(userID string, client ClientInfo) (int64, error) {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := s.sessionRepo.RevokeAllByUser(user.Id, "force logout oleh admin")
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.refreshRepo.RevokeAllByUser(user.Id); err != nil {
+		return n, err
+	}
+
+	logSecurity(s.logSvc, client, models.ActivityLog{
+		UserEmail:  user.Email,
+		ActionType: constants.ActLogout,
+		ResourceID: user.Id.Hex(),
+		Message:    "force logout oleh admin",
+		Metadata:   primitive.M{"revoked_sessions": n},
+		Status:     constants.StatusSuccess,
+	})
+
+	return n, nil
+}
+
+func (s *sessionService) revokeOwned(user models.User, sessionID, reason string) error {
+	id, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return errors.New("ID sesi tidak valid")
+	}
+
+	session, err := s.sessionRepo.FindByID(id)
+	if err != nil || session.UserID != user.Id {
+		return errors.New("sesi tidak ditemukan")
+	}
+
+	return s.sessionRepo.Revoke(id, reason)
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"astro-backend/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestLogout(t *testing.T) {
+	f := newAuthFixture(t)
+	current, other := f.login(t), f.login(t)
+	svc := NewSessionService(f.users, f.sessions, f.refresh, nil)
+
+	_, claims, err := f.svc.Authenticate(current.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Logout(f.user, claims.SessionID, ClientInfo{}); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+
+	if _, _, err := f.svc.Authenticate(current.AccessToken); err == nil {
+		t.Fatal("access token masih diterima setelah logout")
+	}
+	if _, err := f.svc.Refresh(current.RefreshToken, ClientInfo{}); err == nil {
+		t.Fatal("refresh token masih diterima setelah logout")
+	}
+	if _, _, err := f.svc.Authenticate(other.AccessToken); err != nil {
+		t.Fatalf("sesi lain ikut berakhir: %v", err)
+	}
+
+	session, err := f.sessions.FindByID(mustObjectID(t, claims.SessionID))
+	if err != nil || session.RevokedReason != "logout" {
+		t.Fatalf("sesi %+v, err %v", session, err)
+	}
+}
+
+func TestRevokeOtherUsersSession(t *testing.T) {
+	f := newAuthFixture(t)
+	tokens := f.login(t)
+	svc := NewSessionService(f.users, f.sessions, f.refresh, nil)
+
+	_, claims, err := f.svc.Authenticate(tokens.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	intruder := models.User{Id: primitive.NewObjectID(), Email: "lain@example.com"}
+	if err := svc.Revoke(intruder, claims.SessionID, ClientInfo{}); err == nil {
+		t.Fatal("user lain bisa mencabut sesi yang bukan miliknya")
+	}
+	if err := svc.Revoke(f.user, "bukan-id", ClientInfo{}); err == nil {
+		t.Fatal("ID sesi tidak valid diterima")
+	}
+	if _, _, err := f.svc.Authenticate(tokens.AccessToken); err != nil {
+		t.Fatalf("sesi tercabut oleh user lain: %v", err)
+	}
+
+	if err := svc.Revoke(f.user, claims.SessionID, ClientInfo{}); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, _, err := f.svc.Authenticate(tokens.AccessToken); err == nil {
+		t.Fatal("access token masih diterima setelah sesi dicabut")
+	}
+}
+
+func TestForceLogout(t *testing.T) {
+	f := newAuthFixture(t)
+	first, second := f.login(t), f.login(t)
+	svc := NewSessionService(f.users, f.sessions, f.refresh, nil)
+
+	n, err := svc.ForceLogout(f.user.Id.Hex(), ClientInfo{})
+	if err != nil {
+		t.Fatalf("ForceLogout: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("%d sesi dicabut, want 2", n)
+	}
+
+	for _, tokens := range []TokenPair{first, second} {
+		if _, _, err := f.svc.Authenticate(tokens.AccessToken); err == nil {
+			t.Fatal("access token masih diterima setelah force logout")
+		}
+		if stored := f.refresh.stored(t, tokens.RefreshToken); stored.RevokedAt == nil {
+			t.Fatal("refresh token tidak dicabut")
+		}
+	}
+
+	if _, err := svc.ForceLogout(primitive.NewObjectID().Hex(), ClientInfo{}); err == nil {
+		t.Fatal("force logout user yang tidak ada harus gagal")
+	}
+}
+
+func mustObjectID(t *testing.T, hex string) primitive.ObjectID {
+	t.Helper()
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
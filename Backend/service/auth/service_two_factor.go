@@ -0,0 +1,226 @@
+package auth
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	adminRepo "astro-backend/repository/admin"
+	authRepo "astro-backend/repository/auth"
+	"astro-backend/service/activityLog"
+	"astro-backend/utils"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+const recoveryCodeCount = 10
+
+type TwoFactorService interface {
+	Setup(user models.User) (secret, provisioningURI string, err error)
+	Enable(user models.User, code string, client ClientInfo) ([]string, error)
+	Disable(user models.User, code, recoveryCode string, client ClientInfo) error
+	RequiredRoles() ([]string, error)
+	SetRequiredRoles(roles []string) error
+}
+
+type twoFactorService struct {
+	repo        adminRepo.UserRepository
+	settingRepo authRepo.SecuritySettingRepository
+	logSvc      activityLog.ActivityLogService
+}
+
+func NewTwoFactorService(repo adminRepo.UserRepository, settingRepo authRepo.SecuritySettingRepository, logSvc activityLog.ActivityLogService) TwoFactorService {
+	return &twoFactorService{repo, settingRepo, logSvc}
+}
+
+// Setup membuat secret TOTP baru yang belum aktif sampai dikonfirmasi lewat Enable.
+func (s *twoFactorService) Setup(user models.User) (string, string, error) {
+	if user.TwoFactorEnabled {
+		return "", "", errors.New("2FA sudah aktif")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.repo.SetPendingTOTPSecret(user.Id, secret); err != nil {
+		return "", "", err
+	}
+
+	return secret, utils.TOTPProvisioningURI(totpIssuer(), user.Email, secret), nil
+}
+
+// Enable mengaktifkan 2FA setelah user membuktikan aplikasi authenticator-nya
+// menghasilkan kode yang benar. Recovery code hanya dikembalikan sekali ini.
+func (s *twoFactorService) Enable(user models.User, code string, client ClientInfo) ([]string, error) {
+	if user.TwoFactorEnabled {
+		return nil, errors.New("2FA sudah aktif")
+	}
+	if user.TOTPPendingSecret == "" {
+		return nil, errors.New("jalankan setup 2FA terlebih dahulu")
+	}
+
+	step, ok := utils.ValidateTOTP(user.TOTPPendingSecret, code, time.Now(), 1)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+	// step dicatat seperti verifySecondFactor supaya kode yang dipakai untuk
+	// mengaktifkan tidak bisa dipakai lagi untuk login
+	ok, err := s.repo.UseTOTPStep(user.Id, step)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.EnableTwoFactor(user.Id, user.TOTPPendingSecret, hashes); err != nil {
+		return nil, err
+	}
+
+	logSecurity(s.logSvc, client, models.ActivityLog{
+		UserID:     userRef(user),
+		UserEmail:  user.Email,
+		ActionType: constants.ActUpdate,
+		ResourceID: user.Id.Hex(),
+		Message:    "2FA diaktifkan",
+		Status:     constants.StatusSuccess,
+	})
+
+	return codes, nil
+}
+
+func (s *twoFactorService) Disable(user models.User, code, recoveryCode string, client ClientInfo) error {
+	if !user.TwoFactorEnabled {
+		return errors.New("2FA belum aktif")
+	}
+	if roleRequiresTwoFactor(s.settingRepo, user.Role) {
+		return errors.New("2FA wajib untuk role Anda dan tidak bisa dinonaktifkan")
+	}
+
+	ok, err := verifySecondFactor(s.repo, user, code, recoveryCode)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	if err := s.repo.DisableTwoFactor(user.Id); err != nil {
+		return err
+	}
+
+	logSecurity(s.logSvc, client, models.ActivityLog{
+		UserID:     userRef(user),
+		UserEmail:  user.Email,
+		ActionType: constants.ActUpdate,
+		ResourceID: user.Id.Hex(),
+		Message:    "2FA dinonaktifkan",
+		Status:     constants.StatusSuccess,
+	})
+
+	return nil
+}
+
+func (s *twoFactorService) RequiredRoles() ([]string, error) {
+	setting, err := s.settingRepo.Get()
+	if err != nil {
+		return nil, err
+	}
+	if setting.Require2FARoles == nil {
+		return []string{}, nil
+	}
+	return setting.Require2FARoles, nil
+}
+
+func (s *twoFactorService) SetRequiredRoles(roles []string) error {
+	cleaned := []string{}
+	for _, role := range roles {
+		role = strings.TrimSpace(role)
+		if _, ok := constants.RolePermissions[role]; !ok {
+			return errors.New("role tidak dikenal: " + role)
+		}
+		cleaned = append(cleaned, role)
+	}
+
+	setting, err := s.settingRepo.Get()
+	if err != nil {
+		return err
+	}
+	setting.Require2FARoles = cleaned
+
+	return s.settingRepo.Save(setting)
+}
+
+// verifySecondFactor menerima kode TOTP atau recovery code. Kode TOTP yang
+// sudah pernah dipakai ditolak, dan recovery code langsung hangus setelah dipakai.
+func verifySecondFactor(repo adminRepo.UserRepository, user models.User, code, recoveryCode string) (bool, error) {
+	if recoveryCode != "" {
+		return repo.UseRecoveryCode(user.Id, hashRecoveryCode(recoveryCode))
+	}
+
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now(), 1)
+	if !ok {
+		return false, nil
+	}
+
+	return repo.UseTOTPStep(user.Id, step)
+}
+
+func roleRequiresTwoFactor(settingRepo authRepo.SecuritySettingRepository, role string) bool {
+	if settingRepo == nil {
+		return false
+	}
+
+	setting, err := settingRepo.Get()
+	if err != nil {
+		return false
+	}
+
+	for _, r := range setting.Require2FARoles {
+		if strings.EqualFold(r, strings.TrimSpace(role)) {
+			return true
+		}
+	}
+	return false
+}
+
+// generateRecoveryCodes membuat recovery code berformat "xxxxx-xxxxx" beserta hash-nya.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := hex.EncodeToString(b)
+		code := raw[:5] + "-" + raw[5:]
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return hashToken(normalized)
+}
+
+func totpIssuer() string {
+	if v := os.Getenv("TOTP_ISSUER"); v != "" {
+		return v
+	}
+	return "Astro"
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"astro-backend/models"
+	"astro-backend/utils"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (m *memUsers) UseTOTPStep(id primitive.ObjectID, step int64) (bool, error) {
+	used := false
+	m.update(id, func(u *models.User) {
+		if u.TOTPLastStep < step {
+			u.TOTPLastStep, used = step, true
+		}
+	})
+	return used, nil
+}
+
+func (m *memUsers) EnableTwoFactor(id primitive.ObjectID, secret string, recoveryHashes []string) error {
+	m.update(id, func(u *models.User) {
+		u.TwoFactorEnabled, u.TOTPSecret, u.TOTPPendingSecret, u.RecoveryCodes = true, secret, "", recoveryHashes
+	})
+	return nil
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != recoveryCodeCount || len(hashes) != recoveryCodeCount {
+		t.Fatalf("%d kode, %d hash; want %d", len(codes), len(hashes), recoveryCodeCount)
+	}
+
+	format := regexp.MustCompile(`^[0-9a-f]{5}-[0-9a-f]{5}$`)
+	seen := map[string]bool{}
+	for i, code := range codes {
+		if !format.MatchString(code) {
+			t.Errorf("format kode %q", code)
+		}
+		if seen[code] {
+			t.Errorf("kode %q duplikat", code)
+		}
+		seen[code] = true
+
+		if hashes[i] != hashRecoveryCode(code) {
+			t.Errorf("hash kode %q tidak cocok", code)
+		}
+		if hashes[i] == code {
+			t.Errorf("kode %q disimpan tanpa hash", code)
+		}
+	}
+}
+
+func TestHashRecoveryCodeNormalizes(t *testing.T) {
+	want := hashRecoveryCode("abcde-12345")
+
+	// user boleh mengetik tanpa tanda hubung, dengan spasi atau huruf besar
+	for _, input := range []string{"abcde12345", "ABCDE-12345", "abcde 12345", " abcde-12345"} {
+		if got := hashRecoveryCode(input); got != want {
+			t.Errorf("hashRecoveryCode(%q) berbeda dari abcde-12345", input)
+		}
+	}
+
+	if hashRecoveryCode("abcde-12346") == want {
+		t.Error("kode berbeda menghasilkan hash yang sama")
+	}
+}
+
+// TestEnableConsumesTOTPStep memastikan kode yang dipakai untuk mengaktifkan
+// 2FA tidak bisa dipakai ulang sebagai second factor saat login.
+func TestEnableConsumesTOTPStep(t *testing.T) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{Id: primitive.NewObjectID(), Email: "budi@example.com", TOTPPendingSecret: secret}
+	users := &memUsers{users: []models.User{user}}
+	svc := NewTwoFactorService(users, nil, nil)
+
+	code, err := utils.TOTPCode(secret, utils.TOTPStep(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// request Enable kedua dengan kode yang sama ditolak
+	if _, err := svc.Enable(user, code, ClientInfo{}); err != nil {
+		t.Fatalf("Enable: %v", err)
+	}
+	if _, err := svc.Enable(user, code, ClientInfo{}); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("Enable ulang dengan kode yang sama: err %v, want ErrInvalidTwoFactorCode", err)
+	}
+
+	enabled, _ := users.FindByID(user.Id.Hex())
+	if !enabled.TwoFactorEnabled || enabled.TOTPLastStep == 0 {
+		t.Fatalf("2FA aktif %v, step terakhir %d", enabled.TwoFactorEnabled, enabled.TOTPLastStep)
+	}
+
+	ok, err := verifySecondFactor(users, enabled, code, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("kode aktivasi diterima lagi sebagai second factor")
+	}
+}
@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"astro-backend/models"
+	authRepo "astro-backend/repository/auth"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+
+	// token sementara antara login password dan langkah 2FA
+	TokenTypeMFA      = "mfa"
+	TokenTypeMFASetup = "mfa_setup"
+)
+
+// TokenPair adalah pasangan token yang dikirim ke client setelah login / refresh.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"` // detik sampai access token kedaluwarsa
+}
+
+// Claims adalah isi JWT yang diterbitkan service auth.
+type Claims struct {
+	Email     string `json:"email,omitempty"`
+	Role      string `json:"role,omitempty"`
+	Type      string `json:"typ"`
+	SessionID string `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+type tokenConfig struct {
+	secret     []byte
+	issuer     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+var (
+	tokenCfg     tokenConfig
+	tokenCfgOnce sync.Once
+)
+
+// loadTokenConfig membaca konfigurasi JWT dari env satu kali saja, supaya
+// semua instance AuthService memakai secret yang sama.
+func loadTokenConfig() tokenConfig {
+	tokenCfgOnce.Do(func() {
+		secret := []byte(os.Getenv("JWT_SECRET"))
+		if len(secret) == 0 {
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				panic(err)
+			}
+			log.Warn().Msg("JWT_SECRET kosong, memakai secret acak (token tidak valid setelah restart)")
+		}
+
+		issuer := os.Getenv("JWT_ISSUER")
+		if issuer == "" {
+			issuer = "astro-backend"
+		}
+
+		tokenCfg = tokenConfig{
+			secret:     secret,
+			issuer:     issuer,
+			accessTTL:  parseDurationEnv("JWT_ACCESS_TTL", 15*time.Minute),
+			refreshTTL: parseDurationEnv("JWT_REFRESH_TTL", 7*24*time.Hour),
+		}
+	})
+	return tokenCfg
+}
+
+func signToken(claims Claims) (string, error) {
+	cfg := loadTokenConfig()
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(cfg.secret)
+}
+
+func newClaims(subject, tokenType string, ttl time.Duration) Claims {
+	cfg := loadTokenConfig()
+	now := time.Now()
+	return Claims{
+		Type: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   subject,
+			Issuer:    cfg.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+}
+
+// parseToken memverifikasi signature, masa berlaku dan tipe token.
+func parseToken(raw string, tokenTypes ...string) (*Claims, error) {
+	cfg := loadTokenConfig()
+
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (any, error) {
+		return cfg.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(cfg.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, errors.New("token tidak valid atau sudah kedaluwarsa")
+	}
+
+	for _, t := range tokenTypes {
+		if claims.Type == t {
+			return claims, nil
+		}
+	}
+
+	return nil, errors.New("tipe token tidak sesuai")
+}
+
+// issueTokenPair menerbitkan access + refresh token untuk sesi sessionID dan
+// menyimpan hash refresh token dengan ID refreshID.
+func issueTokenPair(refreshRepo authRepo.RefreshTokenRepository, user models.User, sessionID, refreshID primitive.ObjectID) (TokenPair, error) {
+	cfg := loadTokenConfig()
+
+	accessClaims := newClaims(user.Id.Hex(), TokenTypeAccess, cfg.accessTTL)
+	accessClaims.Email = user.Email
+	accessClaims.Role = user.Role
+	accessClaims.SessionID = sessionID.Hex()
+
+	accessToken, err := signToken(accessClaims)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	refreshClaims := newClaims(user.Id.Hex(), TokenTypeRefresh, cfg.refreshTTL)
+	refreshClaims.SessionID = sessionID.Hex()
+	refreshToken, err := signToken(refreshClaims)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	err = refreshRepo.Create(models.RefreshToken{
+		Id:        refreshID,
+		UserID:    user.Id,
+		SessionID: sessionID,
+		TokenHash: hashToken(refreshClaims.ID),
+		ExpiresAt: primitive.NewDateTimeFromTime(refreshClaims.ExpiresAt.Time),
+		CreatedAt: primitive.NewDateTimeFromTime(time.Now()),
+	})
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(cfg.accessTTL.Seconds()),
+	}, nil
+}
+
+// issueChallengeToken menerbitkan token berumur pendek untuk langkah 2FA.
+func issueChallengeToken(user models.User, tokenType string) (string, error) {
+	ttl := parseDurationEnv("MFA_CHALLENGE_TTL", 5*time.Minute)
+	if tokenType == TokenTypeMFASetup {
+		ttl = parseDurationEnv("MFA_SETUP_TTL", 15*time.Minute)
+	}
+
+	claims := newClaims(user.Id.Hex(), tokenType, ttl)
+	claims.Email = user.Email
+	claims.Role = user.Role
+	return signToken(claims)
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func parseDurationEnv(k string, def time.Duration) time.Duration {
+	if v := os.Getenv(k); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return def
+}
+
+func parseIntEnv(k string, def int) int {
+	if v := os.Getenv(k); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			return i
+		}
+	}
+	return def
+}
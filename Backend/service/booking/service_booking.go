@@ -0,0 +1,342 @@
+package booking
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	adminRepo "astro-backend/repository/admin"
+	bookingRepo "astro-backend/repository/booking"
+	"astro-backend/service/activityLog"
+	"astro-backend/utils"
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrBookingNotFound  = errors.New("Booking tidak ditemukan")
+	ErrRoomNotAvailable = errors.New("Kamar tidak tersedia pada tanggal tersebut")
+)
+
+// CreateBookingInput adalah data booking dari client. Tanggal memakai format YYYY-MM-DD.
+type CreateBookingInput struct {
+	RoomID   string `json:"room_id"`
+	CheckIn  string `json:"check_in"`
+	CheckOut string `json:"check_out"`
+	Guests   int    `json:"guests"`
+	Notes    string `json:"notes"`
+	// QuoteID opsional; kalau diisi, harga mengikuti quote tersebut.
+	QuoteID string `json:"quote_id"`
+}
+
+// AvailabilityQuery adalah kriteria pencarian kamar kosong dari client.
+type AvailabilityQuery struct {
+	CheckIn     string
+	CheckOut    string
+	Guests      int
+	RoomTypeID  string
+	FacilityIDs []string
+	MinPrice    *float64
+	MaxPrice    *float64
+}
+
+// Actor adalah user yang melakukan request. Staff (punya permission
+// booking) boleh melihat dan membatalkan booking milik siapa saja.
+type Actor struct {
+	User  models.User
+	Staff bool
+}
+
+type BookingService interface {
+	Create(actor Actor, input CreateBookingInput) (models.Booking, error)
+	GetAll(actor Actor, filter bookingRepo.BookingFilter) ([]models.Booking, error)
+	GetByID(actor Actor, id string) (models.Booking, error)
+	Cancel(actor Actor, id, reason string) (models.Booking, error)
+	Modify(actor Actor, id string, input ModifyBookingInput) (before, after models.Booking, err error)
+	SearchAvailability(query AvailabilityQuery) ([]models.Room, error)
+}
+
+type bookingService struct {
+	repo      bookingRepo.BookingRepository
+	nightRepo bookingRepo.RoomNightRepository
+	roomRepo  adminRepo.RoomRepository
+	promoRepo adminRepo.PromoCodeRepository
+	quotes    QuoteService
+	policies  CancellationService
+	logSvc    activityLog.ActivityLogService
+}
+
+func NewBookingService(repo bookingRepo.BookingRepository, nightRepo bookingRepo.RoomNightRepository, roomRepo adminRepo.RoomRepository, promoRepo adminRepo.PromoCodeRepository, quotes QuoteService, policies CancellationService, logSvc activityLog.ActivityLogService) BookingService {
+	return &bookingService{repo, nightRepo, roomRepo, promoRepo, quotes, policies, logSvc}
+}
+
+func (s *bookingService) Create(actor Actor, input CreateBookingInput) (models.Booking, error) {
+	booking, room, err := prepareBooking(s.repo, s.roomRepo, s.quotes, actor, input)
+	if err != nil {
+		return models.Booking{}, err
+	}
+	checkIn, checkOut := booking.CheckIn.Time().UTC(), booking.CheckOut.Time().UTC()
+
+	// Kunci inventori per malam dulu. Unique index room_night yang menjamin
+	// dua request bersamaan tidak mendapat kamar yang sama; HasOverlap di
+	// atas hanya pengecekan cepat (dan untuk booking lama sebelum room_night ada).
+	if err := s.nightRepo.Reserve(room.Id, booking.Id, checkIn, checkOut); err != nil {
+		if errors.Is(err, bookingRepo.ErrRoomNightTaken) {
+			return models.Booking{}, ErrRoomNotAvailable
+		}
+		return models.Booking{}, err
+	}
+
+	if err := s.repo.Create(booking); err != nil {
+		_ = s.nightRepo.Release(booking.Id)
+		return models.Booking{}, err
+	}
+
+	booking.Room = &room
+	return booking, nil
+}
+
+// GetAll mengembalikan booking milik actor; staff bisa melihat semua booking
+// dan memakai filter user_id.
+func (s *bookingService) GetAll(actor Actor, filter bookingRepo.BookingFilter) ([]models.Booking, error) {
+	if !actor.Staff {
+		filter.UserID = &actor.User.Id
+	}
+	return s.repo.GetAll(filter)
+}
+
+func (s *bookingService) GetByID(actor Actor, id string) (models.Booking, error) {
+	booking, err := s.repo.GetByID(id)
+	if err != nil {
+		return models.Booking{}, ErrBookingNotFound
+	}
+
+	// booking milik user lain dianggap tidak ada supaya ID tidak bisa ditebak
+	if !actor.Staff && booking.UserID != actor.User.Id {
+		return models.Booking{}, ErrBookingNotFound
+	}
+
+	return booking, nil
+}
+
+// Cancel membatalkan booking. Tamu hanya bisa membatalkan sebelum tanggal
+// check-in; staff bisa kapan saja selama booking masih aktif. Refund dan
+// denda dihitung dari cancellation policy tipe kamar, disimpan di booking
+// dan dicatat sebagai activity log REFUND kalau booking sudah dibayar.
+func (s *bookingService) Cancel(actor Actor, id, reason string) (models.Booking, error) {
+	booking, err := s.GetByID(actor, id)
+	if err != nil {
+		return models.Booking{}, err
+	}
+
+	if !actor.Staff && !booking.CheckIn.Time().After(utils.Today()) {
+		return models.Booking{}, errors.New("Booking tidak bisa dibatalkan pada atau setelah tanggal check-in")
+	}
+
+	now := time.Now()
+	result, err := s.policies.EvaluateBooking(booking, now)
+	if err != nil {
+		return models.Booking{}, err
+	}
+
+	reason = strings.TrimSpace(reason)
+	if err := s.repo.Cancel(booking.Id, reason, result); err != nil {
+		return models.Booking{}, err
+	}
+	// booking sudah batal; kalau gagal melepas inventori cukup dicatat
+	if err := s.nightRepo.Release(booking.Id); err != nil {
+		log.Error().Err(err).Str("booking_id", booking.Id.Hex()).Msg("gagal melepas room_night")
+	}
+
+	cancelledAt := primitive.NewDateTimeFromTime(now)
+	booking.Status = constants.BookingStatusCancelled
+	booking.CancelledAt = &cancelledAt
+	booking.CancelReason = reason
+	booking.Cancellation = &result
+
+	if result.AmountPaid > 0 {
+		s.logRefund(actor, booking, result)
+	}
+
+	return booking, nil
+}
+
+// logRefund mencatat hasil refund; categorize memasukkannya ke CRITICAL.
+func (s *bookingService) logRefund(actor Actor, booking models.Booking, result models.CancellationResult) {
+	if s.logSvc == nil {
+		return
+	}
+
+	userID := actor.User.Id
+	entry := models.ActivityLog{
+		UserID:     &userID,
+		UserEmail:  actor.User.Email,
+		ActionType: constants.ActRefund,
+		Endpoint:   "/api/bookings/" + booking.Id.Hex() + "/cancel",
+		Method:     "POST",
+		Resource:   "bookings",
+		ResourceID: booking.Id.Hex(),
+		Message:    "refund pembatalan booking",
+		Metadata: primitive.M{
+			"rule":                result.Rule,
+			"amount_paid":         result.AmountPaid,
+			"penalty":             result.Penalty,
+			"refund":              result.Refund,
+			"days_before_arrival": result.DaysBeforeArrival,
+			"booking_user_id":     booking.UserID.Hex(),
+		},
+		Status: constants.StatusSuccess,
+	}
+	if err := s.logSvc.Log(context.Background(), entry); err != nil {
+		log.Error().Err(err).Str("booking_id", booking.Id.Hex()).Msg("activity log failed")
+	}
+}
+
+// SearchAvailability mengembalikan kamar yang kosong selama rentang tanggal
+// dan memenuhi filter tamu, tipe kamar, fasilitas dan harga.
+func (s *bookingService) SearchAvailability(query AvailabilityQuery) ([]models.Room, error) {
+	checkIn, checkOut, err := parseStay(query.CheckIn, query.CheckOut)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := adminRepo.RoomSearchFilter{
+		CheckIn:  checkIn,
+		CheckOut: checkOut,
+		Guests:   query.Guests,
+		MinPrice: query.MinPrice,
+		MaxPrice: query.MaxPrice,
+	}
+
+	if query.RoomTypeID != "" {
+		oid, err := primitive.ObjectIDFromHex(query.RoomTypeID)
+		if err != nil {
+			return nil, errors.New("room_type_id tidak valid")
+		}
+		filter.RoomTypeID = &oid
+	}
+
+	for _, id := range query.FacilityIDs {
+		oid, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, errors.New("invalid facility ID: " + id)
+		}
+		filter.FacilityIDs = append(filter.FacilityIDs, oid)
+	}
+
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return nil, errors.New("min_price tidak boleh lebih besar dari max_price")
+	}
+
+	return s.roomRepo.SearchAvailable(filter)
+}
+
+// prepareBooking memvalidasi input dan menyusun booking (belum disimpan)
+// beserta harganya. Harga diambil dari quote kalau input.QuoteID diisi,
+// kalau tidak dihitung ulang dengan aturan yang sama; PricePerNight diisi
+// rata-rata harga per malam. Dipakai untuk booking langsung maupun hold.
+func prepareBooking(repo bookingRepo.BookingRepository, roomRepo adminRepo.RoomRepository, quotes QuoteService, actor Actor, input CreateBookingInput) (models.Booking, models.Room, error) {
+	checkIn, checkOut, err := parseStay(input.CheckIn, input.CheckOut)
+	if err != nil {
+		return models.Booking{}, models.Room{}, err
+	}
+
+	if input.Guests <= 0 {
+		return models.Booking{}, models.Room{}, errors.New("Jumlah tamu minimal 1")
+	}
+
+	room, err := roomRepo.GetByID(input.RoomID)
+	if err != nil {
+		return models.Booking{}, models.Room{}, errors.New("Kamar tidak ditemukan")
+	}
+	if !room.Availability {
+		return models.Booking{}, models.Room{}, ErrRoomNotAvailable
+	}
+	if room.Capacity > 0 && input.Guests > room.Capacity {
+		return models.Booking{}, models.Room{}, errors.New("Jumlah tamu melebihi kapasitas kamar")
+	}
+
+	overlap, err := repo.HasOverlap(room.Id, checkIn, checkOut)
+	if err != nil {
+		return models.Booking{}, models.Room{}, err
+	}
+	if overlap {
+		return models.Booking{}, models.Room{}, ErrRoomNotAvailable
+	}
+
+	var quote models.Quote
+	if input.QuoteID != "" {
+		quote, err = quotes.ForBooking(actor, input.QuoteID, room.Id, checkIn, checkOut, input.Guests)
+	} else {
+		quote, err = quotes.Calculate(room, checkIn, checkOut, input.Guests)
+	}
+	if err != nil {
+		return models.Booking{}, models.Room{}, err
+	}
+
+	nights := utils.Nights(checkIn, checkOut)
+	now := primitive.NewDateTimeFromTime(time.Now())
+	booking := models.Booking{
+		Id:            primitive.NewObjectID(),
+		UserID:        actor.User.Id,
+		RoomID:        room.Id,
+		CheckIn:       primitive.NewDateTimeFromTime(checkIn),
+		CheckOut:      primitive.NewDateTimeFromTime(checkOut),
+		Nights:        nights,
+		Guests:        input.Guests,
+		Status:        constants.BookingStatusPending,
+		PricePerNight: roundMoney(quote.Subtotal / float64(nights)),
+		Subtotal:      quote.Subtotal,
+		ServiceCharge: quote.ServiceCharge,
+		Tax:           quote.Tax,
+		TotalPrice:    quote.GrandTotal,
+		NightlyRates:  quote.NightlyRates,
+		Notes:         strings.TrimSpace(input.Notes),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if !quote.Id.IsZero() {
+		booking.QuoteID = &quote.Id
+	}
+
+	return booking, room, nil
+}
+
+// parseStay memvalidasi tanggal menginap: check-in tidak boleh di masa lalu,
+// check-out setelah check-in, dan lama menginap maksimal BOOKING_MAX_NIGHTS.
+func parseStay(checkInStr, checkOutStr string) (time.Time, time.Time, error) {
+	checkIn, err := utils.ParseDate(checkInStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("check_in: " + err.Error())
+	}
+	checkOut, err := utils.ParseDate(checkOutStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("check_out: " + err.Error())
+	}
+
+	if checkIn.Before(utils.Today()) {
+		return time.Time{}, time.Time{}, errors.New("Tanggal check-in sudah lewat")
+	}
+	if !checkOut.After(checkIn) {
+		return time.Time{}, time.Time{}, errors.New("Tanggal check-out harus setelah check-in")
+	}
+
+	if maxNights := maxStayNights(); utils.Nights(checkIn, checkOut) > maxNights {
+		return time.Time{}, time.Time{}, errors.New("Lama menginap maksimal " + strconv.Itoa(maxNights) + " malam")
+	}
+
+	return checkIn, checkOut, nil
+}
+
+// maxStayNights dibaca dari BOOKING_MAX_NIGHTS (default 30).
+func maxStayNights() int {
+	if v, err := strconv.Atoi(os.Getenv("BOOKING_MAX_NIGHTS")); err == nil && v > 0 {
+		return v
+	}
+	return 30
+}
@@ -0,0 +1,245 @@
+package booking
+
+import (
+	"astro-backend/models"
+	adminRepo "astro-backend/repository/admin"
+	bookingRepo "astro-backend/repository/booking"
+	"astro-backend/utils"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memRoomNights meniru koleksi room_night beserta unique index
+// (room_id, date): insert malam yang sudah ada gagal, dan malam yang sempat
+// masuk untuk booking itu dihapus lagi seperti di repository asli.
+type memRoomNights struct {
+	mu     sync.Mutex
+	nights map[string]primitive.ObjectID // room_id|date -> booking_id
+}
+
+func newMemRoomNights() *memRoomNights {
+	return &memRoomNights{nights: map[string]primitive.ObjectID{}}
+}
+
+func nightKey(roomID primitive.ObjectID, d time.Time) string {
+	return roomID.Hex() + "|" + d.Format(utils.DateLayout)
+}
+
+func (m *memRoomNights) Reserve(roomID, bookingID primitive.ObjectID, checkIn, checkOut time.Time) error {
+	var dates []time.Time
+	for d := checkIn; d.Before(checkOut); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d)
+	}
+	return m.ReserveDates(roomID, bookingID, dates)
+}
+
+func (m *memRoomNights) ReserveDates(roomID, bookingID primitive.ObjectID, dates []time.Time) error {
+	var inserted []string
+	for _, d := range dates {
+		// InsertMany tidak atomik: beri kesempatan goroutine lain menyela
+		runtime.Gosched()
+
+		m.mu.Lock()
+		key := nightKey(roomID, d)
+		if _, taken := m.nights[key]; taken {
+			for _, k := range inserted {
+				delete(m.nights, k)
+			}
+			m.mu.Unlock()
+			return bookingRepo.ErrRoomNightTaken
+		}
+		m.nights[key] = bookingID
+		inserted = append(inserted, key)
+		m.mu.Unlock()
+	}
+	return nil
+}
+
+func (m *memRoomNights) Release(bookingID primitive.ObjectID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for k, id := range m.nights {
+		if id == bookingID {
+			delete(m.nights, k)
+		}
+	}
+	return nil
+}
+
+func (m *memRoomNights) ReleaseDates(roomID, bookingID primitive.ObjectID, dates []time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, d := range dates {
+		if key := nightKey(roomID, d); m.nights[key] == bookingID {
+			delete(m.nights, key)
+		}
+	}
+	return nil
+}
+
+func (m *memRoomNights) EnsureIndexes() error { return nil }
+
+// raceBookingRepo selalu melaporkan tidak ada overlap, seperti saat semua
+// request membaca koleksi booking sebelum ada yang tersimpan.
+type raceBookingRepo struct {
+	bookingRepo.BookingRepository
+	mu      sync.Mutex
+	created []models.Booking
+}
+
+func (r *raceBookingRepo) HasOverlap(primitive.ObjectID, time.Time, time.Time) (bool, error) {
+	return false, nil
+}
+
+func (r *raceBookingRepo) Create(booking models.Booking) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.created = append(r.created, booking)
+	return nil
+}
+
+type stubRoomRepo struct {
+	adminRepo.RoomRepository
+	room models.Room
+}
+
+func (r stubRoomRepo) GetByID(id string) (models.Room, error) {
+	if id != r.room.Id.Hex() {
+		return models.Room{}, errors.New("room not found")
+	}
+	return r.room, nil
+}
+
+type stubQuotes struct {
+	QuoteService
+}
+
+func (stubQuotes) Calculate(room models.Room, checkIn, checkOut time.Time, guests int) (models.Quote, error) {
+	total := room.PricePerNight * float64(utils.Nights(checkIn, checkOut))
+	return models.Quote{Subtotal: total, GrandTotal: total}, nil
+}
+
+// TestCreateConcurrentOverlap menembakkan banyak booking bersamaan untuk
+// satu kamar. Semua rentang tanggal memuat satu malam yang sama, jadi tepat
+// satu booking boleh berhasil dan inventori tidak boleh punya malam ganda.
+func TestCreateConcurrentOverlap(t *testing.T) {
+	const workers = 50
+
+	room := models.Room{Id: primitive.NewObjectID(), PricePerNight: 500000, Capacity: 2, Availability: true}
+	nights := newMemRoomNights()
+	bookings := &raceBookingRepo{}
+	svc := NewBookingService(bookings, nights, stubRoomRepo{room: room}, nil, stubQuotes{}, nil, nil)
+
+	// semua rentang memuat malam base+3
+	base := utils.Today().AddDate(0, 0, 30)
+	actor := Actor{User: models.User{Id: primitive.NewObjectID()}}
+
+	var (
+		wg       sync.WaitGroup
+		start    = make(chan struct{})
+		mu       sync.Mutex
+		success  []models.Booking
+		failures []error
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			checkIn := base.AddDate(0, 0, i%4)
+			checkOut := base.AddDate(0, 0, 4+i%3)
+			input := CreateBookingInput{
+				RoomID:   room.Id.Hex(),
+				CheckIn:  checkIn.Format(utils.DateLayout),
+				CheckOut: checkOut.Format(utils.DateLayout),
+				Guests:   1,
+			}
+
+			<-start
+			booking, err := svc.Create(actor, input)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failures = append(failures, err)
+				return
+			}
+			success = append(success, booking)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	if len(success) != 1 {
+		t.Fatalf("%d booking berhasil, want tepat 1", len(success))
+	}
+	for _, err := range failures {
+		if !errors.Is(err, ErrRoomNotAvailable) {
+			t.Errorf("error %v, want ErrRoomNotAvailable", err)
+		}
+	}
+	if len(bookings.created) != 1 || bookings.created[0].Id != success[0].Id {
+		t.Errorf("%d booking tersimpan, want hanya pemenangnya", len(bookings.created))
+	}
+
+	// inventori hanya berisi malam milik pemenang, satu dokumen per malam
+	winner := success[0]
+	if len(nights.nights) != winner.Nights {
+		t.Errorf("%d malam terkunci, want %d", len(nights.nights), winner.Nights)
+	}
+	for key, id := range nights.nights {
+		if id != winner.Id {
+			t.Errorf("malam %s milik booking yang gagal", key)
+		}
+	}
+	for d := winner.CheckIn.Time().UTC(); d.Before(winner.CheckOut.Time().UTC()); d = d.AddDate(0, 0, 1) {
+		if _, ok := nights.nights[nightKey(room.Id, d)]; !ok {
+			t.Errorf("malam %s milik pemenang tidak terkunci", d.Format(utils.DateLayout))
+		}
+	}
+}
+
+// TestCreateConcurrentDisjoint memastikan booking yang tidak bentrok tetap
+// berhasil semua walaupun datang bersamaan.
+func TestCreateConcurrentDisjoint(t *testing.T) {
+	const workers = 20
+
+	room := models.Room{Id: primitive.NewObjectID(), PricePerNight: 500000, Capacity: 2, Availability: true}
+	nights := newMemRoomNights()
+	svc := NewBookingService(&raceBookingRepo{}, nights, stubRoomRepo{room: room}, nil, stubQuotes{}, nil, nil)
+
+	base := utils.Today().AddDate(0, 0, 30)
+	actor := Actor{User: models.User{Id: primitive.NewObjectID()}}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := svc.Create(actor, CreateBookingInput{
+				RoomID:   room.Id.Hex(),
+				CheckIn:  base.AddDate(0, 0, 2*i).Format(utils.DateLayout),
+				CheckOut: base.AddDate(0, 0, 2*i+2).Format(utils.DateLayout),
+				Guests:   1,
+			})
+			if err != nil {
+				errs <- fmt.Errorf("booking %d: %w", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	if len(nights.nights) != 2*workers {
+		t.Errorf("%d malam terkunci, want %d", len(nights.nights), 2*workers)
+	}
+}
@@ -0,0 +1,105 @@
+package booking
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	adminRepo "astro-backend/repository/admin"
+	"astro-backend/utils"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CancellationService interface {
+	// Evaluate menghitung refund dan denda untuk pembatalan pada cancelledAt
+	// atas menginap yang check-in pada arrival. firstNight adalah harga malam
+	// pertama (dipakai untuk denda FIRST_NIGHT).
+	Evaluate(policy *models.CancellationPolicy, arrival, cancelledAt time.Time, amountPaid, firstNight float64) models.CancellationResult
+	// EvaluateBooking mengambil policy dari tipe kamar booking lalu memanggil Evaluate.
+	EvaluateBooking(booking models.Booking, cancelledAt time.Time) (models.CancellationResult, error)
+}
+
+type cancellationService struct {
+	roomRepo     adminRepo.RoomRepository
+	roomTypeRepo adminRepo.RoomTypeRepository
+}
+
+func NewCancellationService(roomRepo adminRepo.RoomRepository, roomTypeRepo adminRepo.RoomTypeRepository) CancellationService {
+	return &cancellationService{roomRepo, roomTypeRepo}
+}
+
+// Evaluate menghitung hari sebelum kedatangan menurut tanggal hotel
+// (HOTEL_TIMEZONE). Denda tidak pernah melebihi jumlah yang dibayar.
+func (*cancellationService) Evaluate(policy *models.CancellationPolicy, arrival, cancelledAt time.Time, amountPaid, firstNight float64) models.CancellationResult {
+	result := models.CancellationResult{
+		Policy:            policy,
+		DaysBeforeArrival: utils.Nights(utils.HotelDate(cancelledAt), arrival),
+		AmountPaid:        amountPaid,
+		EvaluatedAt:       primitive.NewDateTimeFromTime(cancelledAt),
+	}
+
+	var penalty float64
+	switch {
+	case policy == nil:
+		result.Rule = constants.RefundRuleNoPolicy
+	case policy.NonRefundable:
+		result.Rule = constants.RefundRuleNonRefundable
+		penalty = amountPaid
+	case result.DaysBeforeArrival >= policy.FreeUntilDays:
+		result.Rule = constants.RefundRuleFree
+	default:
+		result.Rule = constants.RefundRulePenalty
+		if policy.PenaltyType == constants.PenaltyTypeFirstNight {
+			penalty = firstNight
+		} else {
+			penalty = amountPaid * policy.PenaltyPercent / 100
+		}
+	}
+
+	result.Penalty = roundMoney(math.Min(math.Max(penalty, 0), amountPaid))
+	result.Refund = roundMoney(amountPaid - result.Penalty)
+	return result
+}
+
+func (s *cancellationService) EvaluateBooking(booking models.Booking, cancelledAt time.Time) (models.CancellationResult, error) {
+	var policy *models.CancellationPolicy
+
+	room, err := s.roomRepo.GetByID(booking.RoomID.Hex())
+	if err != nil {
+		return models.CancellationResult{}, err
+	}
+	if !room.RoomTypeID.IsZero() {
+		roomType, err := s.roomTypeRepo.GetByID(room.RoomTypeID.Hex())
+		if err != nil {
+			return models.CancellationResult{}, err
+		}
+		policy = roomType.CancellationPolicy
+	}
+
+	return s.Evaluate(policy, booking.CheckIn.Time().UTC(), cancelledAt, amountPaid(booking), firstNightCharge(booking)), nil
+}
+
+// amountPaid adalah jumlah yang sudah dibayar tamu lewat payment. Booking
+// CONFIRMED tanpa payment (dikonfirmasi staff) dianggap lunas.
+func amountPaid(booking models.Booking) float64 {
+	if booking.AmountPaid > 0 {
+		return booking.AmountPaid
+	}
+	if booking.Status == constants.BookingStatusConfirmed {
+		return booking.TotalPrice
+	}
+	return 0
+}
+
+// firstNightCharge adalah bagian total (termasuk diskon, service charge dan
+// pajak) untuk malam pertama.
+func firstNightCharge(booking models.Booking) float64 {
+	if len(booking.NightlyRates) > 0 && booking.Subtotal > 0 {
+		return roundMoney(booking.TotalPrice * booking.NightlyRates[0].Rate / booking.Subtotal)
+	}
+	if booking.Nights > 0 {
+		return roundMoney(booking.TotalPrice / float64(booking.Nights))
+	}
+	return booking.TotalPrice
+}
@@ -0,0 +1,240 @@
+package booking
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	"testing"
+	"time"
+)
+
+func TestEvaluateCancellation(t *testing.T) {
+	// check-in 10 Maret 2030; tanggal menginap disimpan sebagai tengah malam UTC
+	arrival := time.Date(2030, 3, 10, 0, 0, 0, 0, time.UTC)
+	// 3 Maret 12:00 WIB, 7 hari sebelum kedatangan
+	sevenDays := time.Date(2030, 3, 3, 5, 0, 0, 0, time.UTC)
+
+	free7 := &models.CancellationPolicy{FreeUntilDays: 7, PenaltyType: constants.PenaltyTypePercent, PenaltyPercent: 50}
+	firstNight := &models.CancellationPolicy{FreeUntilDays: 3, PenaltyType: constants.PenaltyTypeFirstNight}
+
+	tests := []struct {
+		name        string
+		timezone    string
+		policy      *models.CancellationPolicy
+		cancelledAt time.Time
+		paid        float64
+		firstNight  float64
+		wantRule    string
+		wantDays    int
+		wantPenalty float64
+		wantRefund  float64
+	}{
+		{
+			name:        "tanpa policy: refund penuh",
+			cancelledAt: arrival.Add(-2 * time.Hour),
+			paid:        1000000,
+			wantRule:    constants.RefundRuleNoPolicy,
+			wantDays:    0,
+			wantRefund:  1000000,
+		},
+		{
+			name:        "non-refundable: semua jadi denda walau jauh hari",
+			policy:      &models.CancellationPolicy{NonRefundable: true, FreeUntilDays: 30},
+			cancelledAt: sevenDays.AddDate(0, 0, -60),
+			paid:        1000000,
+			wantRule:    constants.RefundRuleNonRefundable,
+			wantDays:    67,
+			wantPenalty: 1000000,
+		},
+		{
+			name:        "gratis sebelum batas",
+			policy:      free7,
+			cancelledAt: sevenDays.AddDate(0, 0, -1),
+			paid:        1000000,
+			wantRule:    constants.RefundRuleFree,
+			wantDays:    8,
+			wantRefund:  1000000,
+		},
+		{
+			name:        "tepat FreeUntilDays masih gratis",
+			policy:      free7,
+			cancelledAt: sevenDays,
+			paid:        1000000,
+			wantRule:    constants.RefundRuleFree,
+			wantDays:    7,
+			wantRefund:  1000000,
+		},
+		{
+			name:        "sehari setelah batas kena denda persen",
+			policy:      free7,
+			cancelledAt: sevenDays.AddDate(0, 0, 1),
+			paid:        1000000,
+			wantRule:    constants.RefundRulePenalty,
+			wantDays:    6,
+			wantPenalty: 500000,
+			wantRefund:  500000,
+		},
+		{
+			name:        "penalty type kosong dianggap persen",
+			policy:      &models.CancellationPolicy{FreeUntilDays: 7, PenaltyPercent: 25},
+			cancelledAt: sevenDays.AddDate(0, 0, 2),
+			paid:        800000,
+			wantRule:    constants.RefundRulePenalty,
+			wantDays:    5,
+			wantPenalty: 200000,
+			wantRefund:  600000,
+		},
+		{
+			name:        "denda malam pertama",
+			policy:      firstNight,
+			cancelledAt: arrival.AddDate(0, 0, -1),
+			paid:        1200000,
+			firstNight:  400000,
+			wantRule:    constants.RefundRulePenalty,
+			wantDays:    1,
+			wantPenalty: 400000,
+			wantRefund:  800000,
+		},
+		{
+			name:        "denda malam pertama tidak melebihi yang dibayar",
+			policy:      firstNight,
+			cancelledAt: arrival.AddDate(0, 0, -1),
+			paid:        300000,
+			firstNight:  400000,
+			wantRule:    constants.RefundRulePenalty,
+			wantDays:    1,
+			wantPenalty: 300000,
+		},
+		{
+			name:        "persen di atas 100 dibatasi jumlah yang dibayar",
+			policy:      &models.CancellationPolicy{FreeUntilDays: 1, PenaltyPercent: 150},
+			cancelledAt: arrival.Add(-time.Hour),
+			paid:        500000,
+			wantRule:    constants.RefundRulePenalty,
+			wantDays:    0,
+			wantPenalty: 500000,
+		},
+		{
+			name:        "belum bayar: tidak ada denda maupun refund",
+			policy:      free7,
+			cancelledAt: arrival.AddDate(0, 0, -1),
+			wantRule:    constants.RefundRulePenalty,
+			wantDays:    1,
+		},
+		{
+			name:        "denda dibulatkan 2 desimal",
+			policy:      &models.CancellationPolicy{FreeUntilDays: 7, PenaltyPercent: 33},
+			cancelledAt: arrival.AddDate(0, 0, -1),
+			paid:        333.33,
+			wantRule:    constants.RefundRulePenalty,
+			wantDays:    1,
+			wantPenalty: 110,
+			wantRefund:  223.33,
+		},
+		{
+			name:        "batal setelah tanggal check-in",
+			policy:      free7,
+			cancelledAt: arrival.AddDate(0, 0, 2),
+			paid:        1000000,
+			wantRule:    constants.RefundRulePenalty,
+			wantDays:    -2,
+			wantPenalty: 500000,
+			wantRefund:  500000,
+		},
+		{
+			name:        "WIB: 23:59 masih tanggal 3, gratis",
+			timezone:    "Asia/Jakarta",
+			policy:      free7,
+			cancelledAt: time.Date(2030, 3, 3, 16, 59, 0, 0, time.UTC),
+			paid:        1000000,
+			wantRule:    constants.RefundRuleFree,
+			wantDays:    7,
+			wantRefund:  1000000,
+		},
+		{
+			name:        "WIB: 00:00 sudah tanggal 4, kena denda",
+			timezone:    "Asia/Jakarta",
+			policy:      free7,
+			cancelledAt: time.Date(2030, 3, 3, 17, 0, 0, 0, time.UTC),
+			paid:        1000000,
+			wantRule:    constants.RefundRulePenalty,
+			wantDays:    6,
+			wantPenalty: 500000,
+			wantRefund:  500000,
+		},
+		{
+			name:        "zona waktu hotel lain: waktu UTC yang sama masih tanggal 3",
+			timezone:    "America/New_York",
+			policy:      free7,
+			cancelledAt: time.Date(2030, 3, 4, 3, 0, 0, 0, time.UTC),
+			paid:        1000000,
+			wantRule:    constants.RefundRuleFree,
+			wantDays:    7,
+			wantRefund:  1000000,
+		},
+		{
+			name:        "HOTEL_TIMEZONE kosong memakai Asia/Jakarta",
+			policy:      free7,
+			cancelledAt: time.Date(2030, 3, 4, 3, 0, 0, 0, time.UTC),
+			paid:        1000000,
+			wantRule:    constants.RefundRulePenalty,
+			wantDays:    6,
+			wantPenalty: 500000,
+			wantRefund:  500000,
+		},
+	}
+
+	svc := NewCancellationService(nil, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOTEL_TIMEZONE", tt.timezone)
+
+			got := svc.Evaluate(tt.policy, arrival, tt.cancelledAt, tt.paid, tt.firstNight)
+			if got.Rule != tt.wantRule || got.DaysBeforeArrival != tt.wantDays {
+				t.Errorf("rule %s, %d hari; want %s, %d hari", got.Rule, got.DaysBeforeArrival, tt.wantRule, tt.wantDays)
+			}
+			if got.Penalty != tt.wantPenalty || got.Refund != tt.wantRefund {
+				t.Errorf("denda %v, refund %v; want %v, %v", got.Penalty, got.Refund, tt.wantPenalty, tt.wantRefund)
+			}
+			if got.AmountPaid != tt.paid || got.Policy != tt.policy {
+				t.Errorf("amount_paid %v / policy %v tidak ikut disimpan", got.AmountPaid, got.Policy)
+			}
+			if !got.EvaluatedAt.Time().Equal(tt.cancelledAt) {
+				t.Errorf("evaluated_at %v, want %v", got.EvaluatedAt.Time(), tt.cancelledAt)
+			}
+		})
+	}
+}
+
+func TestFirstNightCharge(t *testing.T) {
+	tests := []struct {
+		name    string
+		booking models.Booking
+		want    float64
+	}{
+		{
+			name: "proporsional dari harga malam pertama",
+			booking: models.Booking{
+				Subtotal:     1000000,
+				TotalPrice:   1210000,
+				NightlyRates: []models.NightlyRate{{Rate: 400000}, {Rate: 600000}},
+				Nights:       2,
+			},
+			want: 484000,
+		},
+		{
+			name:    "tanpa rincian per malam: rata-rata",
+			booking: models.Booking{TotalPrice: 900000, Nights: 3},
+			want:    300000,
+		},
+		{
+			name:    "tanpa jumlah malam: total",
+			booking: models.Booking{TotalPrice: 500000},
+			want:    500000,
+		},
+	}
+	for _, tt := range tests {
+		if got := firstNightCharge(tt.booking); got != tt.want {
+			t.Errorf("%s: %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
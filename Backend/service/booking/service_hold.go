@@ -0,0 +1,186 @@
+package booking
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	adminRepo "astro-backend/repository/admin"
+	bookingRepo "astro-backend/repository/booking"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrHoldNotFound = errors.New("Hold tidak ditemukan")
+
+type HoldService interface {
+	Create(actor Actor, input CreateBookingInput) (models.RoomHold, error)
+	Confirm(actor Actor, id string) (models.Booking, error)
+	Release(actor Actor, id string) error
+	ReleaseExpired(limit int64) ([]models.RoomHold, error)
+}
+
+type holdService struct {
+	repo        bookingRepo.RoomHoldRepository
+	bookingRepo bookingRepo.BookingRepository
+	nightRepo   bookingRepo.RoomNightRepository
+	roomRepo    adminRepo.RoomRepository
+	quotes      QuoteService
+}
+
+func NewHoldService(repo bookingRepo.RoomHoldRepository, bookingRepository bookingRepo.BookingRepository, nightRepo bookingRepo.RoomNightRepository, roomRepo adminRepo.RoomRepository, quotes QuoteService) HoldService {
+	return &holdService{repo, bookingRepository, nightRepo, roomRepo, quotes}
+}
+
+// holdTTL dibaca dari HOLD_TTL (default 10 menit).
+func holdTTL() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("HOLD_TTL")); err == nil && d > 0 {
+		return d
+	}
+	return 10 * time.Minute
+}
+
+// Create menahan kamar untuk actor selama HOLD_TTL. Harga dikunci saat
+// hold dibuat.
+func (s *holdService) Create(actor Actor, input CreateBookingInput) (models.RoomHold, error) {
+	booking, _, err := prepareBooking(s.bookingRepo, s.roomRepo, s.quotes, actor, input)
+	if err != nil {
+		return models.RoomHold{}, err
+	}
+
+	now := time.Now()
+	hold := models.RoomHold{
+		Id:            booking.Id,
+		UserID:        booking.UserID,
+		RoomID:        booking.RoomID,
+		CheckIn:       booking.CheckIn,
+		CheckOut:      booking.CheckOut,
+		Nights:        booking.Nights,
+		Guests:        booking.Guests,
+		PricePerNight: booking.PricePerNight,
+		Subtotal:      booking.Subtotal,
+		ServiceCharge: booking.ServiceCharge,
+		Tax:           booking.Tax,
+		TotalPrice:    booking.TotalPrice,
+		NightlyRates:  booking.NightlyRates,
+		QuoteID:       booking.QuoteID,
+		Notes:         booking.Notes,
+		Status:        constants.HoldStatusActive,
+		ExpiresAt:     primitive.NewDateTimeFromTime(now.Add(holdTTL())),
+		CreatedAt:     primitive.NewDateTimeFromTime(now),
+		UpdatedAt:     primitive.NewDateTimeFromTime(now),
+	}
+
+	if err := s.nightRepo.Reserve(hold.RoomID, hold.Id, hold.CheckIn.Time().UTC(), hold.CheckOut.Time().UTC()); err != nil {
+		if errors.Is(err, bookingRepo.ErrRoomNightTaken) {
+			return models.RoomHold{}, ErrRoomNotAvailable
+		}
+		return models.RoomHold{}, err
+	}
+
+	if err := s.repo.Create(hold); err != nil {
+		_ = s.nightRepo.Release(hold.Id)
+		return models.RoomHold{}, err
+	}
+
+	return hold, nil
+}
+
+func (s *holdService) getOwned(actor Actor, id string) (models.RoomHold, error) {
+	hold, err := s.repo.GetByID(id)
+	if err != nil {
+		return models.RoomHold{}, ErrHoldNotFound
+	}
+	if !actor.Staff && hold.UserID != actor.User.Id {
+		return models.RoomHold{}, ErrHoldNotFound
+	}
+	return hold, nil
+}
+
+// Confirm mengubah hold yang masih aktif menjadi booking dengan ID yang sama.
+// Malam di room_night sudah atas nama ID tersebut, jadi tidak dipesan ulang.
+func (s *holdService) Confirm(actor Actor, id string) (models.Booking, error) {
+	hold, err := s.getOwned(actor, id)
+	if err != nil {
+		return models.Booking{}, err
+	}
+
+	if err := s.repo.Confirm(hold.Id); err != nil {
+		return models.Booking{}, err
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	booking := models.Booking{
+		Id:            hold.Id,
+		UserID:        hold.UserID,
+		RoomID:        hold.RoomID,
+		CheckIn:       hold.CheckIn,
+		CheckOut:      hold.CheckOut,
+		Nights:        hold.Nights,
+		Guests:        hold.Guests,
+		Status:        constants.BookingStatusPending,
+		PricePerNight: hold.PricePerNight,
+		Subtotal:      hold.Subtotal,
+		ServiceCharge: hold.ServiceCharge,
+		Tax:           hold.Tax,
+		TotalPrice:    hold.TotalPrice,
+		NightlyRates:  hold.NightlyRates,
+		QuoteID:       hold.QuoteID,
+		Notes:         hold.Notes,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if err := s.bookingRepo.Create(booking); err != nil {
+		// hold sudah CONFIRMED tapi booking gagal: lepas inventori supaya kamar tidak terkunci
+		if releaseErr := s.nightRepo.Release(hold.Id); releaseErr != nil {
+			log.Error().Err(releaseErr).Str("hold_id", hold.Id.Hex()).Msg("gagal melepas room_night")
+		}
+		return models.Booking{}, err
+	}
+
+	return booking, nil
+}
+
+func (s *holdService) Release(actor Actor, id string) error {
+	hold, err := s.getOwned(actor, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Release(hold.Id, constants.HoldStatusReleased); err != nil {
+		return err
+	}
+
+	return s.nightRepo.Release(hold.Id)
+}
+
+// ReleaseExpired melepas hold aktif yang sudah lewat expires_at dan
+// mengembalikan hold yang berhasil dilepas. Hold yang keburu dikonfirmasi
+// di antara pencarian dan update dilewati.
+func (s *holdService) ReleaseExpired(limit int64) ([]models.RoomHold, error) {
+	holds, err := s.repo.FindExpired(time.Now(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	released := []models.RoomHold{}
+	for _, hold := range holds {
+		if err := s.repo.Release(hold.Id, constants.HoldStatusExpired); err != nil {
+			if !errors.Is(err, bookingRepo.ErrHoldNotActive) {
+				log.Error().Err(err).Str("hold_id", hold.Id.Hex()).Msg("gagal melepas hold")
+			}
+			continue
+		}
+		if err := s.nightRepo.Release(hold.Id); err != nil {
+			log.Error().Err(err).Str("hold_id", hold.Id.Hex()).Msg("gagal melepas room_night")
+		}
+
+		hold.Status = constants.HoldStatusExpired
+		released = append(released, hold)
+	}
+
+	return released, nil
+}
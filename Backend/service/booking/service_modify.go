@@ -0,0 +1,208 @@
+package booking
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	bookingRepo "astro-backend/repository/booking"
+	"astro-backend/utils"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ModifyBookingInput adalah perubahan booking. Field yang tidak dikirim
+// (nil) tidak diubah.
+type ModifyBookingInput struct {
+	RoomID   *string `json:"room_id"`
+	CheckIn  *string `json:"check_in"`
+	CheckOut *string `json:"check_out"`
+	Guests   *int    `json:"guests"`
+}
+
+// Modify mengubah tanggal, kamar atau jumlah tamu booking aktif lalu
+// menghitung ulang harganya. Tamu hanya bisa mengubah sebelum tanggal
+// check-in; staff bisa kapan saja, termasuk memperpanjang tamu yang sedang
+// menginap. Selisih total disimpan di PriceDifference.
+func (s *bookingService) Modify(actor Actor, id string, input ModifyBookingInput) (models.Booking, models.Booking, error) {
+	before, err := s.GetByID(actor, id)
+	if err != nil {
+		return models.Booking{}, models.Booking{}, err
+	}
+	before.Room = nil
+
+	active := false
+	for _, status := range constants.BookingActiveStatuses {
+		if before.Status == status {
+			active = true
+		}
+	}
+	if !active {
+		return models.Booking{}, models.Booking{}, errors.New("Hanya booking aktif yang bisa diubah")
+	}
+	if !actor.Staff && !before.CheckIn.Time().After(utils.Today()) {
+		return models.Booking{}, models.Booking{}, errors.New("Booking tidak bisa diubah pada atau setelah tanggal check-in")
+	}
+
+	oldIn, oldOut := before.CheckIn.Time().UTC(), before.CheckOut.Time().UTC()
+	checkIn, checkOut, err := modifiedStay(input, oldIn, oldOut)
+	if err != nil {
+		return models.Booking{}, models.Booking{}, err
+	}
+
+	guests := before.Guests
+	if input.Guests != nil {
+		guests = *input.Guests
+	}
+	if guests <= 0 {
+		return models.Booking{}, models.Booking{}, errors.New("Jumlah tamu minimal 1")
+	}
+
+	roomID := before.RoomID.Hex()
+	if input.RoomID != nil && *input.RoomID != "" {
+		roomID = *input.RoomID
+	}
+	room, err := s.roomRepo.GetByID(roomID)
+	if err != nil {
+		return models.Booking{}, models.Booking{}, errors.New("Kamar tidak ditemukan")
+	}
+	if room.Id != before.RoomID && !room.Availability {
+		return models.Booking{}, models.Booking{}, ErrRoomNotAvailable
+	}
+	if room.Capacity > 0 && guests > room.Capacity {
+		return models.Booking{}, models.Booking{}, errors.New("Jumlah tamu melebihi kapasitas kamar")
+	}
+
+	overlap, err := s.repo.HasOverlapExcept(room.Id, checkIn, checkOut, before.Id)
+	if err != nil {
+		return models.Booking{}, models.Booking{}, err
+	}
+	if overlap {
+		return models.Booking{}, models.Booking{}, ErrRoomNotAvailable
+	}
+
+	quote, err := s.quotes.Calculate(room, checkIn, checkOut, guests)
+	if err != nil {
+		return models.Booking{}, models.Booking{}, err
+	}
+
+	nights := utils.Nights(checkIn, checkOut)
+
+	// promo dinilai ulang untuk kamar dan malam yang baru; kalau tidak lagi
+	// memenuhi syarat, promo dilepas dan kuotanya dikembalikan setelah
+	// booking tersimpan
+	promoCode, discount := before.PromoCode, 0.0
+	var droppedPromo *models.PromoCode
+	if promoCode != "" {
+		_, result, err := evaluatePromo(s.promoRepo, s.roomRepo, promoCode, promoTarget{roomID: room.Id, nights: nights, subtotal: quote.Subtotal, redeemed: true})
+		if err == nil {
+			discount = result.Discount
+		} else {
+			promoCode = ""
+			if promo, findErr := s.promoRepo.FindByCode(before.PromoCode); findErr == nil {
+				droppedPromo = &promo
+			}
+		}
+	}
+	_, serviceCharge, tax, total := applyCharges(quote.Subtotal, discount)
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	after := before
+	after.RoomID = room.Id
+	after.CheckIn = primitive.NewDateTimeFromTime(checkIn)
+	after.CheckOut = primitive.NewDateTimeFromTime(checkOut)
+	after.Nights = nights
+	after.Guests = guests
+	after.PricePerNight = roundMoney(quote.Subtotal / float64(nights))
+	after.Subtotal = quote.Subtotal
+	after.PromoCode = promoCode
+	after.Discount = discount
+	after.ServiceCharge = serviceCharge
+	after.Tax = tax
+	after.TotalPrice = total
+	after.NightlyRates = quote.NightlyRates
+	after.QuoteID = nil
+	after.PriceDifference = roundMoney(total - before.TotalPrice)
+	after.ModifiedAt = &now
+	after.UpdatedAt = now
+
+	// inventori: pesan malam yang bertambah dulu, baru simpan booking, lalu
+	// lepas malam yang tidak dipakai lagi
+	added := nightsDiff(room.Id, checkIn, checkOut, before.RoomID, oldIn, oldOut)
+	removed := nightsDiff(before.RoomID, oldIn, oldOut, room.Id, checkIn, checkOut)
+
+	if err := s.nightRepo.ReserveDates(room.Id, before.Id, added); err != nil {
+		if errors.Is(err, bookingRepo.ErrRoomNightTaken) {
+			return models.Booking{}, models.Booking{}, ErrRoomNotAvailable
+		}
+		return models.Booking{}, models.Booking{}, err
+	}
+
+	if err := s.repo.Modify(before, after); err != nil {
+		if releaseErr := s.nightRepo.ReleaseDates(room.Id, before.Id, added); releaseErr != nil {
+			log.Error().Err(releaseErr).Str("booking_id", before.Id.Hex()).Msg("gagal melepas room_night")
+		}
+		return models.Booking{}, models.Booking{}, err
+	}
+
+	if err := s.nightRepo.ReleaseDates(before.RoomID, before.Id, removed); err != nil {
+		log.Error().Err(err).Str("booking_id", before.Id.Hex()).Msg("gagal melepas room_night")
+	}
+
+	if droppedPromo != nil {
+		if err := s.promoRepo.ReleaseUsage(droppedPromo.ID, before.UserID); err != nil {
+			log.Error().Err(err).Str("promo_id", droppedPromo.ID.Hex()).Msg("gagal mengembalikan kuota promo")
+		}
+	}
+
+	return before, after, nil
+}
+
+// modifiedStay menggabungkan tanggal baru dengan tanggal lama. Check-in yang
+// diubah tidak boleh di masa lalu; check-in lama boleh sudah lewat supaya
+// staff bisa memperpanjang tamu yang sedang menginap.
+func modifiedStay(input ModifyBookingInput, oldIn, oldOut time.Time) (time.Time, time.Time, error) {
+	checkIn, checkOut := oldIn, oldOut
+
+	if input.CheckIn != nil {
+		t, err := utils.ParseDate(*input.CheckIn)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("check_in: " + err.Error())
+		}
+		if !t.Equal(oldIn) && t.Before(utils.Today()) {
+			return time.Time{}, time.Time{}, errors.New("Tanggal check-in sudah lewat")
+		}
+		checkIn = t
+	}
+	if input.CheckOut != nil {
+		t, err := utils.ParseDate(*input.CheckOut)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("check_out: " + err.Error())
+		}
+		checkOut = t
+	}
+
+	if !checkOut.After(checkIn) {
+		return time.Time{}, time.Time{}, errors.New("Tanggal check-out harus setelah check-in")
+	}
+	if maxNights := maxStayNights(); utils.Nights(checkIn, checkOut) > maxNights {
+		return time.Time{}, time.Time{}, errors.New("Lama menginap maksimal " + strconv.Itoa(maxNights) + " malam")
+	}
+
+	return checkIn, checkOut, nil
+}
+
+// nightsDiff mengembalikan malam di rentang (roomA, inA..outA) yang tidak
+// ada di rentang (roomB, inB..outB).
+func nightsDiff(roomA primitive.ObjectID, inA, outA time.Time, roomB primitive.ObjectID, inB, outB time.Time) []time.Time {
+	dates := []time.Time{}
+	for d := inA; d.Before(outA); d = d.AddDate(0, 0, 1) {
+		if roomA == roomB && !d.Before(inB) && d.Before(outB) {
+			continue
+		}
+		dates = append(dates, d)
+	}
+	return dates
+}
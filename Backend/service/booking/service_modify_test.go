@@ -0,0 +1,140 @@
+package booking
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	adminRepo "astro-backend/repository/admin"
+	bookingRepo "astro-backend/repository/booking"
+	"astro-backend/utils"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type modifyBookingRepo struct {
+	bookingRepo.BookingRepository
+	booking models.Booking
+	saved   *models.Booking
+}
+
+func (r *modifyBookingRepo) GetByID(string) (models.Booking, error) {
+	return r.booking, nil
+}
+
+func (r *modifyBookingRepo) HasOverlapExcept(primitive.ObjectID, time.Time, time.Time, primitive.ObjectID) (bool, error) {
+	return false, nil
+}
+
+func (r *modifyBookingRepo) Modify(_, after models.Booking) error {
+	r.saved = &after
+	return nil
+}
+
+type stubPromoRepo struct {
+	adminRepo.PromoCodeRepository
+	promo    *models.PromoCode
+	released []primitive.ObjectID
+}
+
+func (r *stubPromoRepo) FindByCode(code string) (models.PromoCode, error) {
+	if r.promo == nil || r.promo.Code != code {
+		return models.PromoCode{}, errors.New("promo not found")
+	}
+	return *r.promo, nil
+}
+
+func (r *stubPromoRepo) ReleaseUsage(promoID, _ primitive.ObjectID) error {
+	r.released = append(r.released, promoID)
+	return nil
+}
+
+// TestModifyReevaluatesPromo memastikan diskon dihitung ulang dari promo
+// untuk kamar dan malam yang baru, dan promo yang tidak lagi memenuhi syarat
+// dilepas beserta kuotanya.
+func TestModifyReevaluatesPromo(t *testing.T) {
+	t.Setenv("SERVICE_CHARGE_PERCENT", "0")
+	t.Setenv("TAX_PERCENT", "0")
+
+	roomTypeID := primitive.NewObjectID()
+	room := models.Room{Id: primitive.NewObjectID(), RoomTypeID: roomTypeID, PricePerNight: 500000, Capacity: 2, Availability: true}
+	checkIn := utils.Today().AddDate(0, 0, 30)
+	past := primitive.NewDateTimeFromTime(time.Now().Add(-time.Hour))
+
+	basePromo := func() models.PromoCode {
+		return models.PromoCode{ID: primitive.NewObjectID(), Code: "HEMAT10", DiscountType: constants.DiscountTypePercent, Value: 10, Active: true}
+	}
+
+	tests := []struct {
+		name         string
+		nights       int // lama menginap setelah diubah, sebelumnya 3 malam
+		promo        func(p *models.PromoCode)
+		deleted      bool
+		wantCode     string
+		wantDiscount float64
+		wantReleased bool
+	}{
+		{name: "tetap berlaku, diskon ikut subtotal baru", nights: 4, wantCode: "HEMAT10", wantDiscount: 200000},
+		{name: "kuota habis oleh booking ini sendiri", nights: 2, promo: func(p *models.PromoCode) { p.UsageLimit, p.UsedCount = 1, 1 }, wantCode: "HEMAT10", wantDiscount: 100000},
+		{name: "minimal malam tidak terpenuhi", nights: 2, promo: func(p *models.PromoCode) { p.MinNights = 3 }, wantReleased: true},
+		{name: "tipe kamar tidak berlaku", nights: 3, promo: func(p *models.PromoCode) { p.RoomTypeIDs = []primitive.ObjectID{primitive.NewObjectID()} }, wantReleased: true},
+		{name: "promo sudah berakhir", nights: 3, promo: func(p *models.PromoCode) { p.ValidUntil = &past }, wantReleased: true},
+		{name: "promo dinonaktifkan", nights: 3, promo: func(p *models.PromoCode) { p.Active = false }, wantReleased: true},
+		{name: "promo dihapus", nights: 3, deleted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			promo := basePromo()
+			if tt.promo != nil {
+				tt.promo(&promo)
+			}
+			promos := &stubPromoRepo{promo: &promo}
+			if tt.deleted {
+				promos.promo = nil
+			}
+
+			before := models.Booking{
+				Id:         primitive.NewObjectID(),
+				UserID:     primitive.NewObjectID(),
+				RoomID:     room.Id,
+				CheckIn:    primitive.NewDateTimeFromTime(checkIn),
+				CheckOut:   primitive.NewDateTimeFromTime(checkIn.AddDate(0, 0, 3)),
+				Nights:     3,
+				Guests:     1,
+				Status:     constants.BookingStatusConfirmed,
+				Subtotal:   1500000,
+				PromoCode:  "HEMAT10",
+				Discount:   150000,
+				TotalPrice: 1350000,
+			}
+			bookings := &modifyBookingRepo{booking: before}
+			svc := NewBookingService(bookings, newMemRoomNights(), stubRoomRepo{room: room}, promos, stubQuotes{}, nil, nil)
+
+			checkOut := checkIn.AddDate(0, 0, tt.nights).Format(utils.DateLayout)
+			_, after, err := svc.Modify(Actor{Staff: true}, before.Id.Hex(), ModifyBookingInput{CheckOut: &checkOut})
+			if err != nil {
+				t.Fatalf("Modify: %v", err)
+			}
+			if bookings.saved == nil {
+				t.Fatal("booking tidak disimpan")
+			}
+
+			if after.PromoCode != tt.wantCode || bookings.saved.PromoCode != tt.wantCode {
+				t.Errorf("promo_code %q, want %q", after.PromoCode, tt.wantCode)
+			}
+			if after.Discount != tt.wantDiscount {
+				t.Errorf("discount %v, want %v", after.Discount, tt.wantDiscount)
+			}
+			if want := after.Subtotal - tt.wantDiscount; after.TotalPrice != want {
+				t.Errorf("total %v, want %v", after.TotalPrice, want)
+			}
+
+			released := len(promos.released) == 1 && promos.released[0] == promo.ID
+			if released != tt.wantReleased || len(promos.released) > 1 {
+				t.Errorf("kuota dikembalikan %v (%d kali), want %v", released, len(promos.released), tt.wantReleased)
+			}
+		})
+	}
+}
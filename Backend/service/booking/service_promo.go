@@ -0,0 +1,219 @@
+package booking
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	adminRepo "astro-backend/repository/admin"
+	bookingRepo "astro-backend/repository/booking"
+	adminService "astro-backend/service/admin"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrPromoInvalid = errors.New("Promo code tidak valid")
+
+// PromoInput adalah promo code yang ingin dipakai. Validate menerima
+// booking_id atau quote_id; Redeem hanya booking_id.
+type PromoInput struct {
+	Code      string `json:"code"`
+	BookingID string `json:"booking_id"`
+	QuoteID   string `json:"quote_id"`
+}
+
+// PromoResult adalah harga setelah promo diterapkan.
+type PromoResult struct {
+	Code          string               `json:"code"`
+	DiscountType  string               `json:"discount_type"`
+	Value         float64              `json:"value"`
+	Subtotal      float64              `json:"subtotal"`
+	Discount      float64              `json:"discount"`
+	Charges       []models.QuoteCharge `json:"charges"`
+	ServiceCharge float64              `json:"service_charge"`
+	Tax           float64              `json:"tax"`
+	GrandTotal    float64              `json:"grand_total"`
+}
+
+type PromoService interface {
+	Validate(actor Actor, input PromoInput) (PromoResult, error)
+	Redeem(actor Actor, input PromoInput) (models.Booking, error)
+}
+
+type promoService struct {
+	repo        adminRepo.PromoCodeRepository
+	bookingRepo bookingRepo.BookingRepository
+	roomRepo    adminRepo.RoomRepository
+	bookings    BookingService
+	quotes      QuoteService
+}
+
+func NewPromoService(repo adminRepo.PromoCodeRepository, bookingRepository bookingRepo.BookingRepository, roomRepo adminRepo.RoomRepository, bookings BookingService, quotes QuoteService) PromoService {
+	return &promoService{repo, bookingRepository, roomRepo, bookings, quotes}
+}
+
+// promoTarget adalah data booking / quote yang dibutuhkan untuk menilai promo.
+// redeemed diisi kalau booking sudah memakai kuota promo ini, supaya kuota
+// total yang habis karena booking itu sendiri tidak membuatnya gagal.
+type promoTarget struct {
+	roomID   primitive.ObjectID
+	nights   int
+	subtotal float64
+	redeemed bool
+}
+
+// Validate mengecek promo tanpa memakai kuota.
+func (s *promoService) Validate(actor Actor, input PromoInput) (PromoResult, error) {
+	var target promoTarget
+	switch {
+	case input.BookingID != "":
+		booking, err := s.bookings.GetByID(actor, input.BookingID)
+		if err != nil {
+			return PromoResult{}, err
+		}
+		target = promoTarget{roomID: booking.RoomID, nights: booking.Nights, subtotal: booking.Subtotal}
+	case input.QuoteID != "":
+		quote, err := s.quotes.GetByID(actor, input.QuoteID)
+		if err != nil {
+			return PromoResult{}, err
+		}
+		target = promoTarget{roomID: quote.RoomID, nights: quote.Nights, subtotal: quote.Subtotal}
+	default:
+		return PromoResult{}, errors.New("booking_id atau quote_id wajib diisi")
+	}
+
+	promo, result, err := evaluatePromo(s.repo, s.roomRepo, input.Code, target)
+	if err != nil {
+		return PromoResult{}, err
+	}
+
+	if promo.PerUserLimit > 0 {
+		used, err := s.repo.UserUsage(promo.ID, actor.User.Id)
+		if err != nil {
+			return PromoResult{}, err
+		}
+		if used >= promo.PerUserLimit {
+			return PromoResult{}, adminRepo.ErrPromoLimitReached
+		}
+	}
+
+	return result, nil
+}
+
+// Redeem memakai promo untuk booking PENDING milik actor. Kuota dipakai
+// lebih dulu secara atomik dan dikembalikan kalau booking gagal diubah.
+func (s *promoService) Redeem(actor Actor, input PromoInput) (models.Booking, error) {
+	if input.BookingID == "" {
+		return models.Booking{}, errors.New("booking_id wajib diisi")
+	}
+
+	booking, err := s.bookings.GetByID(actor, input.BookingID)
+	if err != nil {
+		return models.Booking{}, err
+	}
+	if booking.Status != constants.BookingStatusPending || booking.PromoCode != "" {
+		return models.Booking{}, errors.New("Promo hanya bisa dipakai pada booking PENDING yang belum memakai promo")
+	}
+
+	promo, result, err := evaluatePromo(s.repo, s.roomRepo, input.Code, promoTarget{roomID: booking.RoomID, nights: booking.Nights, subtotal: booking.Subtotal})
+	if err != nil {
+		return models.Booking{}, err
+	}
+
+	// kuota dihitung untuk pemilik booking, bukan staff yang memasukkan kode
+	if err := s.repo.ReserveUsage(promo, booking.UserID); err != nil {
+		return models.Booking{}, err
+	}
+
+	booking.PromoCode = promo.Code
+	booking.Discount = result.Discount
+	booking.ServiceCharge = result.ServiceCharge
+	booking.Tax = result.Tax
+	booking.TotalPrice = result.GrandTotal
+	booking.UpdatedAt = primitive.NewDateTimeFromTime(time.Now())
+
+	if err := s.bookingRepo.ApplyPromo(booking); err != nil {
+		if releaseErr := s.repo.ReleaseUsage(promo.ID, booking.UserID); releaseErr != nil {
+			log.Error().Err(releaseErr).Str("promo_id", promo.ID.Hex()).Msg("gagal mengembalikan kuota promo")
+		}
+		return models.Booking{}, err
+	}
+
+	// kuota dan booking sudah tersimpan; riwayat yang gagal cukup dicatat
+	if err := s.repo.CreateRedemption(models.PromoRedemption{
+		ID:        primitive.NewObjectID(),
+		PromoID:   promo.ID,
+		Code:      promo.Code,
+		UserID:    booking.UserID,
+		BookingID: booking.Id,
+		Discount:  result.Discount,
+		CreatedAt: booking.UpdatedAt,
+	}); err != nil {
+		log.Error().Err(err).Str("booking_id", booking.Id.Hex()).Msg("gagal menyimpan promo_redemption")
+	}
+
+	return booking, nil
+}
+
+// evaluatePromo mengecek aturan promo (aktif, masa berlaku, minimal malam,
+// tipe kamar, kuota total) dan menghitung harga setelah diskon. Dipakai juga
+// oleh Modify untuk menilai ulang promo booking yang diubah.
+func evaluatePromo(repo adminRepo.PromoCodeRepository, roomRepo adminRepo.RoomRepository, code string, target promoTarget) (models.PromoCode, PromoResult, error) {
+	promo, err := repo.FindByCode(adminService.NormalizePromoCode(code))
+	if err != nil || !promo.Active {
+		return models.PromoCode{}, PromoResult{}, ErrPromoInvalid
+	}
+
+	now := time.Now()
+	if promo.ValidFrom != nil && now.Before(promo.ValidFrom.Time()) {
+		return models.PromoCode{}, PromoResult{}, errors.New("Promo code belum berlaku")
+	}
+	if promo.ValidUntil != nil && now.After(promo.ValidUntil.Time()) {
+		return models.PromoCode{}, PromoResult{}, errors.New("Promo code sudah berakhir")
+	}
+	if promo.MinNights > 0 && target.nights < promo.MinNights {
+		return models.PromoCode{}, PromoResult{}, errors.New("Promo code memerlukan minimal menginap lebih lama")
+	}
+	if !target.redeemed && promo.UsageLimit > 0 && promo.UsedCount >= promo.UsageLimit {
+		return models.PromoCode{}, PromoResult{}, adminRepo.ErrPromoLimitReached
+	}
+
+	if len(promo.RoomTypeIDs) > 0 {
+		room, err := roomRepo.GetByID(target.roomID.Hex())
+		if err != nil {
+			return models.PromoCode{}, PromoResult{}, errors.New("Kamar tidak ditemukan")
+		}
+		eligible := false
+		for _, id := range promo.RoomTypeIDs {
+			if id == room.RoomTypeID {
+				eligible = true
+				break
+			}
+		}
+		if !eligible {
+			return models.PromoCode{}, PromoResult{}, errors.New("Promo code tidak berlaku untuk tipe kamar ini")
+		}
+	}
+
+	discount := promo.Value
+	if promo.DiscountType == constants.DiscountTypePercent {
+		discount = target.subtotal * promo.Value / 100
+		if promo.MaxDiscount > 0 {
+			discount = math.Min(discount, promo.MaxDiscount)
+		}
+	}
+	discount = roundMoney(math.Min(discount, target.subtotal))
+
+	result := PromoResult{
+		Code:         promo.Code,
+		DiscountType: promo.DiscountType,
+		Value:        promo.Value,
+		Subtotal:     target.subtotal,
+		Discount:     discount,
+	}
+	result.Charges, result.ServiceCharge, result.Tax, result.GrandTotal = applyCharges(target.subtotal, discount)
+
+	return promo, result, nil
+}
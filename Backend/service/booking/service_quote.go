@@ -0,0 +1,204 @@
+package booking
+
+import (
+	"astro-backend/models"
+	adminRepo "astro-backend/repository/admin"
+	bookingRepo "astro-backend/repository/booking"
+	"astro-backend/service/pricing"
+	"astro-backend/utils"
+	"errors"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrQuoteNotFound = errors.New("Quote tidak ditemukan")
+	ErrQuoteExpired  = errors.New("Quote sudah kedaluwarsa, silakan minta quote baru")
+	ErrQuoteMismatch = errors.New("Quote tidak sesuai dengan kamar, tanggal atau jumlah tamu")
+)
+
+// QuoteInput adalah permintaan quote dari client. Tanggal memakai format YYYY-MM-DD.
+type QuoteInput struct {
+	RoomID   string `json:"room_id"`
+	CheckIn  string `json:"check_in"`
+	CheckOut string `json:"check_out"`
+	Guests   int    `json:"guests"`
+}
+
+type QuoteService interface {
+	Create(actor Actor, input QuoteInput) (models.Quote, error)
+	GetByID(actor Actor, id string) (models.Quote, error)
+	// Calculate menghitung quote tanpa menyimpannya.
+	Calculate(room models.Room, checkIn, checkOut time.Time, guests int) (models.Quote, error)
+	// ForBooking mengambil quote yang masih berlaku dan cocok dengan booking.
+	ForBooking(actor Actor, id string, roomID primitive.ObjectID, checkIn, checkOut time.Time, guests int) (models.Quote, error)
+}
+
+type quoteService struct {
+	repo     bookingRepo.QuoteRepository
+	roomRepo adminRepo.RoomRepository
+	pricing  pricing.PricingService
+}
+
+func NewQuoteService(repo bookingRepo.QuoteRepository, roomRepo adminRepo.RoomRepository, pricingService pricing.PricingService) QuoteService {
+	return &quoteService{repo, roomRepo, pricingService}
+}
+
+// Create menghitung dan menyimpan quote milik actor. Berlaku selama
+// QUOTE_TTL (default 30 menit).
+func (s *quoteService) Create(actor Actor, input QuoteInput) (models.Quote, error) {
+	checkIn, checkOut, err := parseStay(input.CheckIn, input.CheckOut)
+	if err != nil {
+		return models.Quote{}, err
+	}
+	if input.Guests <= 0 {
+		return models.Quote{}, errors.New("Jumlah tamu minimal 1")
+	}
+
+	room, err := s.roomRepo.GetByID(input.RoomID)
+	if err != nil {
+		return models.Quote{}, errors.New("Kamar tidak ditemukan")
+	}
+	if room.Capacity > 0 && input.Guests > room.Capacity {
+		return models.Quote{}, errors.New("Jumlah tamu melebihi kapasitas kamar")
+	}
+
+	quote, err := s.Calculate(room, checkIn, checkOut, input.Guests)
+	if err != nil {
+		return models.Quote{}, err
+	}
+
+	now := time.Now()
+	quote.Id = primitive.NewObjectID()
+	quote.UserID = actor.User.Id
+	quote.ExpiresAt = primitive.NewDateTimeFromTime(now.Add(quoteTTL()))
+	quote.CreatedAt = primitive.NewDateTimeFromTime(now)
+
+	if err := s.repo.Create(quote); err != nil {
+		return models.Quote{}, err
+	}
+	return quote, nil
+}
+
+func (s *quoteService) GetByID(actor Actor, id string) (models.Quote, error) {
+	quote, err := s.repo.GetByID(id)
+	if err != nil {
+		return models.Quote{}, ErrQuoteNotFound
+	}
+	if !actor.Staff && quote.UserID != actor.User.Id {
+		return models.Quote{}, ErrQuoteNotFound
+	}
+	return quote, nil
+}
+
+func (s *quoteService) ForBooking(actor Actor, id string, roomID primitive.ObjectID, checkIn, checkOut time.Time, guests int) (models.Quote, error) {
+	quote, err := s.GetByID(actor, id)
+	if err != nil {
+		return models.Quote{}, err
+	}
+	if !quote.ExpiresAt.Time().After(time.Now()) {
+		return models.Quote{}, ErrQuoteExpired
+	}
+	if quote.RoomID != roomID ||
+		!quote.CheckIn.Time().Equal(checkIn) ||
+		!quote.CheckOut.Time().Equal(checkOut) ||
+		quote.Guests != guests {
+		return models.Quote{}, ErrQuoteMismatch
+	}
+	return quote, nil
+}
+
+// Calculate menyusun rincian harga: harga per malam dari pricing service
+// lalu service charge dan pajak (lihat applyCharges).
+func (s *quoteService) Calculate(room models.Room, checkIn, checkOut time.Time, guests int) (models.Quote, error) {
+	price, err := s.pricing.PriceStay(room, checkIn, checkOut)
+	if err != nil {
+		return models.Quote{}, err
+	}
+
+	quote := models.Quote{
+		RoomID:       room.Id,
+		CheckIn:      primitive.NewDateTimeFromTime(checkIn),
+		CheckOut:     primitive.NewDateTimeFromTime(checkOut),
+		Nights:       utils.Nights(checkIn, checkOut),
+		Guests:       guests,
+		Currency:     envString("CURRENCY", "IDR"),
+		NightlyRates: price.Nights,
+		Subtotal:     roundMoney(price.Total),
+	}
+
+	quote.Charges, quote.ServiceCharge, quote.Tax, quote.GrandTotal = applyCharges(quote.Subtotal, 0)
+	return quote, nil
+}
+
+// applyCharges menghitung service charge (SERVICE_CHARGE_PERCENT) dari
+// subtotal setelah diskon, lalu pajak (TAX_PERCENT) dari jumlah yang sama
+// ditambah service charge. Pajak hanya dihitung dari subtotal kalau
+// TAX_ON_SERVICE_CHARGE=false; kosong atau nilai tidak valid dianggap true,
+// sesuai praktik hotel di Indonesia.
+func applyCharges(subtotal, discount float64) (charges []models.QuoteCharge, serviceCharge, tax, grandTotal float64) {
+	charges = []models.QuoteCharge{}
+	net := roundMoney(subtotal - discount)
+
+	servicePercent := envPercent("SERVICE_CHARGE_PERCENT", 10)
+	if servicePercent > 0 {
+		serviceCharge = roundMoney(net * servicePercent / 100)
+		charges = append(charges, models.QuoteCharge{
+			Code:        "SERVICE_CHARGE",
+			Description: "Service charge " + strconv.FormatFloat(servicePercent, 'f', -1, 64) + "%",
+			Percent:     servicePercent,
+			Base:        net,
+			Amount:      serviceCharge,
+		})
+	}
+
+	taxPercent := envPercent("TAX_PERCENT", 10)
+	if taxPercent > 0 {
+		base := net
+		if taxOnService, err := strconv.ParseBool(os.Getenv("TAX_ON_SERVICE_CHARGE")); err != nil || taxOnService {
+			base = roundMoney(base + serviceCharge)
+		}
+		tax = roundMoney(base * taxPercent / 100)
+		charges = append(charges, models.QuoteCharge{
+			Code:        "TAX",
+			Description: "Pajak " + strconv.FormatFloat(taxPercent, 'f', -1, 64) + "%",
+			Percent:     taxPercent,
+			Base:        base,
+			Amount:      tax,
+		})
+	}
+
+	grandTotal = roundMoney(net + serviceCharge + tax)
+	return charges, serviceCharge, tax, grandTotal
+}
+
+// quoteTTL dibaca dari QUOTE_TTL (default 30 menit).
+func quoteTTL() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("QUOTE_TTL")); err == nil && d > 0 {
+		return d
+	}
+	return 30 * time.Minute
+}
+
+func envPercent(key string, fallback float64) float64 {
+	if v, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil && v >= 0 {
+		return v
+	}
+	return fallback
+}
+
+func envString(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// roundMoney membulatkan ke 2 desimal.
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,154 @@
+package booking
+
+import (
+	"testing"
+)
+
+func TestApplyCharges(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		subtotal    float64
+		discount    float64
+		wantService float64
+		wantTax     float64
+		wantTaxBase float64
+		wantTotal   float64
+		wantCharges []string
+	}{
+		{
+			name:        "default: 10% service, 10% pajak atas subtotal + service",
+			subtotal:    1000000,
+			wantService: 100000,
+			wantTaxBase: 1100000,
+			wantTax:     110000,
+			wantTotal:   1210000,
+			wantCharges: []string{"SERVICE_CHARGE", "TAX"},
+		},
+		{
+			name:        "TAX_ON_SERVICE_CHARGE=false: pajak hanya dari subtotal",
+			env:         map[string]string{"TAX_ON_SERVICE_CHARGE": "false"},
+			subtotal:    1000000,
+			wantService: 100000,
+			wantTaxBase: 1000000,
+			wantTax:     100000,
+			wantTotal:   1200000,
+			wantCharges: []string{"SERVICE_CHARGE", "TAX"},
+		},
+		{
+			name:        "TAX_ON_SERVICE_CHARGE tidak valid dianggap true",
+			env:         map[string]string{"TAX_ON_SERVICE_CHARGE": "ya"},
+			subtotal:    1000000,
+			wantService: 100000,
+			wantTaxBase: 1100000,
+			wantTax:     110000,
+			wantTotal:   1210000,
+			wantCharges: []string{"SERVICE_CHARGE", "TAX"},
+		},
+		{
+			name:        "diskon dipotong sebelum service charge dan pajak",
+			subtotal:    1000000,
+			discount:    200000,
+			wantService: 80000,
+			wantTaxBase: 880000,
+			wantTax:     88000,
+			wantTotal:   968000,
+			wantCharges: []string{"SERVICE_CHARGE", "TAX"},
+		},
+		{
+			name:        "diskon penuh: tidak ada service charge maupun pajak",
+			subtotal:    750000,
+			discount:    750000,
+			wantTotal:   0,
+			wantCharges: []string{"SERVICE_CHARGE", "TAX"},
+		},
+		{
+			name:        "pembulatan ke 2 desimal di setiap langkah",
+			subtotal:    333.33,
+			wantService: 33.33,  // 33.333
+			wantTaxBase: 366.66, // 333.33 + 33.33
+			wantTax:     36.67,  // 36.666
+			wantTotal:   403.33,
+			wantCharges: []string{"SERVICE_CHARGE", "TAX"},
+		},
+		{
+			name:        "diskon berdesimal dibulatkan sebelum dihitung",
+			subtotal:    1000,
+			discount:    333.333,
+			wantService: 66.67, // 666.67 * 10%
+			wantTaxBase: 733.34,
+			wantTax:     73.33,
+			wantTotal:   806.67,
+			wantCharges: []string{"SERVICE_CHARGE", "TAX"},
+		},
+		{
+			name:        "persentase desimal",
+			env:         map[string]string{"SERVICE_CHARGE_PERCENT": "5.5", "TAX_PERCENT": "11"},
+			subtotal:    200000,
+			wantService: 11000,
+			wantTaxBase: 211000,
+			wantTax:     23210,
+			wantTotal:   234210,
+			wantCharges: []string{"SERVICE_CHARGE", "TAX"},
+		},
+		{
+			name:        "service charge 0 tidak masuk rincian",
+			env:         map[string]string{"SERVICE_CHARGE_PERCENT": "0"},
+			subtotal:    500000,
+			wantTaxBase: 500000,
+			wantTax:     50000,
+			wantTotal:   550000,
+			wantCharges: []string{"TAX"},
+		},
+		{
+			name:        "pajak 0 tidak masuk rincian",
+			env:         map[string]string{"TAX_PERCENT": "0"},
+			subtotal:    500000,
+			wantService: 50000,
+			wantTotal:   550000,
+			wantCharges: []string{"SERVICE_CHARGE"},
+		},
+		{
+			name:        "persentase negatif memakai default",
+			env:         map[string]string{"SERVICE_CHARGE_PERCENT": "-5", "TAX_PERCENT": "abc"},
+			subtotal:    1000000,
+			wantService: 100000,
+			wantTaxBase: 1100000,
+			wantTax:     110000,
+			wantTotal:   1210000,
+			wantCharges: []string{"SERVICE_CHARGE", "TAX"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"SERVICE_CHARGE_PERCENT", "TAX_PERCENT", "TAX_ON_SERVICE_CHARGE"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			charges, service, tax, total := applyCharges(tt.subtotal, tt.discount)
+			if service != tt.wantService || tax != tt.wantTax || total != tt.wantTotal {
+				t.Errorf("service %v, pajak %v, total %v; want %v, %v, %v", service, tax, total, tt.wantService, tt.wantTax, tt.wantTotal)
+			}
+
+			if len(charges) != len(tt.wantCharges) {
+				t.Fatalf("%d rincian, want %v", len(charges), tt.wantCharges)
+			}
+			for i, charge := range charges {
+				if charge.Code != tt.wantCharges[i] {
+					t.Errorf("rincian %d: %s, want %s", i, charge.Code, tt.wantCharges[i])
+				}
+				switch charge.Code {
+				case "SERVICE_CHARGE":
+					if charge.Base != roundMoney(tt.subtotal-tt.discount) || charge.Amount != tt.wantService {
+						t.Errorf("service charge base %v amount %v", charge.Base, charge.Amount)
+					}
+				case "TAX":
+					if charge.Base != tt.wantTaxBase || charge.Amount != tt.wantTax {
+						t.Errorf("pajak base %v amount %v, want %v %v", charge.Base, charge.Amount, tt.wantTaxBase, tt.wantTax)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package catalog
+
+import (
+	"astro-backend/models"
+	repository "astro-backend/repository/admin"
+	"astro-backend/utils"
+	"errors"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrRoomNotFound = errors.New("Kamar tidak ditemukan")
+
+// RoomSortFields adalah nilai sort_by katalog; kamar tidak bisa diurutkan
+// berdasarkan field internal.
+var RoomSortFields = map[string]string{
+	"price":    "price_per_night",
+	"capacity": "capacity",
+}
+
+// RoomFilter adalah filter katalog dari query string. Semua field opsional;
+// ID masih berupa hex dan divalidasi di service.
+type RoomFilter struct {
+	Category    string
+	BedType     string
+	RoomTypeID  string
+	FacilityIDs []string
+}
+
+// PublicRoomType adalah data tipe kamar yang aman ditampilkan ke tamu.
+type PublicRoomType struct {
+	ID                 string                     `json:"id"`
+	Name               string                     `json:"name"`
+	Description        string                     `json:"description"`
+	CancellationPolicy *models.CancellationPolicy `json:"cancellation_policy,omitempty"`
+}
+
+// PublicFacility adalah data fasilitas untuk tamu.
+type PublicFacility struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// PublicImage adalah foto galeri kamar untuk tamu, sudah dalam urutan tampil.
+// Variants kosong untuk foto lama yang di-upload sebelum ada varian.
+type PublicImage struct {
+	URL      string                `json:"url"`
+	Alt      string                `json:"alt"`
+	Caption  string                `json:"caption"`
+	Primary  bool                  `json:"primary"`
+	Width    int                   `json:"width,omitempty"`
+	Height   int                   `json:"height,omitempty"`
+	Variants *models.ImageVariants `json:"variants,omitempty"`
+}
+
+// PublicRoom adalah kamar versi katalog. Nomor kamar, flag availability,
+// daftar ID mentah dan timestamp sengaja tidak ikut.
+type PublicRoom struct {
+	ID            string           `json:"id"`
+	Name          string           `json:"name"`
+	Description   string           `json:"description"`
+	PricePerNight float64          `json:"price_per_night"`
+	CoverImage    *PublicImage     `json:"cover_image,omitempty"`
+	Images        []PublicImage    `json:"images"`
+	BedType       string           `json:"bed_type"`
+	Capacity      int              `json:"capacity"`
+	Category      string           `json:"category"`
+	RoomType      *PublicRoomType  `json:"room_type,omitempty"`
+	Facilities    []PublicFacility `json:"facilities"`
+}
+
+type CatalogService interface {
+	ListRooms(filter RoomFilter, q utils.ListQuery) (utils.ListPage[PublicRoom], error)
+	GetRoom(id string) (PublicRoom, error)
+	ListRoomTypes() ([]PublicRoomType, error)
+	ListFacilities() ([]PublicFacility, error)
+}
+
+type catalogService struct {
+	roomRepo     repository.RoomRepository
+	roomTypeRepo repository.RoomTypeRepository
+	facilityRepo repository.FacilityRepository
+}
+
+func NewCatalogService(roomRepo repository.RoomRepository, roomTypeRepo repository.RoomTypeRepository, facilityRepo repository.FacilityRepository) CatalogService {
+	return &catalogService{roomRepo: roomRepo, roomTypeRepo: roomTypeRepo, facilityRepo: facilityRepo}
+}
+
+// ListRooms mengembalikan satu halaman katalog. Hanya kamar dengan
+// availability true yang ikut.
+func (s *catalogService) ListRooms(filter RoomFilter, q utils.ListQuery) (utils.ListPage[PublicRoom], error) {
+	available := true
+	roomFilter := repository.RoomListFilter{
+		Category:     strings.TrimSpace(filter.Category),
+		BedType:      strings.TrimSpace(filter.BedType),
+		Availability: &available,
+	}
+
+	if filter.RoomTypeID != "" {
+		oid, err := primitive.ObjectIDFromHex(filter.RoomTypeID)
+		if err != nil {
+			return utils.ListPage[PublicRoom]{}, errors.New("room_type_id tidak valid")
+		}
+		roomFilter.RoomTypeID = &oid
+	}
+	for _, id := range filter.FacilityIDs {
+		oid, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return utils.ListPage[PublicRoom]{}, errors.New("ID fasilitas tidak valid: " + id)
+		}
+		roomFilter.FacilityIDs = append(roomFilter.FacilityIDs, oid)
+	}
+
+	rooms, err := s.roomRepo.List(roomFilter, q)
+	if err != nil {
+		return utils.ListPage[PublicRoom]{}, err
+	}
+
+	data := make([]PublicRoom, 0, len(rooms.Data))
+	for _, room := range rooms.Data {
+		data = append(data, toPublicRoom(room))
+	}
+
+	return utils.NewListPage(q, data, rooms.Total, rooms.NextCursor), nil
+}
+
+// GetRoom mengembalikan detail kamar. Kamar yang availability-nya false
+// diperlakukan seperti tidak ada.
+func (s *catalogService) GetRoom(id string) (PublicRoom, error) {
+	room, err := s.roomRepo.GetByID(id)
+	if err != nil || !room.Availability {
+		return PublicRoom{}, ErrRoomNotFound
+	}
+	return toPublicRoom(room), nil
+}
+
+func (s *catalogService) ListRoomTypes() ([]PublicRoomType, error) {
+	roomTypes, err := s.roomTypeRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]PublicRoomType, 0, len(roomTypes))
+	for _, rt := range roomTypes {
+		data = append(data, toPublicRoomType(rt))
+	}
+	return data, nil
+}
+
+func (s *catalogService) ListFacilities() ([]PublicFacility, error) {
+	facilities, err := s.facilityRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return toPublicFacilities(facilities), nil
+}
+
+func toPublicRoom(room models.Room) PublicRoom {
+	out := PublicRoom{
+		ID:            room.Id.Hex(),
+		Name:          room.Name,
+		Description:   room.Description,
+		PricePerNight: room.PricePerNight,
+		Images:        make([]PublicImage, 0, len(room.Images)),
+		BedType:       room.Bed_type,
+		Capacity:      room.Capacity,
+		Category:      room.Category,
+		Facilities:    toPublicFacilities(room.Facilities),
+	}
+	// foto yang masih diproses atau gagal diproses tidak ditampilkan
+	for _, img := range room.Images {
+		if !img.Ready() {
+			continue
+		}
+		image := PublicImage{
+			URL:      img.URL,
+			Alt:      img.Alt,
+			Caption:  img.Caption,
+			Primary:  img.Primary,
+			Width:    img.Width,
+			Height:   img.Height,
+			Variants: img.Variants,
+		}
+		out.Images = append(out.Images, image)
+		if img.Primary {
+			out.CoverImage = &image
+		}
+	}
+	// cover yang belum siap digantikan foto pertama yang sudah siap
+	if out.CoverImage == nil && len(out.Images) > 0 {
+		out.CoverImage = &out.Images[0]
+	}
+	// hasil $lookup berupa array; room_type_id hanya menunjuk satu dokumen
+	if len(room.RoomType) > 0 {
+		rt := toPublicRoomType(room.RoomType[0])
+		out.RoomType = &rt
+	}
+	return out
+}
+
+func toPublicRoomType(rt models.RoomType) PublicRoomType {
+	return PublicRoomType{
+		ID:                 rt.ID.Hex(),
+		Name:               rt.Name,
+		Description:        rt.Description,
+		CancellationPolicy: rt.CancellationPolicy,
+	}
+}
+
+func toPublicFacilities(facilities []models.Facility) []PublicFacility {
+	out := make([]PublicFacility, 0, len(facilities))
+	for _, f := range facilities {
+		out = append(out, PublicFacility{ID: f.ID.Hex(), Name: f.Name})
+	}
+	return out
+}
@@ -0,0 +1,168 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// buildTIFF membuat header TIFF dengan satu IFD berisi entri tag/nilai.
+func buildTIFF(order binary.ByteOrder, entries ...[2]uint16) []byte {
+	var buf bytes.Buffer
+	if order == binary.LittleEndian {
+		buf.WriteString("II")
+	} else {
+		buf.WriteString("MM")
+	}
+	binary.Write(&buf, order, uint16(42))
+	binary.Write(&buf, order, uint32(8))
+	binary.Write(&buf, order, uint16(len(entries)))
+	for _, e := range entries {
+		binary.Write(&buf, order, e[0])      // tag
+		binary.Write(&buf, order, uint16(3)) // SHORT
+		binary.Write(&buf, order, uint32(1)) // count
+		binary.Write(&buf, order, e[1])
+		binary.Write(&buf, order, uint16(0)) // padding nilai 4 byte
+	}
+	binary.Write(&buf, order, uint32(0)) // tidak ada IFD berikutnya
+	return buf.Bytes()
+}
+
+func segment(marker byte, payload []byte) []byte {
+	seg := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+func buildJPEG(segments ...[]byte) []byte {
+	out := []byte{0xFF, 0xD8}
+	for _, s := range segments {
+		out = append(out, s...)
+	}
+	return append(out, 0xFF, 0xD9)
+}
+
+func exifSegment(tiff []byte) []byte {
+	return segment(0xE1, append([]byte("Exif\x00\x00"), tiff...))
+}
+
+func TestTIFFOrientation(t *testing.T) {
+	valid := buildTIFF(binary.LittleEndian, [2]uint16{0x010F, 7}, [2]uint16{0x0112, 6})
+
+	outOfRange := append([]byte{}, valid...)
+	binary.LittleEndian.PutUint32(outOfRange[4:], uint32(len(valid)))
+
+	hugeOffset := append([]byte{}, valid...)
+	binary.LittleEndian.PutUint32(hugeOffset[4:], 0xFFFFFFFF)
+
+	// tanpa tag orientasi, jadi pembacaan harus berhenti di akhir data
+	tooManyEntries := buildTIFF(binary.LittleEndian, [2]uint16{0x010F, 7})
+	binary.LittleEndian.PutUint16(tooManyEntries[8:], 0xFFFF)
+
+	wrongOrder := append([]byte{}, valid...)
+	copy(wrongOrder, "MM")
+
+	tests := []struct {
+		name string
+		tiff []byte
+		want int
+	}{
+		{"little endian", valid, 6},
+		{"big endian", buildTIFF(binary.BigEndian, [2]uint16{0x0112, 8}), 8},
+		{"tag tidak ada", buildTIFF(binary.LittleEndian, [2]uint16{0x010F, 3}), 1},
+		{"nilai nol", buildTIFF(binary.LittleEndian, [2]uint16{0x0112, 0}), 1},
+		{"nilai di atas 8", buildTIFF(binary.BigEndian, [2]uint16{0x0112, 9}), 1},
+		{"byte order tidak dikenal", append([]byte("XX"), valid[2:]...), 1},
+		{"byte order salah", wrongOrder, 1},
+		{"terlalu pendek", valid[:7], 1},
+		{"kosong", nil, 1},
+		{"offset IFD di luar data", outOfRange, 1},
+		{"offset IFD sangat besar", hugeOffset, 1},
+		{"jumlah entri melebihi data", tooManyEntries, 1},
+		{"entri terpotong", valid[:8+2+12+6], 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tiffOrientation(tt.tiff); got != tt.want {
+				t.Fatalf("tiffOrientation = %d, mau %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	tiff := buildTIFF(binary.BigEndian, [2]uint16{0x0112, 6})
+	app0 := segment(0xE0, []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"))
+	sos := segment(0xDA, []byte{0, 0, 0})
+
+	valid := buildJPEG(app0, exifSegment(tiff), sos)
+
+	badSize := buildJPEG(exifSegment(tiff))
+	binary.BigEndian.PutUint16(badSize[4:], 1)
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"exif setelah APP0", valid, 6},
+		{"exif langsung setelah SOI", buildJPEG(exifSegment(tiff), sos), 6},
+		{"kosong", nil, 1},
+		{"bukan jpeg", []byte("\x89PNG\r\n\x1a\n"), 1},
+		{"hanya SOI", []byte{0xFF, 0xD8}, 1},
+		{"tanpa exif", buildJPEG(app0, sos), 1},
+		{"APP1 bukan exif", buildJPEG(segment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00")), sos), 1},
+		{"exif setelah SOS diabaikan", buildJPEG(sos, exifSegment(tiff)), 1},
+		{"marker rusak", append([]byte{0xFF, 0xD8, 0x00, 0xE1}, valid[4:]...), 1},
+		{"ukuran segmen kurang dari 2", badSize, 1},
+		{"segmen terpotong", valid[:len(valid)-len(sos)-10], 1},
+		{"exif dengan tiff rusak", buildJPEG(exifSegment([]byte("MM\x00")), sos), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(bytes.NewReader(tt.data)); got != tt.want {
+				t.Fatalf("jpegOrientation = %d, mau %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	// 2x1: piksel kiri merah, kanan biru
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	src.SetNRGBA(0, 0, red)
+	src.SetNRGBA(1, 0, blue)
+
+	tests := []struct {
+		orientation int
+		w, h        int
+		first, last color.NRGBA // piksel (0,0) dan piksel terakhir
+	}{
+		{1, 2, 1, red, blue},
+		{2, 2, 1, blue, red},
+		{3, 2, 1, blue, red},
+		{6, 1, 2, red, blue},
+		{8, 1, 2, blue, red},
+		{9, 2, 1, red, blue},
+	}
+
+	for _, tt := range tests {
+		got := applyOrientation(src, tt.orientation)
+		b := got.Bounds()
+		if b.Dx() != tt.w || b.Dy() != tt.h {
+			t.Fatalf("orientasi %d: ukuran %dx%d, mau %dx%d", tt.orientation, b.Dx(), b.Dy(), tt.w, tt.h)
+		}
+		if c := got.NRGBAAt(0, 0); c != tt.first {
+			t.Fatalf("orientasi %d: piksel pertama %v, mau %v", tt.orientation, c, tt.first)
+		}
+		if c := got.NRGBAAt(b.Dx()-1, b.Dy()-1); c != tt.last {
+			t.Fatalf("orientasi %d: piksel terakhir %v, mau %v", tt.orientation, c, tt.last)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package media
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	"astro-backend/repository/admin"
+	"errors"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrQueueFull = errors.New("Antrian pemrosesan gambar sedang penuh, silakan coba lagi")
+
+// submitWait adalah batas waktu menunggu slot antrian sebelum Submit
+// menyerah, supaya request tidak tertahan lama saat worker sibuk.
+const submitWait = 2 * time.Second
+
+// ImageJob adalah satu upload yang menunggu dibuatkan varian. Foto dengan
+// ImageID sudah tersimpan di galeri kamar dengan status PROCESSING.
+type ImageJob struct {
+	RoomID  primitive.ObjectID
+	ImageID primitive.ObjectID
+	Upload  Upload
+}
+
+// ImageProcessor memproses upload foto kamar di pool worker berukuran tetap
+// (UPLOAD_WORKERS) dengan antrian terbatas (UPLOAD_QUEUE_SIZE). Request
+// handler cukup menaruh job lalu langsung merespons.
+type ImageProcessor interface {
+	Start()
+	Stop()
+	Submit(job ImageJob) error
+}
+
+type imageProcessor struct {
+	repo      admin.RoomRepository
+	dir       string
+	urlPrefix string
+	workers   int
+	jobs      chan ImageJob
+	wg        sync.WaitGroup
+	once      sync.Once
+	stopOnce  sync.Once
+	// mu menjaga jobs supaya Submit tidak mengirim ke channel yang sudah
+	// ditutup Stop
+	mu      sync.RWMutex
+	stopped bool
+}
+
+func NewImageProcessor(repo admin.RoomRepository) ImageProcessor {
+	return &imageProcessor{
+		repo:      repo,
+		dir:       filepath.Join("uploads", "rooms"),
+		urlPrefix: "/uploads/rooms",
+		workers:   envInt("UPLOAD_WORKERS", 2),
+		jobs:      make(chan ImageJob, envInt("UPLOAD_QUEUE_SIZE", 32)),
+	}
+}
+
+func (p *imageProcessor) Start() {
+	p.once.Do(func() {
+		for i := 0; i < p.workers; i++ {
+			p.wg.Add(1)
+			go p.work()
+		}
+		log.Info().Int("workers", p.workers).Int("queue", cap(p.jobs)).Msg("image processor started")
+	})
+}
+
+// Stop menutup antrian dan menunggu job yang tersisa selesai.
+func (p *imageProcessor) Stop() {
+	p.stopOnce.Do(func() {
+		p.mu.Lock()
+		p.stopped = true
+		close(p.jobs)
+		p.mu.Unlock()
+		p.wg.Wait()
+	})
+}
+
+// Submit menaruh job di antrian. Kalau antrian tetap penuh setelah
+// submitWait, ErrQueueFull dikembalikan dan file staging menjadi tanggung
+// jawab pemanggil. Setelah Stop, Submit juga mengembalikan ErrQueueFull.
+func (p *imageProcessor) Submit(job ImageJob) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.stopped {
+		return ErrQueueFull
+	}
+
+	timer := time.NewTimer(submitWait)
+	defer timer.Stop()
+
+	select {
+	case p.jobs <- job:
+		return nil
+	case <-timer.C:
+		return ErrQueueFull
+	}
+}
+
+func (p *imageProcessor) work() {
+	defer p.wg.Done()
+	for job := range p.jobs {
+		p.process(job)
+	}
+}
+
+func (p *imageProcessor) process(job ImageJob) {
+	defer Discard(job.Upload)
+
+	logger := log.With().Str("room_id", job.RoomID.Hex()).Str("image_id", job.ImageID.Hex()).Logger()
+
+	rendered, err := Render(job.Upload, p.dir, p.urlPrefix, job.ImageID.Hex())
+	if err != nil {
+		logger.Warn().Err(err).Msg("gagal memproses foto kamar")
+		if _, err := p.repo.SetImageResult(job.RoomID, job.ImageID, models.RoomImage{Status: constants.ImageStatusFailed}); err != nil {
+			logger.Error().Err(err).Msg("gagal menandai foto kamar gagal diproses")
+		}
+		return
+	}
+
+	variants := rendered.Variants
+	found, err := p.repo.SetImageResult(job.RoomID, job.ImageID, models.RoomImage{
+		URL:      rendered.URL,
+		Width:    rendered.Width,
+		Height:   rendered.Height,
+		Variants: &variants,
+		Status:   constants.ImageStatusReady,
+	})
+	if err != nil || !found {
+		// foto sudah dihapus dari galeri (atau gagal disimpan): buang hasilnya
+		if err != nil {
+			logger.Error().Err(err).Msg("gagal menyimpan hasil foto kamar")
+		}
+		RemoveFiles(rendered.Files)
+	}
+}
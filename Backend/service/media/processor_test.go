@@ -0,0 +1,17 @@
+package media
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSubmitAfterStop(t *testing.T) {
+	p := NewImageProcessor(nil)
+	p.Start()
+	p.Stop()
+	p.Stop() // aman dipanggil dua kali
+
+	if err := p.Submit(ImageJob{}); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Submit setelah Stop: err = %v, mau ErrQueueFull", err)
+	}
+}
@@ -0,0 +1,158 @@
+package media
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/image/draw"
+)
+
+const jpegQuality = 85
+
+// variantSizes adalah sisi terpanjang setiap varian. Gambar tidak pernah
+// diperbesar; kalau aslinya lebih kecil, ukuran asli yang dipakai.
+var variantSizes = []struct {
+	name string
+	size int
+}{
+	{constants.ImageVariantThumbnail, 320},
+	{constants.ImageVariantMedium, 800},
+	{constants.ImageVariantLarge, 1600},
+}
+
+// Rendered adalah hasil pemrosesan satu upload.
+type Rendered struct {
+	URL      string
+	Width    int
+	Height   int
+	Variants models.ImageVariants
+	Files    []string // semua file yang ditulis, untuk dibersihkan kalau gagal
+}
+
+// Render men-decode upload, menerapkan orientasi EXIF, lalu menulis ulang
+// gambar asli beserta varian thumbnail, medium dan large ke dir dengan nama
+// name[_varian].ext. Encode ulang membuang semua metadata (EXIF, GPS, XMP).
+// PNG dan gambar transparan disimpan sebagai PNG, selebihnya JPEG.
+func Render(upload Upload, dir, urlPrefix, name string) (Rendered, error) {
+	img, err := decode(upload)
+	if err != nil {
+		return Rendered{}, err
+	}
+
+	ext := ".jpg"
+	if upload.Format == "png" || !img.Opaque() {
+		ext = ".png"
+	}
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return Rendered{}, err
+	}
+
+	var out Rendered
+	write := func(suffix string, m image.Image) (string, error) {
+		filename := name + suffix + ext
+		path := filepath.Join(dir, filename)
+		if err := encode(path, m, ext); err != nil {
+			return "", err
+		}
+		out.Files = append(out.Files, path)
+		return urlPrefix + "/" + filename, nil
+	}
+
+	bounds := img.Bounds()
+	out.Width, out.Height = bounds.Dx(), bounds.Dy()
+	if out.URL, err = write("", img); err != nil {
+		RemoveFiles(out.Files)
+		return Rendered{}, err
+	}
+
+	for _, v := range variantSizes {
+		url, err := write("_"+v.name, resize(img, v.size))
+		if err != nil {
+			RemoveFiles(out.Files)
+			return Rendered{}, err
+		}
+		switch v.name {
+		case constants.ImageVariantThumbnail:
+			out.Variants.Thumbnail = url
+		case constants.ImageVariantMedium:
+			out.Variants.Medium = url
+		case constants.ImageVariantLarge:
+			out.Variants.Large = url
+		}
+	}
+
+	return out, nil
+}
+
+// RemoveFiles menghapus file hasil Render.
+func RemoveFiles(paths []string) {
+	for _, p := range paths {
+		os.Remove(p)
+	}
+}
+
+func decode(upload Upload) (*image.NRGBA, error) {
+	f, err := os.Open(upload.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	src, format, err := image.Decode(f)
+	if err != nil || format != upload.Format {
+		return nil, ErrUnsupportedType
+	}
+
+	b := src.Bounds()
+	img := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(img, img.Bounds(), src, b.Min, draw.Src)
+
+	if upload.Format == "jpeg" {
+		if _, err := f.Seek(0, 0); err == nil {
+			img = applyOrientation(img, jpegOrientation(f))
+		}
+	}
+	return img, nil
+}
+
+// resize mengecilkan gambar sampai sisi terpanjangnya maxSide.
+func resize(src *image.NRGBA, maxSide int) image.Image {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	if w <= maxSide && h <= maxSide {
+		return src
+	}
+
+	dw, dh := maxSide, h*maxSide/w
+	if h > w {
+		dw, dh = w*maxSide/h, maxSide
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, max(dw, 1), max(dh, 1)))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Src, nil)
+	return dst
+}
+
+func encode(path string, img image.Image, ext string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if ext == ".png" {
+		err = png.Encode(f, img)
+	} else {
+		err = jpeg.Encode(f, img, &jpeg.Options{Quality: jpegQuality})
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
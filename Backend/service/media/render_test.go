@@ -0,0 +1,40 @@
+package media
+
+import (
+	"image"
+	"testing"
+)
+
+func TestResize(t *testing.T) {
+	tests := []struct {
+		name         string
+		w, h, max    int
+		wantW, wantH int
+	}{
+		{"landscape", 1000, 500, 320, 320, 160},
+		{"portrait", 500, 1000, 320, 160, 320},
+		{"persegi", 800, 800, 320, 320, 320},
+		{"tidak diperbesar", 200, 100, 320, 200, 100},
+		{"tepat di batas", 320, 240, 320, 320, 240},
+		{"sangat lebar minimal 1px", 5000, 1, 320, 320, 1},
+		{"sangat tinggi minimal 1px", 1, 5000, 320, 1, 320},
+		{"pembulatan ke bawah", 1001, 333, 320, 320, 106},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := image.NewNRGBA(image.Rect(0, 0, tt.w, tt.h))
+			got := resize(src, tt.max).Bounds()
+			if got.Dx() != tt.wantW || got.Dy() != tt.wantH {
+				t.Fatalf("resize %dx%d ke %d = %dx%d, mau %dx%d", tt.w, tt.h, tt.max, got.Dx(), got.Dy(), tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestResizeKeepsSmallImage(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	if got := resize(src, 320); got != image.Image(src) {
+		t.Fatal("gambar yang lebih kecil dari batas harus dikembalikan apa adanya")
+	}
+}
@@ -0,0 +1,144 @@
+package media
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strconv"
+
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrUnsupportedType = errors.New("File harus berupa gambar JPEG, PNG atau WebP")
+	ErrFileTooLarge    = errors.New("Ukuran file gambar melebihi batas")
+	ErrImageTooLarge   = errors.New("Dimensi gambar melebihi batas")
+)
+
+// allowedTypes memetakan hasil sniffing http.DetectContentType ke nama
+// format dari image.DecodeConfig. Keduanya harus cocok.
+var allowedTypes = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+// Upload adalah file gambar yang sudah lolos validasi dan disalin ke file
+// staging sementara. Path dihapus oleh worker setelah diproses, atau lewat
+// Discard kalau tidak jadi diproses.
+type Upload struct {
+	Path   string
+	Format string // jpeg, png atau webp
+	Width  int
+	Height int
+}
+
+// Limits adalah batas upload gambar dari environment.
+type Limits struct {
+	MaxBytes  int64
+	MaxWidth  int
+	MaxHeight int
+}
+
+// LimitsFromEnv membaca UPLOAD_MAX_BYTES, UPLOAD_MAX_WIDTH dan
+// UPLOAD_MAX_HEIGHT. Default 10 MB dan 6000x6000 piksel.
+func LimitsFromEnv() Limits {
+	return Limits{
+		MaxBytes:  int64(envInt("UPLOAD_MAX_BYTES", 10<<20)),
+		MaxWidth:  envInt("UPLOAD_MAX_WIDTH", 6000),
+		MaxHeight: envInt("UPLOAD_MAX_HEIGHT", 6000),
+	}
+}
+
+// Stage memvalidasi file upload dari isinya, bukan dari nama file atau
+// Content-Type kiriman client, lalu menyalinnya ke file staging. Hanya
+// header gambar yang dibaca di sini; decode penuh dilakukan worker.
+func Stage(file *multipart.FileHeader, limits Limits) (Upload, error) {
+	if file.Size > limits.MaxBytes {
+		return Upload{}, fmt.Errorf("%w (maksimal %d MB)", ErrFileTooLarge, limits.MaxBytes>>20)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return Upload{}, err
+	}
+	defer src.Close()
+
+	br := bufio.NewReaderSize(src, 512)
+	head, err := br.Peek(512)
+	if err != nil && err != io.EOF {
+		return Upload{}, err
+	}
+	format, ok := allowedTypes[http.DetectContentType(head)]
+	if !ok {
+		return Upload{}, ErrUnsupportedType
+	}
+
+	tmp, err := os.CreateTemp("", "room-upload-*")
+	if err != nil {
+		return Upload{}, err
+	}
+	upload := Upload{Path: tmp.Name(), Format: format}
+
+	// batas dicek lagi saat menyalin karena file.Size berasal dari client
+	n, err := io.Copy(tmp, io.LimitReader(br, limits.MaxBytes+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && n > limits.MaxBytes {
+		err = fmt.Errorf("%w (maksimal %d MB)", ErrFileTooLarge, limits.MaxBytes>>20)
+	}
+	if err == nil {
+		err = checkDimensions(&upload, limits)
+	}
+	if err != nil {
+		os.Remove(upload.Path)
+		return Upload{}, err
+	}
+
+	return upload, nil
+}
+
+// Discard menghapus file staging yang tidak jadi diproses.
+func Discard(uploads ...Upload) {
+	for _, u := range uploads {
+		if u.Path != "" {
+			os.Remove(u.Path)
+		}
+	}
+}
+
+// checkDimensions membaca ukuran gambar tanpa decode penuh, sehingga gambar
+// raksasa (decompression bomb) ditolak sebelum memakan memori.
+func checkDimensions(upload *Upload, limits Limits) error {
+	f, err := os.Open(upload.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cfg, format, err := image.DecodeConfig(f)
+	if err != nil || format != upload.Format {
+		return ErrUnsupportedType
+	}
+	if cfg.Width > limits.MaxWidth || cfg.Height > limits.MaxHeight {
+		return fmt.Errorf("%w (maksimal %dx%d piksel)", ErrImageTooLarge, limits.MaxWidth, limits.MaxHeight)
+	}
+
+	upload.Width, upload.Height = cfg.Width, cfg.Height
+	return nil
+}
+
+func envInt(key string, fallback int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v > 0 {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,132 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"mime/multipart"
+	"os"
+	"testing"
+)
+
+// fileHeader membungkus data sebagai file upload multipart seperti yang
+// diterima handler.
+func fileHeader(t *testing.T, filename string, data []byte) *multipart.FileHeader {
+	t.Helper()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("images", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	w.Close()
+
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { form.RemoveAll() })
+	return form.File["images"][0]
+}
+
+func pngBytes(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	img.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func jpegBytes(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestStage(t *testing.T) {
+	limits := Limits{MaxBytes: 64 << 10, MaxWidth: 100, MaxHeight: 80}
+	png := pngBytes(t, 40, 30)
+
+	tests := []struct {
+		name     string
+		filename string
+		data     []byte
+		size     int64 // kalau diisi, menimpa ukuran yang dilaporkan client
+		wantErr  error
+		format   string
+	}{
+		{name: "png", filename: "kamar.png", data: png, format: "png"},
+		{name: "jpeg", filename: "kamar.jpg", data: jpegBytes(t, 100, 80), format: "jpeg"},
+		{name: "ekstensi menipu tetap dibaca dari isi", filename: "kamar.jpg", data: png, format: "png"},
+		{name: "bukan gambar", filename: "kamar.png", data: []byte("<html><body>bukan gambar</body></html>"), wantErr: ErrUnsupportedType},
+		{name: "gif tidak diizinkan", filename: "kamar.gif", data: []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;"), wantErr: ErrUnsupportedType},
+		{name: "header png tapi rusak", filename: "kamar.png", data: png[:20], wantErr: ErrUnsupportedType},
+		{name: "file kosong", filename: "kamar.png", data: nil, wantErr: ErrUnsupportedType},
+		{name: "ukuran dari client terlalu besar", filename: "kamar.png", data: png, size: limits.MaxBytes + 1, wantErr: ErrFileTooLarge},
+		{name: "ukuran dari client dipalsukan", filename: "kamar.png", data: append(append([]byte{}, png...), make([]byte, limits.MaxBytes)...), size: 10, wantErr: ErrFileTooLarge},
+		{name: "lebar melebihi batas", filename: "kamar.png", data: pngBytes(t, 101, 10), wantErr: ErrImageTooLarge},
+		{name: "tinggi melebihi batas", filename: "kamar.jpg", data: jpegBytes(t, 10, 81), wantErr: ErrImageTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fh := fileHeader(t, tt.filename, tt.data)
+			if tt.size != 0 {
+				fh.Size = tt.size
+			}
+
+			upload, err := Stage(fh, limits)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, mau %v", err, tt.wantErr)
+				}
+				if upload.Path != "" {
+					t.Fatalf("upload gagal tidak boleh mengembalikan path, dapat %q", upload.Path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Stage: %v", err)
+			}
+			defer Discard(upload)
+
+			if upload.Format != tt.format {
+				t.Fatalf("format = %q, mau %q", upload.Format, tt.format)
+			}
+			if _, err := os.Stat(upload.Path); err != nil {
+				t.Fatalf("file staging tidak ada: %v", err)
+			}
+			if upload.Width == 0 || upload.Height == 0 {
+				t.Fatalf("dimensi tidak terbaca: %dx%d", upload.Width, upload.Height)
+			}
+		})
+	}
+}
+
+func TestStageDimensions(t *testing.T) {
+	limits := Limits{MaxBytes: 64 << 10, MaxWidth: 100, MaxHeight: 100}
+
+	upload, err := Stage(fileHeader(t, "kamar.png", pngBytes(t, 100, 100)), limits)
+	if err != nil {
+		t.Fatalf("gambar tepat di batas harus diterima: %v", err)
+	}
+	if upload.Width != 100 || upload.Height != 100 {
+		t.Fatalf("dimensi = %dx%d, mau 100x100", upload.Width, upload.Height)
+	}
+
+	Discard(upload)
+	if _, err := os.Stat(upload.Path); !os.IsNotExist(err) {
+		t.Fatalf("Discard tidak menghapus file staging: %v", err)
+	}
+}
@@ -0,0 +1,253 @@
+package payment
+
+import (
+	"astro-backend/constants"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Token yang dikenali FakeProvider untuk mensimulasikan hasil pembayaran.
+const (
+	FakeTokenSuccess     = "tok_success"      // langsung berhasil (default kalau token kosong)
+	FakeTokenDeclined    = "tok_declined"     // langsung gagal
+	FakeTokenDelayed     = "tok_delayed"      // PENDING, berhasil setelah FAKE_PAYMENT_DELAY
+	FakeTokenDelayedFail = "tok_delayed_fail" // PENDING, gagal setelah FAKE_PAYMENT_DELAY
+)
+
+// FakeProvider adalah gateway lokal untuk development dan testing offline.
+// Transaksi disimpan di memori. Hasil yang tertunda dikirim sebagai webhook
+// bertanda tangan ke FAKE_PAYMENT_WEBHOOK_URL, dan juga terlihat lewat Status.
+type FakeProvider struct {
+	mu         sync.Mutex
+	txns       map[string]*fakeTxn
+	secret     string
+	webhookURL string
+	delay      time.Duration
+	client     *http.Client
+}
+
+type fakeTxn struct {
+	amount   float64
+	captured float64
+	refunded float64
+	capture  bool
+	status   string
+	reason   string
+}
+
+func NewFakeProvider() *FakeProvider {
+	delay := 5 * time.Second
+	if d, err := time.ParseDuration(os.Getenv("FAKE_PAYMENT_DELAY")); err == nil && d >= 0 {
+		delay = d
+	}
+
+	webhookURL := os.Getenv("FAKE_PAYMENT_WEBHOOK_URL")
+	if webhookURL == "" {
+		port := os.Getenv("PORT")
+		if port == "" {
+			port = "8080"
+		}
+		webhookURL = "http://localhost:" + port + "/api/payments/webhook/fake"
+	}
+
+	return &FakeProvider{
+		txns:       map[string]*fakeTxn{},
+		secret:     os.Getenv("PAYMENT_WEBHOOK_SECRET"),
+		webhookURL: webhookURL,
+		delay:      delay,
+		client:     &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (*FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) Charge(_ context.Context, req ChargeRequest) (ProviderResult, error) {
+	if req.Amount <= 0 {
+		return ProviderResult{}, errors.New("amount harus lebih dari 0")
+	}
+
+	ref := "fake_" + randomHex(12)
+	txn := &fakeTxn{amount: req.Amount, capture: req.Capture}
+
+	switch req.Token {
+	case "", FakeTokenSuccess:
+		p.settle(txn, true)
+	case FakeTokenDeclined:
+		txn.status = constants.PaymentStatusFailed
+		txn.reason = "card_declined"
+	case FakeTokenDelayed, FakeTokenDelayedFail:
+		txn.status = constants.PaymentStatusPending
+		succeed := req.Token == FakeTokenDelayed
+		time.AfterFunc(p.delay, func() { p.complete(ref, succeed) })
+	default:
+		return ProviderResult{}, errors.New("token pembayaran tidak dikenal")
+	}
+
+	p.mu.Lock()
+	p.txns[ref] = txn
+	p.mu.Unlock()
+
+	return ProviderResult{Reference: ref, Status: txn.status, FailureReason: txn.reason}, nil
+}
+
+func (p *FakeProvider) Capture(_ context.Context, ref string, amount float64) (ProviderResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	txn, ok := p.txns[ref]
+	if !ok {
+		return ProviderResult{}, errors.New("transaksi tidak ditemukan di provider")
+	}
+	if txn.status != constants.PaymentStatusAuthorized {
+		return ProviderResult{}, errors.New("hanya transaksi AUTHORIZED yang bisa di-capture")
+	}
+	if amount <= 0 || amount > txn.amount {
+		return ProviderResult{}, errors.New("jumlah capture tidak valid")
+	}
+
+	txn.captured = amount
+	txn.status = constants.PaymentStatusCaptured
+	return ProviderResult{Reference: ref, Status: txn.status}, nil
+}
+
+func (p *FakeProvider) Refund(_ context.Context, ref string, amount float64) (ProviderResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	txn, ok := p.txns[ref]
+	if !ok {
+		return ProviderResult{}, errors.New("transaksi tidak ditemukan di provider")
+	}
+	if txn.status != constants.PaymentStatusCaptured && txn.status != constants.PaymentStatusPartiallyRefunded {
+		return ProviderResult{}, errors.New("hanya transaksi CAPTURED yang bisa di-refund")
+	}
+	if amount <= 0 || amount > txn.captured-txn.refunded {
+		return ProviderResult{}, errors.New("jumlah refund melebihi sisa dana")
+	}
+
+	txn.refunded += amount
+	txn.status = constants.PaymentStatusPartiallyRefunded
+	if txn.refunded >= txn.captured {
+		txn.status = constants.PaymentStatusRefunded
+	}
+	return ProviderResult{Reference: ref, Status: txn.status}, nil
+}
+
+func (p *FakeProvider) Status(_ context.Context, ref string) (ProviderResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	txn, ok := p.txns[ref]
+	if !ok {
+		return ProviderResult{}, errors.New("transaksi tidak ditemukan di provider")
+	}
+	return ProviderResult{Reference: ref, Status: txn.status, FailureReason: txn.reason}, nil
+}
+
+func (p *FakeProvider) ParseWebhook(header http.Header, body []byte) (WebhookEvent, error) {
+	if err := VerifySignature(p.secret, header.Get(SignatureHeader), body, time.Now()); err != nil {
+		return WebhookEvent{}, err
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return WebhookEvent{}, errors.New("payload webhook tidak valid")
+	}
+	if event.ID == "" || event.Reference == "" {
+		return WebhookEvent{}, errors.New("payload webhook tidak lengkap")
+	}
+	return event, nil
+}
+
+// settle menandai transaksi berhasil: CAPTURED, atau AUTHORIZED kalau
+// charge dibuat dengan capture=false. Harus dipanggil dengan mu terkunci
+// atau sebelum txn dibagikan.
+func (*FakeProvider) settle(txn *fakeTxn, ok bool) {
+	if !ok {
+		txn.status = constants.PaymentStatusFailed
+		txn.reason = "card_declined"
+		return
+	}
+	if txn.capture {
+		txn.status = constants.PaymentStatusCaptured
+		txn.captured = txn.amount
+		return
+	}
+	txn.status = constants.PaymentStatusAuthorized
+}
+
+// complete menyelesaikan transaksi tertunda lalu mengirim webhook.
+func (p *FakeProvider) complete(ref string, ok bool) {
+	p.mu.Lock()
+	txn, found := p.txns[ref]
+	if !found || txn.status != constants.PaymentStatusPending {
+		p.mu.Unlock()
+		return
+	}
+	p.settle(txn, ok)
+	event := WebhookEvent{
+		ID:        "evt_" + randomHex(12),
+		Reference: ref,
+		Amount:    txn.amount,
+		Reason:    txn.reason,
+	}
+	switch txn.status {
+	case constants.PaymentStatusCaptured:
+		event.Type = constants.PaymentEventCaptured
+	case constants.PaymentStatusAuthorized:
+		event.Type = constants.PaymentEventAuthorized
+	default:
+		event.Type = constants.PaymentEventFailed
+	}
+	p.mu.Unlock()
+
+	p.sendWebhook(event)
+}
+
+func (p *FakeProvider) sendWebhook(event WebhookEvent) {
+	if p.secret == "" {
+		log.Warn().Str("reference", event.Reference).Msg("PAYMENT_WEBHOOK_SECRET kosong, webhook fake provider tidak dikirim")
+		return
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		log.Error().Err(err).Msg("gagal membuat request webhook")
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(p.secret, time.Now(), body))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		log.Error().Err(err).Str("reference", event.Reference).Msg("gagal mengirim webhook fake provider")
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Error().Int("status", resp.StatusCode).Str("reference", event.Reference).Msg("webhook fake provider ditolak")
+	}
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,116 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSignature dikembalikan kalau signature webhook tidak valid atau kedaluwarsa.
+var ErrInvalidSignature = errors.New("signature webhook tidak valid")
+
+// SignatureHeader berisi "t=<unix timestamp>,v1=<hex HMAC-SHA256>". HMAC
+// dihitung dari "<timestamp>.<body>" dengan PAYMENT_WEBHOOK_SECRET.
+const SignatureHeader = "X-Payment-Signature"
+
+// webhookTolerance adalah selisih waktu maksimal webhook supaya tidak bisa di-replay.
+const webhookTolerance = 5 * time.Minute
+
+// ChargeRequest adalah permintaan charge ke provider.
+type ChargeRequest struct {
+	PaymentID   string
+	Amount      float64
+	Currency    string
+	Description string
+	// Token adalah data kartu / metode bayar yang sudah di-tokenisasi client.
+	Token string
+	// Capture false berarti dana hanya di-authorize dan di-capture belakangan.
+	Capture bool
+}
+
+// ProviderResult adalah status transaksi menurut provider.
+type ProviderResult struct {
+	Reference     string
+	Status        string // salah satu constants.PaymentStatus*
+	FailureReason string
+}
+
+// WebhookEvent adalah event dari provider yang sudah diverifikasi.
+type WebhookEvent struct {
+	ID        string  `json:"id"`
+	Type      string  `json:"type"` // constants.PaymentEvent*
+	Reference string  `json:"reference"`
+	Amount    float64 `json:"amount"`
+	Reason    string  `json:"reason,omitempty"`
+}
+
+// Provider adalah payment gateway. Implementasi harus aman dipakai dari
+// beberapa goroutine.
+type Provider interface {
+	Name() string
+	Charge(ctx context.Context, req ChargeRequest) (ProviderResult, error)
+	Capture(ctx context.Context, reference string, amount float64) (ProviderResult, error)
+	Refund(ctx context.Context, reference string, amount float64) (ProviderResult, error)
+	Status(ctx context.Context, reference string) (ProviderResult, error)
+	// ParseWebhook memverifikasi signature lalu membaca event.
+	ParseWebhook(header http.Header, body []byte) (WebhookEvent, error)
+}
+
+// Sign membuat nilai SignatureHeader untuk body pada waktu t.
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + computeHMAC(secret, ts, body)
+}
+
+// VerifySignature mengecek nilai SignatureHeader dengan perbandingan
+// constant-time dan menolak timestamp di luar webhookTolerance.
+func VerifySignature(secret, header string, body []byte, now time.Time) error {
+	if secret == "" {
+		return ErrInvalidSignature
+	}
+
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			sig = value
+		}
+	}
+	if ts == "" || sig == "" {
+		return ErrInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if diff := now.Sub(time.Unix(unix, 0)); diff > webhookTolerance || diff < -webhookTolerance {
+		return ErrInvalidSignature
+	}
+
+	expected := computeHMAC(secret, ts, body)
+	if !hmac.Equal([]byte(expected), []byte(sig)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func computeHMAC(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
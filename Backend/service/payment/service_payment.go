@@ -0,0 +1,441 @@
+package payment
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	adminRepo "astro-backend/repository/admin"
+	bookingRepo "astro-backend/repository/booking"
+	paymentRepo "astro-backend/repository/payment"
+	"astro-backend/service/booking"
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrPaymentNotFound  = errors.New("Pembayaran tidak ditemukan")
+	ErrUnknownProvider  = errors.New("Payment provider tidak dikenal")
+	ErrNothingToPay     = errors.New("Tidak ada tagihan yang perlu dibayar")
+	ErrInvalidOperation = errors.New("Operasi tidak bisa dilakukan pada status pembayaran ini")
+)
+
+// ChargeInput adalah permintaan pembayaran dari client.
+type ChargeInput struct {
+	PayableType string `json:"payable_type"` // booking / room_charge
+	PayableID   string `json:"payable_id"`
+	// Amount opsional untuk booking (default sisa tagihan), wajib untuk room_charge.
+	Amount       float64 `json:"amount"`
+	Description  string  `json:"description"`
+	PaymentToken string  `json:"payment_token"`
+	// Capture default true; false berarti hanya authorize.
+	Capture  *bool  `json:"capture"`
+	Provider string `json:"provider"`
+}
+
+type PaymentService interface {
+	Charge(actor booking.Actor, input ChargeInput) (models.Payment, error)
+	GetAll(actor booking.Actor, filter paymentRepo.PaymentFilter) ([]models.Payment, error)
+	GetByID(actor booking.Actor, id string) (models.Payment, error)
+	Capture(id string, amount float64) (models.Payment, error)
+	Refund(id string, amount float64, reason string) (models.Payment, error)
+	// Refresh menanyakan status terbaru ke provider (untuk pembayaran tertunda).
+	Refresh(actor booking.Actor, id string) (models.Payment, error)
+	HandleWebhook(provider string, header http.Header, body []byte) error
+}
+
+type paymentService struct {
+	repo        paymentRepo.PaymentRepository
+	bookingRepo bookingRepo.BookingRepository
+	roomRepo    adminRepo.RoomRepository
+	providers   map[string]Provider
+}
+
+// NewPaymentService mendaftarkan provider berdasarkan Name(). Provider
+// default dipilih dari PAYMENT_PROVIDER (default "fake").
+func NewPaymentService(repo paymentRepo.PaymentRepository, bookingRepository bookingRepo.BookingRepository, roomRepo adminRepo.RoomRepository, providers ...Provider) PaymentService {
+	registry := map[string]Provider{}
+	for _, p := range providers {
+		registry[p.Name()] = p
+	}
+	return &paymentService{repo, bookingRepository, roomRepo, registry}
+}
+
+func (s *paymentService) provider(name string) (Provider, error) {
+	if name == "" {
+		name = os.Getenv("PAYMENT_PROVIDER")
+	}
+	if name == "" {
+		name = "fake"
+	}
+	p, ok := s.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return p, nil
+}
+
+func (s *paymentService) Charge(actor booking.Actor, input ChargeInput) (models.Payment, error) {
+	provider, err := s.provider(input.Provider)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	payment, err := s.resolvePayable(actor, input)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	payment.Id = primitive.NewObjectID()
+	payment.Provider = provider.Name()
+	payment.Currency = os.Getenv("CURRENCY")
+	if payment.Currency == "" {
+		payment.Currency = "IDR"
+	}
+	payment.Status = constants.PaymentStatusPending
+	payment.Events = []models.PaymentEvent{{Type: "created", Status: constants.PaymentStatusPending, Amount: payment.Amount, CreatedAt: now}}
+	payment.CreatedAt = now
+	payment.UpdatedAt = now
+
+	// record disimpan dulu supaya transaksi di provider selalu punya pasangan di database
+	if err := s.repo.Create(payment); err != nil {
+		return models.Payment{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	result, err := provider.Charge(ctx, ChargeRequest{
+		PaymentID:   payment.Id.Hex(),
+		Amount:      payment.Amount,
+		Currency:    payment.Currency,
+		Description: payment.Description,
+		Token:       input.PaymentToken,
+		Capture:     input.Capture == nil || *input.Capture,
+	})
+	if err != nil {
+		result = ProviderResult{Status: constants.PaymentStatusFailed, FailureReason: err.Error()}
+	}
+
+	payment.ProviderRef = result.Reference
+	if result.Status == constants.PaymentStatusPending {
+		// hasil menyusul lewat webhook atau Refresh; simpan referensinya dulu
+		return s.transition(payment, constants.PaymentStatusPending, 0, models.PaymentEvent{Type: "charge", Note: "menunggu konfirmasi provider"})
+	}
+	return s.sync(payment, result.Status, result.FailureReason, "charge", "")
+}
+
+// resolvePayable memeriksa tagihan dan menentukan jumlah serta pemilik pembayaran.
+func (s *paymentService) resolvePayable(actor booking.Actor, input ChargeInput) (models.Payment, error) {
+	payableID, err := primitive.ObjectIDFromHex(input.PayableID)
+	if err != nil {
+		return models.Payment{}, errors.New("payable_id tidak valid")
+	}
+	if input.Amount < 0 {
+		return models.Payment{}, errors.New("amount tidak boleh negatif")
+	}
+
+	payment := models.Payment{
+		PayableType: input.PayableType,
+		PayableID:   payableID,
+		Amount:      input.Amount,
+		Description: strings.TrimSpace(input.Description),
+	}
+
+	switch input.PayableType {
+	case constants.PayableBooking:
+		b, err := s.bookingRepo.GetByID(input.PayableID)
+		if err != nil || (!actor.Staff && b.UserID != actor.User.Id) {
+			return models.Payment{}, booking.ErrBookingNotFound
+		}
+		if b.Status != constants.BookingStatusPending && b.Status != constants.BookingStatusConfirmed {
+			return models.Payment{}, errors.New("Booking tidak aktif")
+		}
+
+		// pembayaran yang masih PENDING / AUTHORIZED ikut dihitung supaya
+		// booking tidak bisa ditagih dua kali selama hasilnya belum final
+		inFlight, err := s.inFlightAmount(payableID)
+		if err != nil {
+			return models.Payment{}, err
+		}
+		outstanding := math.Round((b.TotalPrice-b.AmountPaid-inFlight)*100) / 100
+		if outstanding <= 0 {
+			return models.Payment{}, ErrNothingToPay
+		}
+		if payment.Amount == 0 {
+			payment.Amount = outstanding
+		}
+		if payment.Amount > outstanding {
+			return models.Payment{}, errors.New("amount melebihi sisa tagihan booking")
+		}
+		payment.UserID = b.UserID
+		if payment.Description == "" {
+			payment.Description = "Booking " + b.Id.Hex()
+		}
+
+	case constants.PayableRoomCharge:
+		// tagihan tambahan hanya dibuat staff
+		if !actor.Staff {
+			return models.Payment{}, errors.New("Hanya staff yang bisa membuat tagihan kamar")
+		}
+		if _, err := s.roomRepo.GetByID(input.PayableID); err != nil {
+			return models.Payment{}, errors.New("Kamar tidak ditemukan")
+		}
+		if payment.Amount <= 0 {
+			return models.Payment{}, errors.New("amount wajib diisi untuk room_charge")
+		}
+		if payment.Description == "" {
+			return models.Payment{}, errors.New("description wajib diisi untuk room_charge")
+		}
+		payment.UserID = actor.User.Id
+
+	default:
+		return models.Payment{}, errors.New("payable_type harus booking atau room_charge")
+	}
+
+	return payment, nil
+}
+
+// inFlightAmount menjumlahkan pembayaran booking yang belum final.
+func (s *paymentService) inFlightAmount(bookingID primitive.ObjectID) (float64, error) {
+	payments, err := s.repo.GetAll(paymentRepo.PaymentFilter{PayableType: constants.PayableBooking, PayableID: &bookingID})
+	if err != nil {
+		return 0, err
+	}
+	var total float64
+	for _, p := range payments {
+		if p.Status == constants.PaymentStatusPending || p.Status == constants.PaymentStatusAuthorized {
+			total += p.Amount
+		}
+	}
+	return total, nil
+}
+
+func (s *paymentService) GetAll(actor booking.Actor, filter paymentRepo.PaymentFilter) ([]models.Payment, error) {
+	if !actor.Staff {
+		filter.UserID = &actor.User.Id
+	}
+	return s.repo.GetAll(filter)
+}
+
+func (s *paymentService) GetByID(actor booking.Actor, id string) (models.Payment, error) {
+	payment, err := s.repo.GetByID(id)
+	if err != nil || (!actor.Staff && payment.UserID != actor.User.Id) {
+		return models.Payment{}, ErrPaymentNotFound
+	}
+	return payment, nil
+}
+
+// Capture menagih dana yang sebelumnya hanya di-authorize. amount 0 berarti penuh.
+func (s *paymentService) Capture(id string, amount float64) (models.Payment, error) {
+	payment, err := s.repo.GetByID(id)
+	if err != nil {
+		return models.Payment{}, ErrPaymentNotFound
+	}
+	if payment.Status != constants.PaymentStatusAuthorized {
+		return models.Payment{}, ErrInvalidOperation
+	}
+	if amount == 0 {
+		amount = payment.Amount
+	}
+	if amount < 0 || amount > payment.Amount {
+		return models.Payment{}, errors.New("jumlah capture tidak valid")
+	}
+
+	provider, err := s.provider(payment.Provider)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if _, err := provider.Capture(ctx, payment.ProviderRef, amount); err != nil {
+		return models.Payment{}, err
+	}
+
+	from := payment.Status
+	payment.Status = constants.PaymentStatusCaptured
+	payment.CapturedAmount = amount
+	return s.transition(payment, from, amount, models.PaymentEvent{Type: "capture", Amount: amount})
+}
+
+// Refund mengembalikan sebagian atau seluruh dana yang sudah di-capture.
+// amount 0 berarti seluruh sisa dana.
+func (s *paymentService) Refund(id string, amount float64, reason string) (models.Payment, error) {
+	payment, err := s.repo.GetByID(id)
+	if err != nil {
+		return models.Payment{}, ErrPaymentNotFound
+	}
+	if payment.Status != constants.PaymentStatusCaptured && payment.Status != constants.PaymentStatusPartiallyRefunded {
+		return models.Payment{}, ErrInvalidOperation
+	}
+
+	remaining := math.Round((payment.CapturedAmount-payment.RefundedAmount)*100) / 100
+	if amount == 0 {
+		amount = remaining
+	}
+	if amount <= 0 || amount > remaining {
+		return models.Payment{}, errors.New("jumlah refund melebihi sisa dana")
+	}
+
+	provider, err := s.provider(payment.Provider)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	// nominal refund diklaim dulu di database supaya dua refund bersamaan
+	// tidak sama-sama sampai ke provider melebihi dana yang di-capture
+	claimed, err := s.repo.ReserveRefund(payment.Id, amount)
+	if err != nil {
+		if errors.Is(err, paymentRepo.ErrPaymentStateChanged) {
+			return models.Payment{}, ErrInvalidOperation
+		}
+		return models.Payment{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if _, err := provider.Refund(ctx, payment.ProviderRef, amount); err != nil {
+		if releaseErr := s.repo.ReleaseRefund(payment.Id, amount); releaseErr != nil {
+			log.Error().Err(releaseErr).Str("payment_id", payment.Id.Hex()).Float64("amount", amount).Msg("gagal membatalkan klaim refund")
+		}
+		return models.Payment{}, err
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	event := models.PaymentEvent{Type: "refund", Status: claimed.Status, Amount: amount, Note: strings.TrimSpace(reason), CreatedAt: now}
+	if err := s.repo.AddEvent(payment.Id, event); err != nil {
+		log.Error().Err(err).Str("payment_id", payment.Id.Hex()).Msg("gagal mencatat event refund")
+	}
+	claimed.Events = append(claimed.Events, event)
+
+	s.applyToPayable(claimed, -amount)
+	return claimed, nil
+}
+
+func (s *paymentService) Refresh(actor booking.Actor, id string) (models.Payment, error) {
+	payment, err := s.GetByID(actor, id)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	if payment.ProviderRef == "" {
+		return payment, nil
+	}
+
+	provider, err := s.provider(payment.Provider)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	result, err := provider.Status(ctx, payment.ProviderRef)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	return s.sync(payment, result.Status, result.FailureReason, "status", "")
+}
+
+// HandleWebhook memverifikasi dan memproses event dari provider. Event yang
+// sama (event ID) hanya diproses sekali.
+func (s *paymentService) HandleWebhook(providerName string, header http.Header, body []byte) error {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return ErrUnknownProvider
+	}
+
+	event, err := provider.ParseWebhook(header, body)
+	if err != nil {
+		return err
+	}
+
+	payment, err := s.repo.FindByProviderRef(provider.Name(), event.Reference)
+	if err != nil {
+		return ErrPaymentNotFound
+	}
+
+	var status string
+	switch event.Type {
+	case constants.PaymentEventAuthorized:
+		status = constants.PaymentStatusAuthorized
+	case constants.PaymentEventCaptured:
+		status = constants.PaymentStatusCaptured
+	case constants.PaymentEventFailed:
+		status = constants.PaymentStatusFailed
+	default:
+		// refund selalu dimulai dari API ini, jadi event refund cukup diabaikan
+		return nil
+	}
+
+	_, err = s.sync(payment, status, event.Reason, "webhook:"+event.Type, event.ID)
+	return err
+}
+
+// sync menerapkan status dari provider kalau transisinya masuk akal:
+// PENDING -> AUTHORIZED / CAPTURED / FAILED, AUTHORIZED -> CAPTURED / FAILED.
+func (s *paymentService) sync(payment models.Payment, status, reason, eventType, eventID string) (models.Payment, error) {
+	from := payment.Status
+	valid := false
+	switch status {
+	case constants.PaymentStatusAuthorized:
+		valid = from == constants.PaymentStatusPending
+	case constants.PaymentStatusCaptured, constants.PaymentStatusFailed:
+		valid = from == constants.PaymentStatusPending || from == constants.PaymentStatusAuthorized
+	}
+	if !valid {
+		return payment, nil
+	}
+
+	var paid float64
+	payment.Status = status
+	payment.FailureReason = reason
+	if status == constants.PaymentStatusCaptured {
+		paid = payment.Amount - payment.CapturedAmount
+		payment.CapturedAmount = payment.Amount
+	}
+
+	return s.transition(payment, from, paid, models.PaymentEvent{Type: eventType, EventID: eventID, Note: reason})
+}
+
+// transition menyimpan status baru lalu menerapkan perubahan dana ke
+// tagihan (amount_paid booking). Kalau status sudah diubah proses lain,
+// data terbaru dikembalikan tanpa error.
+func (s *paymentService) transition(payment models.Payment, from string, paidDelta float64, event models.PaymentEvent) (models.Payment, error) {
+	now := primitive.NewDateTimeFromTime(time.Now())
+	payment.UpdatedAt = now
+	event.Status = payment.Status
+	event.CreatedAt = now
+
+	if err := s.repo.Transition(payment, []string{from}, event); err != nil {
+		if errors.Is(err, paymentRepo.ErrPaymentStateChanged) {
+			return s.repo.GetByID(payment.Id.Hex())
+		}
+		return models.Payment{}, err
+	}
+	payment.Events = append(payment.Events, event)
+
+	s.applyToPayable(payment, paidDelta)
+	return payment, nil
+}
+
+// applyToPayable menerapkan perubahan dana ke amount_paid booking.
+func (s *paymentService) applyToPayable(payment models.Payment, paidDelta float64) {
+	if paidDelta == 0 || payment.PayableType != constants.PayableBooking {
+		return
+	}
+	// dana sudah berpindah di provider; kegagalan di sini harus dicek manual
+	if err := s.bookingRepo.AddPayment(payment.PayableID, paidDelta); err != nil {
+		log.Error().Err(err).Str("payment_id", payment.Id.Hex()).Float64("amount", paidDelta).Msg("gagal memperbarui amount_paid booking")
+	}
+}
@@ -0,0 +1,282 @@
+package payment
+
+import (
+	"astro-backend/constants"
+	"astro-backend/models"
+	bookingRepo "astro-backend/repository/booking"
+	paymentRepo "astro-backend/repository/payment"
+	"astro-backend/service/booking"
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"runtime"
+	"sync"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memPayments meniru paymentRepository di memori. ReserveRefund dan
+// ReleaseRefund atomik seperti update bersyarat di MongoDB.
+type memPayments struct {
+	paymentRepo.PaymentRepository
+	mu       sync.Mutex
+	payments map[primitive.ObjectID]models.Payment
+}
+
+func newMemPayments(payments ...models.Payment) *memPayments {
+	m := &memPayments{payments: map[primitive.ObjectID]models.Payment{}}
+	for _, p := range payments {
+		m.payments[p.Id] = p
+	}
+	return m
+}
+
+func (m *memPayments) GetByID(id string) (models.Payment, error) {
+	objID, _ := primitive.ObjectIDFromHex(id)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.payments[objID]
+	if !ok {
+		return p, errors.New("Pembayaran tidak ditemukan")
+	}
+	return p, nil
+}
+
+func (m *memPayments) GetAll(filter paymentRepo.PaymentFilter) ([]models.Payment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []models.Payment
+	for _, p := range m.payments {
+		if filter.PayableID != nil && p.PayableID != *filter.PayableID {
+			continue
+		}
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+func memRefundStatus(p models.Payment) string {
+	switch {
+	case p.RefundedAmount <= 0.005:
+		return constants.PaymentStatusCaptured
+	case p.RefundedAmount >= p.CapturedAmount-0.005:
+		return constants.PaymentStatusRefunded
+	}
+	return constants.PaymentStatusPartiallyRefunded
+}
+
+func (m *memPayments) ReserveRefund(id primitive.ObjectID, amount float64) (models.Payment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p := m.payments[id]
+	if (p.Status != constants.PaymentStatusCaptured && p.Status != constants.PaymentStatusPartiallyRefunded) ||
+		p.RefundedAmount+amount > p.CapturedAmount+0.005 {
+		return models.Payment{}, paymentRepo.ErrPaymentStateChanged
+	}
+	p.RefundedAmount += amount
+	p.Status = memRefundStatus(p)
+	m.payments[id] = p
+	return p, nil
+}
+
+func (m *memPayments) ReleaseRefund(id primitive.ObjectID, amount float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p := m.payments[id]
+	p.RefundedAmount -= amount
+	p.Status = memRefundStatus(p)
+	m.payments[id] = p
+	return nil
+}
+
+func (m *memPayments) AddEvent(id primitive.ObjectID, event models.PaymentEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p := m.payments[id]
+	p.Events = append(p.Events, event)
+	m.payments[id] = p
+	return nil
+}
+
+// stubProvider mencatat total refund yang sampai ke provider.
+type stubProvider struct {
+	Provider
+	mu       sync.Mutex
+	refunded float64
+	calls    int
+	fail     bool
+}
+
+func (*stubProvider) Name() string { return "stub" }
+
+func (p *stubProvider) Refund(_ context.Context, ref string, amount float64) (ProviderResult, error) {
+	runtime.Gosched()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.fail {
+		return ProviderResult{}, errors.New("provider menolak refund")
+	}
+	p.refunded += amount
+	return ProviderResult{Reference: ref, Status: constants.PaymentStatusPartiallyRefunded}, nil
+}
+
+func (*stubProvider) ParseWebhook(http.Header, []byte) (WebhookEvent, error) {
+	return WebhookEvent{}, ErrInvalidSignature
+}
+
+type stubBookings struct {
+	bookingRepo.BookingRepository
+	mu      sync.Mutex
+	booking models.Booking
+}
+
+func (b *stubBookings) GetByID(string) (models.Booking, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.booking, nil
+}
+
+func (b *stubBookings) AddPayment(_ primitive.ObjectID, amount float64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.booking.AmountPaid += amount
+	return nil
+}
+
+func capturedPayment(amount float64, bookingID primitive.ObjectID) models.Payment {
+	return models.Payment{
+		Id:             primitive.NewObjectID(),
+		PayableType:    constants.PayableBooking,
+		PayableID:      bookingID,
+		Amount:         amount,
+		Provider:       "stub",
+		ProviderRef:    "ref",
+		Status:         constants.PaymentStatusCaptured,
+		CapturedAmount: amount,
+	}
+}
+
+func TestRefundConcurrentPartial(t *testing.T) {
+	const workers = 20
+	bookingID := primitive.NewObjectID()
+	payment := capturedPayment(1000, bookingID)
+	repo := newMemPayments(payment)
+	provider := &stubProvider{}
+	bookings := &stubBookings{booking: models.Booking{Id: bookingID, TotalPrice: 1000, AmountPaid: 1000}}
+	svc := NewPaymentService(repo, bookings, nil, provider)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	start := make(chan struct{})
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, err := svc.Refund(payment.Id.Hex(), 300, "")
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			} else if !errors.Is(err, ErrInvalidOperation) && err.Error() != "jumlah refund melebihi sisa dana" {
+				t.Errorf("error tidak terduga: %v", err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if succeeded != 3 {
+		t.Fatalf("refund berhasil = %d, mau 3", succeeded)
+	}
+	if provider.calls != 3 || provider.refunded != 900 {
+		t.Fatalf("provider dipanggil %d kali dengan total %v, mau 3 kali total 900", provider.calls, provider.refunded)
+	}
+
+	stored, _ := repo.GetByID(payment.Id.Hex())
+	if stored.RefundedAmount != 900 || stored.Status != constants.PaymentStatusPartiallyRefunded {
+		t.Fatalf("tersimpan refunded=%v status=%s", stored.RefundedAmount, stored.Status)
+	}
+	if len(stored.Events) != 3 {
+		t.Fatalf("event refund = %d, mau 3", len(stored.Events))
+	}
+	if bookings.booking.AmountPaid != 100 {
+		t.Fatalf("amount_paid booking = %v, mau 100", bookings.booking.AmountPaid)
+	}
+}
+
+func TestRefundProviderFailureReleasesClaim(t *testing.T) {
+	payment := capturedPayment(500, primitive.NewObjectID())
+	repo := newMemPayments(payment)
+	svc := NewPaymentService(repo, &stubBookings{}, nil, &stubProvider{fail: true})
+
+	if _, err := svc.Refund(payment.Id.Hex(), 0, ""); err == nil {
+		t.Fatal("refund harus gagal kalau provider menolak")
+	}
+
+	stored, _ := repo.GetByID(payment.Id.Hex())
+	if stored.RefundedAmount != 0 || stored.Status != constants.PaymentStatusCaptured {
+		t.Fatalf("klaim tidak dibatalkan: refunded=%v status=%s", stored.RefundedAmount, stored.Status)
+	}
+}
+
+func TestRefundFullMarksRefunded(t *testing.T) {
+	payment := capturedPayment(500, primitive.NewObjectID())
+	repo := newMemPayments(payment)
+	svc := NewPaymentService(repo, &stubBookings{}, nil, &stubProvider{})
+
+	result, err := svc.Refund(payment.Id.Hex(), 0, "batal")
+	if err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	if result.Status != constants.PaymentStatusRefunded || result.RefundedAmount != 500 {
+		t.Fatalf("hasil status=%s refunded=%v", result.Status, result.RefundedAmount)
+	}
+	if _, err := svc.Refund(payment.Id.Hex(), 1, ""); !errors.Is(err, ErrInvalidOperation) {
+		t.Fatalf("refund kedua: err = %v, mau ErrInvalidOperation", err)
+	}
+}
+
+func TestResolvePayableSubtractsInFlight(t *testing.T) {
+	bookingID := primitive.NewObjectID()
+	user := models.User{Id: primitive.NewObjectID()}
+	b := models.Booking{Id: bookingID, UserID: user.Id, Status: constants.BookingStatusPending, TotalPrice: 1000, AmountPaid: 200}
+
+	pending := capturedPayment(300, bookingID)
+	pending.Status = constants.PaymentStatusPending
+	authorized := capturedPayment(100, bookingID)
+	authorized.Status = constants.PaymentStatusAuthorized
+	failed := capturedPayment(400, bookingID)
+	failed.Status = constants.PaymentStatusFailed
+	other := capturedPayment(250, primitive.NewObjectID())
+	other.Status = constants.PaymentStatusPending
+
+	svc := &paymentService{
+		repo:        newMemPayments(pending, authorized, failed, other),
+		bookingRepo: &stubBookings{booking: b},
+	}
+	actor := booking.Actor{User: user}
+
+	got, err := svc.resolvePayable(actor, ChargeInput{PayableType: constants.PayableBooking, PayableID: bookingID.Hex()})
+	if err != nil {
+		t.Fatalf("resolvePayable: %v", err)
+	}
+	if math.Abs(got.Amount-400) > 0.001 {
+		t.Fatalf("amount = %v, mau 400 (1000 - 200 dibayar - 400 tertunda)", got.Amount)
+	}
+
+	if _, err := svc.resolvePayable(actor, ChargeInput{PayableType: constants.PayableBooking, PayableID: bookingID.Hex(), Amount: 401}); err == nil {
+		t.Fatal("amount di atas sisa tagihan harus ditolak")
+	}
+
+	// sisa tagihan sudah habis tertahan pembayaran yang belum final
+	pending.Amount = 800
+	svc.repo = newMemPayments(pending)
+	if _, err := svc.resolvePayable(actor, ChargeInput{PayableType: constants.PayableBooking, PayableID: bookingID.Hex()}); !errors.Is(err, ErrNothingToPay) {
+		t.Fatalf("err = %v, mau ErrNothingToPay", err)
+	}
+}
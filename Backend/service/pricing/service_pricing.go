@@ -0,0 +1,191 @@
+package pricing
+
+import (
+	"astro-backend/models"
+	adminRepo "astro-backend/repository/admin"
+	"astro-backend/utils"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrRoomNotFound = errors.New("Kamar tidak ditemukan")
+
+// StayPrice adalah rincian harga menginap per malam.
+type StayPrice struct {
+	RoomID   string               `json:"room_id"`
+	CheckIn  string               `json:"check_in"`
+	CheckOut string               `json:"check_out"`
+	Nights   []models.NightlyRate `json:"nights"`
+	Total    float64              `json:"total"`
+}
+
+// AverageRate dipakai untuk mengisi Booking.PricePerNight.
+func (p StayPrice) AverageRate() float64 {
+	if len(p.Nights) == 0 {
+		return 0
+	}
+	return p.Total / float64(len(p.Nights))
+}
+
+type PricingService interface {
+	PriceStay(room models.Room, checkIn, checkOut time.Time) (StayPrice, error)
+	PriceRoom(roomID, checkIn, checkOut string) (StayPrice, error)
+}
+
+type pricingService struct {
+	planRepo adminRepo.RatePlanRepository
+	roomRepo adminRepo.RoomRepository
+}
+
+func NewPricingService(planRepo adminRepo.RatePlanRepository, roomRepo adminRepo.RoomRepository) PricingService {
+	return &pricingService{planRepo, roomRepo}
+}
+
+// PriceRoom menghitung harga kamar untuk rentang tanggal dari input client.
+func (s *pricingService) PriceRoom(roomID, checkInStr, checkOutStr string) (StayPrice, error) {
+	checkIn, err := utils.ParseDate(checkInStr)
+	if err != nil {
+		return StayPrice{}, errors.New("check_in: " + err.Error())
+	}
+	checkOut, err := utils.ParseDate(checkOutStr)
+	if err != nil {
+		return StayPrice{}, errors.New("check_out: " + err.Error())
+	}
+	if !checkOut.After(checkIn) {
+		return StayPrice{}, errors.New("Tanggal check-out harus setelah check-in")
+	}
+	if utils.Nights(checkIn, checkOut) > 366 {
+		return StayPrice{}, errors.New("Rentang tanggal maksimal 366 malam")
+	}
+
+	room, err := s.roomRepo.GetByID(roomID)
+	if err != nil {
+		return StayPrice{}, ErrRoomNotFound
+	}
+
+	return s.PriceStay(room, checkIn, checkOut)
+}
+
+// PriceStay menentukan harga setiap malam dari checkIn sampai sebelum
+// checkOut. Plan dicoba berurutan: plan khusus kamar sebelum plan tipe
+// kamar, lalu Priority terbesar, lalu yang paling baru diubah. Plan pertama
+// yang punya harga untuk malam itu dipakai; kalau tidak ada, dipakai
+// Room.PricePerNight.
+func (s *pricingService) PriceStay(room models.Room, checkIn, checkOut time.Time) (StayPrice, error) {
+	plans, err := s.planRepo.FindActiveForRoom(room.Id, room.RoomTypeID)
+	if err != nil {
+		return StayPrice{}, err
+	}
+	sortPlans(plans)
+
+	price := StayPrice{
+		RoomID:   room.Id.Hex(),
+		CheckIn:  checkIn.Format(utils.DateLayout),
+		CheckOut: checkOut.Format(utils.DateLayout),
+		Nights:   []models.NightlyRate{},
+	}
+
+	for d := checkIn; d.Before(checkOut); d = d.AddDate(0, 0, 1) {
+		night := models.NightlyRate{
+			Date:   d.Format(utils.DateLayout),
+			Rate:   room.PricePerNight,
+			Source: "room",
+		}
+		for _, plan := range plans {
+			if rate, source, ok := planRate(plan, d); ok {
+				night.Rate = rate
+				night.Source = source
+				break
+			}
+		}
+
+		price.Nights = append(price.Nights, night)
+		price.Total += night.Rate
+	}
+
+	return price, nil
+}
+
+func sortPlans(plans []models.RatePlan) {
+	sort.SliceStable(plans, func(i, j int) bool {
+		a, b := plans[i], plans[j]
+		if (a.RoomID != nil) != (b.RoomID != nil) {
+			return a.RoomID != nil
+		}
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		return a.UpdatedAt > b.UpdatedAt
+	})
+}
+
+// planRate mencari harga plan untuk satu tanggal: season > DayRates > BaseRate.
+func planRate(plan models.RatePlan, date time.Time) (float64, string, bool) {
+	if season, ok := activeSeason(plan.Seasons, date); ok {
+		if rate, key, ok := dayRate(season.DayRates, date); ok {
+			return rate, plan.Name + " / season: " + season.Name + " (" + key + ")", true
+		}
+		if season.Rate > 0 {
+			return season.Rate, plan.Name + " / season: " + season.Name, true
+		}
+	}
+
+	if rate, key, ok := dayRate(plan.DayRates, date); ok {
+		return rate, plan.Name + " (" + key + ")", true
+	}
+
+	if plan.BaseRate > 0 {
+		return plan.BaseRate, plan.Name, true
+	}
+
+	return 0, "", false
+}
+
+// dayRate mencari harga per hari: nama hari dulu, lalu "weekday"/"weekend".
+func dayRate(rates map[string]float64, date time.Time) (float64, string, bool) {
+	day := strings.ToLower(date.Weekday().String())
+	if rate := rates[day]; rate > 0 {
+		return rate, day, true
+	}
+
+	group := "weekday"
+	if utils.IsWeekendNight(date) {
+		group = "weekend"
+	}
+	if rate := rates[group]; rate > 0 {
+		return rate, group, true
+	}
+
+	return 0, "", false
+}
+
+// activeSeason memilih season yang mencakup tanggal; kalau tumpang tindih
+// dipakai Priority terbesar, lalu season yang mulai paling akhir.
+func activeSeason(seasons []models.SeasonRate, date time.Time) (models.SeasonRate, bool) {
+	var best models.SeasonRate
+	found := false
+
+	for _, season := range seasons {
+		start, err := utils.ParseDate(season.StartDate)
+		if err != nil {
+			continue
+		}
+		end, err := utils.ParseDate(season.EndDate)
+		if err != nil {
+			continue
+		}
+		if date.Before(start) || date.After(end) {
+			continue
+		}
+
+		if !found || season.Priority > best.Priority ||
+			(season.Priority == best.Priority && season.StartDate > best.StartDate) {
+			best = season
+			found = true
+		}
+	}
+
+	return best, found
+}
@@ -0,0 +1,227 @@
+package pricing
+
+import (
+	"astro-backend/models"
+	adminRepo "astro-backend/repository/admin"
+	"astro-backend/utils"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type stubPlanRepo struct {
+	adminRepo.RatePlanRepository
+	plans []models.RatePlan
+}
+
+func (r stubPlanRepo) FindActiveForRoom(roomID, roomTypeID primitive.ObjectID) ([]models.RatePlan, error) {
+	return append([]models.RatePlan(nil), r.plans...), nil
+}
+
+func date(s string) time.Time {
+	d, err := utils.ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func updatedAt(s string) primitive.DateTime {
+	return primitive.NewDateTimeFromTime(date(s))
+}
+
+// 2030-01-04 Jumat, 01-05 Sabtu, 01-06 Minggu, 01-07 Senin.
+func TestPriceStay(t *testing.T) {
+	t.Setenv("HOTEL_WEEKEND_DAYS", "friday,saturday")
+
+	roomID := primitive.NewObjectID()
+	typeID := primitive.NewObjectID()
+	room := models.Room{Id: roomID, RoomTypeID: typeID, PricePerNight: 100}
+
+	roomPlan := func(name string, priority int, base float64) models.RatePlan {
+		return models.RatePlan{Name: name, RoomID: &roomID, Priority: priority, BaseRate: base}
+	}
+	typePlan := func(name string, priority int, base float64) models.RatePlan {
+		return models.RatePlan{Name: name, RoomTypeID: &typeID, Priority: priority, BaseRate: base}
+	}
+
+	tests := []struct {
+		name       string
+		plans      []models.RatePlan
+		checkIn    string
+		checkOut   string
+		wantRates  []float64
+		wantSource []string
+	}{
+		{
+			name:       "tanpa plan memakai harga kamar",
+			checkIn:    "2030-01-07",
+			checkOut:   "2030-01-09",
+			wantRates:  []float64{100, 100},
+			wantSource: []string{"room", "room"},
+		},
+		{
+			name:       "plan kamar menang atas plan tipe walau priority lebih kecil",
+			plans:      []models.RatePlan{typePlan("Tipe", 10, 300), roomPlan("Kamar", 0, 200)},
+			checkIn:    "2030-01-07",
+			checkOut:   "2030-01-08",
+			wantRates:  []float64{200},
+			wantSource: []string{"Kamar"},
+		},
+		{
+			name:       "priority lebih besar menang di level yang sama",
+			plans:      []models.RatePlan{typePlan("Rendah", 1, 150), typePlan("Tinggi", 5, 250)},
+			checkIn:    "2030-01-07",
+			checkOut:   "2030-01-08",
+			wantRates:  []float64{250},
+			wantSource: []string{"Tinggi"},
+		},
+		{
+			name: "priority sama: yang paling baru diubah menang",
+			plans: func() []models.RatePlan {
+				old, baru := typePlan("Lama", 1, 150), typePlan("Baru", 1, 175)
+				old.UpdatedAt, baru.UpdatedAt = updatedAt("2029-01-01"), updatedAt("2029-06-01")
+				return []models.RatePlan{old, baru}
+			}(),
+			checkIn:    "2030-01-07",
+			checkOut:   "2030-01-08",
+			wantRates:  []float64{175},
+			wantSource: []string{"Baru"},
+		},
+		{
+			name: "plan tanpa harga untuk malam itu dilewati",
+			plans: []models.RatePlan{
+				{Name: "Weekend", RoomID: &roomID, DayRates: map[string]float64{"weekend": 400}},
+				typePlan("Standar", 0, 120),
+			},
+			checkIn:    "2030-01-04",
+			checkOut:   "2030-01-08",
+			wantRates:  []float64{400, 400, 120, 120},
+			wantSource: []string{"Weekend (weekend)", "Weekend (weekend)", "Standar", "Standar"},
+		},
+		{
+			name: "nama hari menang atas weekday/weekend",
+			plans: []models.RatePlan{{
+				Name:     "Harian",
+				RoomID:   &roomID,
+				BaseRate: 110,
+				DayRates: map[string]float64{"saturday": 500, "weekend": 300, "weekday": 130},
+			}},
+			checkIn:    "2030-01-04",
+			checkOut:   "2030-01-07",
+			wantRates:  []float64{300, 500, 130},
+			wantSource: []string{"Harian (weekend)", "Harian (saturday)", "Harian (weekday)"},
+		},
+		{
+			name: "season menang atas harga hari dan base rate",
+			plans: []models.RatePlan{{
+				Name:     "Standar",
+				RoomID:   &roomID,
+				BaseRate: 110,
+				DayRates: map[string]float64{"weekend": 300},
+				Seasons: []models.SeasonRate{
+					{Name: "Tahun Baru", StartDate: "2030-01-05", EndDate: "2030-01-06", Rate: 900},
+				},
+			}},
+			checkIn:    "2030-01-04",
+			checkOut:   "2030-01-08",
+			wantRates:  []float64{300, 900, 900, 110},
+			wantSource: []string{"Standar (weekend)", "Standar / season: Tahun Baru", "Standar / season: Tahun Baru", "Standar"},
+		},
+		{
+			name: "harga hari di dalam season menang atas rate season",
+			plans: []models.RatePlan{{
+				Name:   "Standar",
+				RoomID: &roomID,
+				Seasons: []models.SeasonRate{{
+					Name: "Libur", StartDate: "2030-01-04", EndDate: "2030-01-07", Rate: 700,
+					DayRates: map[string]float64{"sunday": 650},
+				}},
+			}},
+			checkIn:    "2030-01-05",
+			checkOut:   "2030-01-07",
+			wantRates:  []float64{700, 650},
+			wantSource: []string{"Standar / season: Libur", "Standar / season: Libur (sunday)"},
+		},
+		{
+			name: "season tumpang tindih: priority lalu yang mulai paling akhir",
+			plans: []models.RatePlan{{
+				Name:     "Standar",
+				RoomID:   &roomID,
+				BaseRate: 110,
+				Seasons: []models.SeasonRate{
+					{Name: "Januari", StartDate: "2030-01-01", EndDate: "2030-01-31", Rate: 200},
+					{Name: "Minggu Kedua", StartDate: "2030-01-06", EndDate: "2030-01-12", Rate: 250},
+					{Name: "Event", StartDate: "2030-01-07", EndDate: "2030-01-07", Rate: 800, Priority: 1},
+				},
+			}},
+			checkIn:    "2030-01-05",
+			checkOut:   "2030-01-08",
+			wantRates:  []float64{200, 250, 800},
+			wantSource: []string{"Standar / season: Januari", "Standar / season: Minggu Kedua", "Standar / season: Event"},
+		},
+		{
+			name: "batas season inklusif di kedua ujung",
+			plans: []models.RatePlan{{
+				Name:     "Standar",
+				RoomID:   &roomID,
+				BaseRate: 110,
+				Seasons:  []models.SeasonRate{{Name: "Pendek", StartDate: "2030-01-05", EndDate: "2030-01-06", Rate: 900}},
+			}},
+			checkIn:    "2030-01-04",
+			checkOut:   "2030-01-08",
+			wantRates:  []float64{110, 900, 900, 110},
+			wantSource: []string{"Standar", "Standar / season: Pendek", "Standar / season: Pendek", "Standar"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewPricingService(stubPlanRepo{plans: tt.plans}, nil)
+			price, err := svc.PriceStay(room, date(tt.checkIn), date(tt.checkOut))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(price.Nights) != len(tt.wantRates) {
+				t.Fatalf("%d malam, want %d", len(price.Nights), len(tt.wantRates))
+			}
+
+			total := 0.0
+			for i, night := range price.Nights {
+				if night.Rate != tt.wantRates[i] || night.Source != tt.wantSource[i] {
+					t.Errorf("malam %s: %v (%s), want %v (%s)", night.Date, night.Rate, night.Source, tt.wantRates[i], tt.wantSource[i])
+				}
+				total += tt.wantRates[i]
+			}
+			if price.Total != total {
+				t.Errorf("total %v, want %v", price.Total, total)
+			}
+		})
+	}
+}
+
+func TestActiveSeasonSkipsInvalidDates(t *testing.T) {
+	seasons := []models.SeasonRate{
+		{Name: "Rusak", StartDate: "05-01-2030", EndDate: "2030-01-10", Rate: 999, Priority: 9},
+		{Name: "Valid", StartDate: "2030-01-01", EndDate: "2030-01-10", Rate: 200},
+	}
+
+	season, ok := activeSeason(seasons, date("2030-01-05"))
+	if !ok || season.Name != "Valid" {
+		t.Errorf("season %q (%v), want Valid", season.Name, ok)
+	}
+	if _, ok := activeSeason(seasons, date("2030-01-11")); ok {
+		t.Error("tanggal di luar season seharusnya tidak cocok")
+	}
+}
+
+func TestStayPriceAverageRate(t *testing.T) {
+	if got := (StayPrice{}).AverageRate(); got != 0 {
+		t.Errorf("tanpa malam: %v, want 0", got)
+	}
+	price := StayPrice{Nights: make([]models.NightlyRate, 3), Total: 450}
+	if got := price.AverageRate(); got != 150 {
+		t.Errorf("rata-rata %v, want 150", got)
+	}
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+// DateLayout adalah format tanggal menginap (check-in / check-out).
+const DateLayout = "2006-01-02"
+
+// ParseDate mengubah "YYYY-MM-DD" menjadi tengah malam UTC. Semua tanggal
+// menginap disimpan sebagai tengah malam UTC supaya mudah dibandingkan.
+func ParseDate(s string) (time.Time, error) {
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return time.Time{}, errors.New("format tanggal harus YYYY-MM-DD")
+	}
+	return t.UTC(), nil
+}
+
+// Today mengembalikan tanggal hari ini menurut zona waktu hotel
+// (HOTEL_TIMEZONE, default Asia/Jakarta) sebagai tengah malam UTC.
+func Today() time.Time {
+	return HotelDate(time.Now())
+}
+
+// HotelDate mengembalikan tanggal t menurut zona waktu hotel sebagai
+// tengah malam UTC, sehingga bisa dibandingkan dengan tanggal menginap.
+func HotelDate(t time.Time) time.Time {
+	loc, err := time.LoadLocation(os.Getenv("HOTEL_TIMEZONE"))
+	if err != nil || os.Getenv("HOTEL_TIMEZONE") == "" {
+		loc, err = time.LoadLocation("Asia/Jakarta")
+		if err != nil {
+			loc = time.FixedZone("WIB", 7*60*60)
+		}
+	}
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Nights menghitung jumlah malam antara check-in dan check-out.
+func Nights(checkIn, checkOut time.Time) int {
+	return int(checkOut.Sub(checkIn).Hours() / 24)
+}
+
+// IsWeekendNight menentukan apakah malam pada tanggal t dihitung weekend.
+// Hari weekend diatur lewat HOTEL_WEEKEND_DAYS (default "friday,saturday",
+// karena malam Jumat dan Sabtu yang biasanya ramai).
+func IsWeekendNight(t time.Time) bool {
+	days := os.Getenv("HOTEL_WEEKEND_DAYS")
+	if days == "" {
+		days = "friday,saturday"
+	}
+	day := strings.ToLower(t.Weekday().String())
+	for _, d := range strings.Split(days, ",") {
+		if strings.TrimSpace(strings.ToLower(d)) == day {
+			return true
+		}
+	}
+	return false
+}
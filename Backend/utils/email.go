@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+)
+
+var ErrInvalidEmail = errors.New("Format email tidak valid")
+
+// NormalizeEmail merapikan email (trim + huruf kecil) lalu memastikan isinya
+// hanya alamat email. mail.ParseAddress juga menerima bentuk
+// "Nama <a@b.c>", jadi hasil parse harus sama persis dengan inputnya.
+func NormalizeEmail(raw string) (string, error) {
+	email := strings.ToLower(strings.TrimSpace(raw))
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", ErrInvalidEmail
+	}
+	return email, nil
+}
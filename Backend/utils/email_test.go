@@ -0,0 +1,26 @@
+package utils
+
+import "testing"
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"tamu@example.com", "tamu@example.com", false},
+		{"  Tamu@Example.COM ", "tamu@example.com", false},
+		{"Tamu <tamu@example.com>", "", true},
+		{"<tamu@example.com>", "", true},
+		{"tamu@example.com (kantor)", "", true},
+		{`"tamu"@example.com`, "", true},
+		{"tamu", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeEmail(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NormalizeEmail(%q) = %q, %v; mau %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// ListQuery adalah parameter daftar yang dipakai bersama semua endpoint list:
+// page/limit atau cursor, plus field dan arah urutan. SortBy sudah berupa
+// nama field bson yang lolos whitelist.
+type ListQuery struct {
+	Page      int
+	Limit     int
+	Cursor    string // next_cursor dari halaman sebelumnya; kalau diisi Page diabaikan
+	SortBy    string
+	SortOrder int // 1 = asc, -1 = desc
+}
+
+// ListPage adalah envelope hasil list, sama dengan ActivityLogHandler.List.
+// CurrentPage kosong kalau halaman diambil lewat cursor. NextCursor hanya
+// diisi kalau masih ada data berikutnya.
+type ListPage[T any] struct {
+	Data        []T    `json:"data"`
+	Total       int64  `json:"total"`
+	CurrentPage int    `json:"current_page,omitempty"`
+	PerPage     int    `json:"per_page"`
+	TotalPages  int64  `json:"total_pages"`
+	NextCursor  string `json:"next_cursor,omitempty"`
+}
+
+// Skip mengembalikan jumlah dokumen yang dilewati untuk mode page.
+func (q ListQuery) Skip() int64 {
+	if q.Cursor != "" || q.Page < 1 {
+		return 0
+	}
+	return int64(q.Page-1) * int64(q.Limit)
+}
+
+// NewListPage menyusun envelope dari data satu halaman dan total dokumen.
+func NewListPage[T any](q ListQuery, data []T, total int64, nextCursor string) ListPage[T] {
+	if data == nil {
+		data = []T{}
+	}
+	page := ListPage[T]{
+		Data:       data,
+		Total:      total,
+		PerPage:    q.Limit,
+		NextCursor: nextCursor,
+	}
+	if q.Limit > 0 {
+		page.TotalPages = (total + int64(q.Limit) - 1) / int64(q.Limit)
+	}
+	if q.Cursor == "" {
+		page.CurrentPage = q.Page
+	}
+	return page
+}
+
+// ParseListQuery membaca page, limit, cursor, sort_by dan sort_order dari
+// query string. sortable memetakan nama sort_by publik ke field bson; tanpa
+// sort_by data diurutkan dari yang terbaru (_id menurun).
+func ParseListQuery(values url.Values, sortable map[string]string) (ListQuery, error) {
+	q := ListQuery{
+		Page:      1,
+		Limit:     DefaultListLimit,
+		Cursor:    strings.TrimSpace(values.Get("cursor")),
+		SortBy:    "_id",
+		SortOrder: -1,
+	}
+
+	if v := values.Get("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return ListQuery{}, errors.New("page tidak valid")
+		}
+		q.Page = page
+	}
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return ListQuery{}, errors.New("limit tidak valid")
+		}
+		q.Limit = min(limit, MaxListLimit)
+	}
+
+	if v := values.Get("sort_by"); v != "" {
+		field, ok := sortable[v]
+		if !ok {
+			names := make([]string, 0, len(sortable))
+			for name := range sortable {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return ListQuery{}, errors.New("sort_by tidak dikenal, pilihan: " + strings.Join(names, ", "))
+		}
+		q.SortBy = field
+		q.SortOrder = 1
+	}
+	switch values.Get("sort_order") {
+	case "":
+	case "asc":
+		q.SortOrder = 1
+	case "desc":
+		q.SortOrder = -1
+	default:
+		return ListQuery{}, errors.New("sort_order harus asc atau desc")
+	}
+
+	return q, nil
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParseListQuery(t *testing.T) {
+	sortable := map[string]string{"created_at": "CreatedAt", "name": "Name"}
+
+	tests := []struct {
+		query   string
+		want    ListQuery
+		wantErr bool
+	}{
+		{"", ListQuery{Page: 1, Limit: DefaultListLimit, SortBy: "_id", SortOrder: -1}, false},
+		{"page=3&limit=10", ListQuery{Page: 3, Limit: 10, SortBy: "_id", SortOrder: -1}, false},
+		{"limit=1000", ListQuery{Page: 1, Limit: MaxListLimit, SortBy: "_id", SortOrder: -1}, false},
+		{"sort_by=name", ListQuery{Page: 1, Limit: DefaultListLimit, SortBy: "Name", SortOrder: 1}, false},
+		{"sort_by=created_at&sort_order=desc", ListQuery{Page: 1, Limit: DefaultListLimit, SortBy: "CreatedAt", SortOrder: -1}, false},
+		{"cursor=%20abc%20", ListQuery{Page: 1, Limit: DefaultListLimit, Cursor: "abc", SortBy: "_id", SortOrder: -1}, false},
+		{"page=0", ListQuery{}, true},
+		{"page=x", ListQuery{}, true},
+		{"limit=0", ListQuery{}, true},
+		{"sort_by=Password", ListQuery{}, true},
+		{"sort_order=up", ListQuery{}, true},
+	}
+
+	for _, tt := range tests {
+		values, _ := url.ParseQuery(tt.query)
+		got, err := ParseListQuery(values, sortable)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseListQuery(%q) = %+v, %v; mau %+v, error %v", tt.query, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestListQuerySkipAndPage(t *testing.T) {
+	q := ListQuery{Page: 3, Limit: 10}
+	if got := q.Skip(); got != 20 {
+		t.Errorf("Skip = %d, mau 20", got)
+	}
+	page := NewListPage[int](q, nil, 21, "")
+	if page.TotalPages != 3 || page.CurrentPage != 3 || page.Data == nil {
+		t.Errorf("page %+v", page)
+	}
+
+	// mode cursor tidak melewati dokumen dan tidak punya nomor halaman
+	q.Cursor = "abc"
+	if got := q.Skip(); got != 0 {
+		t.Errorf("Skip dengan cursor = %d, mau 0", got)
+	}
+	if page := NewListPage(q, []int{1}, 21, "next"); page.CurrentPage != 0 || page.NextCursor != "next" {
+		t.Errorf("page cursor %+v", page)
+	}
+}
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Konfigurasi password policy dibaca dari env:
+//
+//	PASSWORD_MIN_LENGTH      panjang minimal (default 8)
+//	PASSWORD_REQUIRE_UPPER   wajib huruf besar (default true)
+//	PASSWORD_REQUIRE_LOWER   wajib huruf kecil (default true)
+//	PASSWORD_REQUIRE_DIGIT   wajib angka (default true)
+//	PASSWORD_REQUIRE_SYMBOL  wajib simbol (default false)
+//	BCRYPT_COST              cost bcrypt (default 14, sama dengan data lama)
+const (
+	defaultPasswordMinLength = 8
+	defaultBcryptCost        = 14
+
+	// bcrypt menolak password lebih dari 72 byte
+	maxPasswordLength = 72
+)
+
+// ValidatePassword memastikan password memenuhi policy. email dan name
+// dipakai untuk menolak password yang sama dengan identitas user.
+func ValidatePassword(password, email, name string) error {
+	if strings.TrimSpace(password) == "" {
+		return errors.New("Password wajib diisi")
+	}
+
+	minLength := envInt("PASSWORD_MIN_LENGTH", defaultPasswordMinLength)
+	if len([]rune(password)) < minLength {
+		return fmt.Errorf("Password minimal %d karakter", minLength)
+	}
+	if len(password) > maxPasswordLength {
+		return fmt.Errorf("Password maksimal %d byte", maxPasswordLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	if envBool("PASSWORD_REQUIRE_UPPER", true) && !hasUpper {
+		return errors.New("Password harus mengandung huruf besar")
+	}
+	if envBool("PASSWORD_REQUIRE_LOWER", true) && !hasLower {
+		return errors.New("Password harus mengandung huruf kecil")
+	}
+	if envBool("PASSWORD_REQUIRE_DIGIT", true) && !hasDigit {
+		return errors.New("Password harus mengandung angka")
+	}
+	if envBool("PASSWORD_REQUIRE_SYMBOL", false) && !hasSymbol {
+		return errors.New("Password harus mengandung simbol")
+	}
+
+	lower := strings.ToLower(password)
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email != "" {
+		local, _, _ := strings.Cut(email, "@")
+		if lower == email || (len(local) >= 3 && strings.Contains(lower, local)) {
+			return errors.New("Password tidak boleh mengandung email")
+		}
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name != "" && (lower == name || lower == strings.ReplaceAll(name, " ", "")) {
+		return errors.New("Password tidak boleh sama dengan nama")
+	}
+
+	return nil
+}
+
+// HashPassword meng-hash password dengan bcrypt memakai cost dari BCRYPT_COST.
+func HashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost())
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// PasswordNeedsRehash bernilai true kalau hash bukan bcrypt atau cost-nya
+// berbeda dari konfigurasi saat ini. Dipanggil setelah login berhasil.
+func PasswordNeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return true
+	}
+	return cost != bcryptCost()
+}
+
+func bcryptCost() int {
+	cost := envInt("BCRYPT_COST", defaultBcryptCost)
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return defaultBcryptCost
+	}
+	return cost
+}
+
+func envInt(k string, def int) int {
+	if v := os.Getenv(k); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			return i
+		}
+	}
+	return def
+}
+
+func envBool(k string, def bool) bool {
+	if v := os.Getenv(k); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return def
+}
@@ -12,6 +12,7 @@ const MaxPayloadSize = 4096 // bytes
 var SensitiveKeys = []string{
 	"password", "passwd", "token", "access_token", "refresh_token",
 	"card_number", "card", "cvv", "credit_card", "ssn",
+	"secret", "recovery_code", "provisioning_uri", "api_key",
 }
 
 // SanitizeMap removes sensitive keys and truncates long strings.
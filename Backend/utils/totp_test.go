@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret adalah secret SHA1 dari RFC 6238 Appendix B
+// ("12345678901234567890") dalam base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// Vektor RFC 6238 memakai 8 digit; kode 6 digit adalah 6 digit terakhirnya.
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},          // 94287082
+	{1111111109, "081804"},  // 07081804
+	{1111111111, "050471"},  // 14050471
+	{1234567890, "005924"},  // 89005924
+	{2000000000, "279037"},  // 69279037
+	{20000000000, "353130"}, // 65353130
+}
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	for _, v := range rfc6238Vectors {
+		got, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(v.unix, 0)))
+		if err != nil {
+			t.Fatalf("t=%d: %v", v.unix, err)
+		}
+		if got != v.code {
+			t.Errorf("t=%d: kode %s, want %s", v.unix, got, v.code)
+		}
+	}
+}
+
+func TestTOTPCodeSecretFormat(t *testing.T) {
+	step := TOTPStep(time.Unix(59, 0))
+
+	// secret dari aplikasi lain kadang lowercase atau masih ber-padding
+	for _, secret := range []string{strings.ToLower(rfc6238Secret), rfc6238Secret + "===="} {
+		got, err := TOTPCode(secret, step)
+		if err != nil || got != "287082" {
+			t.Errorf("secret %q: kode %s, err %v", secret, got, err)
+		}
+	}
+
+	if _, err := TOTPCode("bukan-base32!", step); err == nil {
+		t.Error("secret tidak valid seharusnya error")
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := TOTPStep(now)
+
+	code := func(step int64) string {
+		c, err := TOTPCode(rfc6238Secret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{"langkah sekarang", code(current), current, true},
+		{"satu langkah sebelumnya", code(current - 1), current - 1, true},
+		{"satu langkah sesudahnya", code(current + 1), current + 1, true},
+		{"dua langkah sebelumnya", code(current - 2), 0, false},
+		{"dua langkah sesudahnya", code(current + 2), 0, false},
+		{"spasi di pinggir", " " + code(current) + " ", current, true},
+		{"terlalu pendek", code(current)[:5], 0, false},
+		{"kosong", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(rfc6238Secret, tt.code, now, 1)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("ValidateTOTP = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+
+	if _, ok := ValidateTOTP(rfc6238Secret, code(current-1), now, 0); ok {
+		t.Error("skew 0 seharusnya hanya menerima langkah sekarang")
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	a, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := GenerateTOTPSecret()
+	if a == b {
+		t.Error("secret seharusnya acak")
+	}
+
+	key, err := totpEncoding.DecodeString(a)
+	if err != nil || len(key) != 20 {
+		t.Errorf("secret %q: %d byte, err %v; want 20 byte", a, len(key), err)
+	}
+	if _, err := TOTPCode(a, 1); err != nil {
+		t.Errorf("secret baru tidak bisa dipakai: %v", err)
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("Astro", "user@x.com", rfc6238Secret)
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/Astro:user@x.com" {
+		t.Errorf("uri %s", uri)
+	}
+	q := u.Query()
+	if q.Get("secret") != rfc6238Secret || q.Get("issuer") != "Astro" || q.Get("digits") != "6" || q.Get("period") != "30" {
+		t.Errorf("query %v", q)
+	}
+}
@@ -3,6 +3,19 @@ import React, { useState, useEffect, useMemo } from "react";
 import { motion, AnimatePresence } from "framer-motion";
 import RoomCard from "./RoomCard";
 import RoomFormModal from "./RoomFormModal";
+import adminFetch from "../../../../utils/adminFetch";
+
+const NO_IMAGE = "https://placehold.co/600x400/CCCCCC/666666?text=No+Image";
+const PROCESSING_IMAGE = "https://placehold.co/600x400/CCCCCC/666666?text=Memproses...";
+
+// coverImage memilih foto cover; foto yang masih diproses belum punya url
+function coverImage(images) {
+  if (!images || images.length === 0) return NO_IMAGE;
+  const cover = images.find(img => img.primary) || images[0];
+  if (cover.status === "PROCESSING") return PROCESSING_IMAGE;
+  if (cover.status === "FAILED" || !cover.url) return NO_IMAGE;
+  return `http://localhost:8080${cover.variants?.medium || cover.url}`;
+}
 
 export default function AdminRooms() {
   const [rooms, setRooms] = useState([]);
@@ -20,7 +33,7 @@ export default function AdminRooms() {
 
   const fetchRooms = async () => {
   try {
-    const res = await fetch("http://localhost:8080/admin/room");
+    const res = await adminFetch("http://localhost:8080/admin/room?limit=100");
     
     if (!res.ok) {
       const errText = await res.text();
@@ -35,6 +48,8 @@ export default function AdminRooms() {
     let roomList = [];
     if (Array.isArray(data)) {
       roomList = data;
+    } else if (data && Array.isArray(data.data)) {
+      roomList = data.data;
     } else if (data && Array.isArray(data.rooms)) {
       roomList = data.rooms;
     } else if (data === null || data === undefined) {
@@ -56,9 +71,9 @@ export default function AdminRooms() {
       bed: r.bed_type || r.Bed_type || "Queen Bed",
       description: r.description || r.Description || "",
       facilities: r.facilities || r.Facilities || [],
-      image: r.images && r.images.length > 0 
-        ? `http://localhost:8080${r.images[0]}` 
-        : "https://placehold.co/600x400/CCCCCC/666666?text=No+Image",
+      // images berisi objek { id, url, alt, caption, primary, status, variants };
+      // cover = primary, pakai varian medium kalau sudah diproses
+      image: coverImage(r.images),
       updatedAt: r.updated_at 
         ? new Date(Number(r.updated_at)).toLocaleDateString("id-ID")
         : "N/A",
@@ -93,7 +108,7 @@ export default function AdminRooms() {
       : "http://localhost:8080/admin/create-room";
 
     try {
-      const res = await fetch(url, {
+      const res = await adminFetch(url, {
         method: "POST",
         // [KRUSIAL] Body adalah objek FormData. JANGAN SET Content-Type!
         body: formData, 
@@ -122,7 +137,7 @@ export default function AdminRooms() {
   const handleDelete = async (id) => {
     if (!window.confirm("Yakin hapus kamar ini?")) return;
     try {
-      const res = await fetch(`http://localhost:8080/admin/delete-room/${id}`, {
+      const res = await adminFetch(`http://localhost:8080/admin/delete-room/${id}`, {
         method: "POST", // backend pakai POST
       });
       if (res.ok) {
@@ -4,6 +4,7 @@ import { motion, AnimatePresence } from "framer-motion";
 import UserRow from "./UserRow";
 import UserFormModal from "./UserFormModal";
 import { Users, UserCheck, UserX, Shield, Search } from "lucide-react";
+import adminFetch from "../../../../utils/adminFetch";
 
 export default function AdminUsers() {
   const [users, setUsers] = useState([]);
@@ -19,13 +20,13 @@ export default function AdminUsers() {
 
   const fetchUsers = async () => {
     try {
-      const res = await fetch("http://localhost:8080/admin/user");
+      const res = await adminFetch("http://localhost:8080/admin/user?limit=100");
       if (!res.ok) throw new Error("Gagal koneksi"); // Pilihan: madd (lebih detail)
 
       const data = await res.json();
       console.log("Data dari server:", data); // Pilihan: main (message lebih umum)
 
-      const userList = Array.isArray(data) ? data : data.users || [];
+      const userList = Array.isArray(data) ? data : data.data || data.users || [];
 
       // NORMALISASI DATA (Gabungan lengkap)
       const normalized = userList.map(u => ({
@@ -74,7 +75,7 @@ export default function AdminUsers() {
     const body = isEdit ? userData : { ...userData };
 
     try {
-      const res = await fetch(url, {
+      const res = await adminFetch(url, {
         method: "POST",
         headers: { "Content-Type": "application/json" },
         body: JSON.stringify(body),
@@ -96,7 +97,7 @@ export default function AdminUsers() {
   const handleDelete = async (id) => {
     if (!window.confirm("Yakin hapus user ini?")) return; // Pilihan: madd (konfirmasi lebih jelas)
     try {
-      const res = await fetch(`http://localhost:8080/admin/delete-user/${id}`, {
+      const res = await adminFetch(`http://localhost:8080/admin/delete-user/${id}`, {
         method: "DELETE",
       });
       if (res.ok) {
@@ -47,9 +47,8 @@ const LoginPage = () => {
 
       if (response.ok) {
         // Login Sukses (Status 200)
-        // Note: Dalam aplikasi nyata, 'token' harus menjadi bagian dari respons
-        const token = data.user.token || "default-secure-token"; // Ganti dengan logika token asli dari Go
-        localStorage.setItem("adminToken", token); 
+        localStorage.setItem("adminToken", data.access_token);
+        localStorage.setItem("adminRefreshToken", data.refresh_token);
         
         showCustomMessage("success", "Login berhasil! Mengalihkan ke Dashboard Admin.");
 
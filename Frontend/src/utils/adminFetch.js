@@ -0,0 +1,56 @@
+// src/utils/adminFetch.js
+// fetch untuk endpoint /admin: mengirim access token dari login dan
+// memperbarui token lewat /login/refresh kalau server membalas 401.
+
+const API_BASE_URL = "http://localhost:8080";
+
+let refreshing = null;
+
+// refreshToken dipakai bersama supaya beberapa request yang kena 401
+// berbarengan hanya memicu satu refresh
+function refreshToken() {
+  if (!refreshing) {
+    refreshing = (async () => {
+      const refresh = localStorage.getItem("adminRefreshToken");
+      if (!refresh) return false;
+
+      const res = await fetch(`${API_BASE_URL}/login/refresh`, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ refresh_token: refresh }),
+      });
+      if (!res.ok) return false;
+
+      const data = await res.json();
+      localStorage.setItem("adminToken", data.access_token);
+      localStorage.setItem("adminRefreshToken", data.refresh_token);
+      return true;
+    })().finally(() => {
+      refreshing = null;
+    });
+  }
+  return refreshing;
+}
+
+function withAuth(options = {}) {
+  const headers = new Headers(options.headers || {});
+  const token = localStorage.getItem("adminToken");
+  if (token) headers.set("Authorization", `Bearer ${token}`);
+  return { ...options, headers };
+}
+
+export default async function adminFetch(url, options = {}) {
+  let res = await fetch(url, withAuth(options));
+  if (res.status !== 401) return res;
+
+  if (await refreshToken().catch(() => false)) {
+    res = await fetch(url, withAuth(options));
+    if (res.status !== 401) return res;
+  }
+
+  // sesi sudah tidak berlaku: kembali ke halaman login
+  localStorage.removeItem("adminToken");
+  localStorage.removeItem("adminRefreshToken");
+  window.location.href = "/admin/login";
+  return res;
+}